@@ -0,0 +1,128 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RemovalPolicy controls what happens to tags the policy no longer asks for.
+// +kubebuilder:validation:Enum=Retain;Delete
+type RemovalPolicy string
+
+const (
+	// RemovalPolicyRetain leaves tags on the resources when they are dropped
+	// from the policy or the policy is deleted.
+	RemovalPolicyRetain RemovalPolicy = "Retain"
+	// RemovalPolicyDelete removes tags the policy applied earlier once they
+	// are dropped from the policy, and all of them when it is deleted.
+	RemovalPolicyDelete RemovalPolicy = "Delete"
+)
+
+// ConditionSynced reports whether the last sync applied the policy.
+const ConditionSynced = "Synced"
+
+// ClusterMetadataPolicySpec is the desired tag set of the cluster's cloud
+// resources.
+type ClusterMetadataPolicySpec struct {
+	// Tags are applied to every selected resource. They take precedence over
+	// the tags read with FromCluster.
+	// +optional
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// FromCluster adds the user tags recorded in the Infrastructure status
+	// (resourceTags on AWS and Azure, resourceLabels on GCP).
+	// +optional
+	FromCluster bool `json:"fromCluster,omitempty"`
+
+	// IncludeTypes limits the policy to these resource types, e.g.
+	// AWSEC2Instance. All types are selected when empty.
+	// +optional
+	IncludeTypes []string `json:"includeTypes,omitempty"`
+
+	// ExcludeTypes are never tagged, even when listed in IncludeTypes.
+	// +optional
+	ExcludeTypes []string `json:"excludeTypes,omitempty"`
+
+	// NamespaceTagKeys are read from the labels and annotations of each
+	// Namespace and added to the disks of its PersistentVolumeClaims and the
+	// load balancers of its Services, on top of Tags. They are never removed.
+	// +optional
+	NamespaceTagKeys []string `json:"namespaceTagKeys,omitempty"`
+
+	// RemovalPolicy is Retain or Delete.
+	// +kubebuilder:default=Retain
+	// +optional
+	RemovalPolicy RemovalPolicy `json:"removalPolicy,omitempty"`
+
+	// Interval between full syncs. Changes to the policy or to the
+	// Infrastructure object trigger a sync right away.
+	// +kubebuilder:default="30m"
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// SyncSummary counts the resources handled by a sync.
+type SyncSummary struct {
+	// Resources is the number of selected resources.
+	Resources int `json:"resources"`
+	// InSync resources already carried the desired tags.
+	InSync int `json:"inSync"`
+	// Updated resources had tags added or changed.
+	Updated int `json:"updated"`
+	// Pruned resources had tags removed under RemovalPolicy Delete.
+	Pruned int `json:"pruned"`
+	// Failed resources could not be updated.
+	Failed int `json:"failed"`
+}
+
+// ClusterMetadataPolicyStatus is the observed state of the policy.
+type ClusterMetadataPolicyStatus struct {
+	// ObservedGeneration is the generation the last sync applied.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions hold the Synced condition.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// LastSyncTime is when the last sync finished.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// LastSyncSummary counts the resources handled by the last sync.
+	// +optional
+	LastSyncSummary *SyncSummary `json:"lastSyncSummary,omitempty"`
+
+	// ManagedKeys are the tag keys the policy applied, used to find the
+	// keys to remove under RemovalPolicy Delete.
+	// +optional
+	ManagedKeys []string `json:"managedKeys,omitempty"`
+}
+
+// ClusterMetadataPolicy declares the tags of the cluster's cloud resources
+// and is reconciled by the controller subcommand.
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=cmp
+// +kubebuilder:printcolumn:name="Synced",type=string,JSONPath=`.status.conditions[?(@.type=="Synced")].status`
+// +kubebuilder:printcolumn:name="Last Sync",type=date,JSONPath=`.status.lastSyncTime`
+type ClusterMetadataPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterMetadataPolicySpec   `json:"spec,omitempty"`
+	Status ClusterMetadataPolicyStatus `json:"status,omitempty"`
+}
+
+// ClusterMetadataPolicyList contains a list of ClusterMetadataPolicy.
+// +kubebuilder:object:root=true
+type ClusterMetadataPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterMetadataPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterMetadataPolicy{}, &ClusterMetadataPolicyList{})
+}
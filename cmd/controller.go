@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/controller"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/manager"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	"github.com/go-logr/stdr"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"log"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+)
+
+var (
+	metricsAddr    string
+	probeAddr      string
+	leaderElection bool
+)
+
+var controllerCmd = &cobra.Command{
+	Use:   "controller",
+	Short: "Run as a controller reconciling ClusterMetadataPolicy resources",
+	Long: `Run continuously and keep the cluster's cloud resources in sync with every
+ClusterMetadataPolicy. A policy is synced on its interval and whenever the
+policy or the Infrastructure object changes; the result is reported in the
+policy status. New Nodes, PersistentVolumes and LoadBalancer Services are
+tagged as soon as they appear (AWS, Azure and GCP).
+
+Install the CRD and RBAC from config/ before starting the controller.`,
+	Example: `  # Run against the current kubeconfig
+  openshift-metadata-manager controller
+
+  # Run with leader election, e.g. as a Deployment with several replicas
+  openshift-metadata-manager controller --leader-elect`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if offlineMode() {
+			log.Fatal("controller mode needs the cluster API and cannot be used offline")
+		}
+		ctrl.SetLogger(stdr.New(log.Default()))
+
+		cfg, err := cluster.RESTConfig(kubeconfigPath, kubeContext)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+			Scheme: cluster.Scheme,
+			// The ledgers are read from the API server: their writes
+			// must see each other's, and the RBAC grants no list/watch
+			// on ConfigMaps.
+			Client: client.Options{Cache: &client.CacheOptions{
+				DisableFor: []client.Object{&corev1.ConfigMap{}},
+			}},
+			Metrics:                metricsserver.Options{BindAddress: metricsAddr},
+			HealthProbeBindAddress: probeAddr,
+			LeaderElection:         leaderElection,
+			LeaderElectionID:       "openshift-metadata-manager.metadatamanager.openshift.io",
+		})
+		if err != nil {
+			log.Fatalf("Error creating controller manager: %v", err)
+		}
+
+		// The providers read a handful of cluster objects when they run;
+		// they get an uncached client so the manager does not start
+		// cluster-wide informers for them.
+		providerClient, err := client.New(cfg, client.Options{Scheme: cluster.Scheme})
+		if err != nil {
+			log.Fatalf("Error creating Kubernetes client: %v", err)
+		}
+		tagger := providerTagger{k8sClient: providerClient}
+
+		reconciler := &controller.PolicyReconciler{
+			Client:   mgr.GetClient(),
+			Tagger:   tagger,
+			Resolver: tagger,
+		}
+		if err := reconciler.SetupWithManager(mgr); err != nil {
+			log.Fatalf("Error setting up ClusterMetadataPolicy controller: %v", err)
+		}
+		resourceReconciler := &controller.ResourceReconciler{
+			Client:   mgr.GetClient(),
+			Tagger:   tagger,
+			Resolver: tagger,
+		}
+		if err := resourceReconciler.SetupWithManager(mgr); err != nil {
+			log.Fatalf("Error setting up resource controllers: %v", err)
+		}
+		if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
+			log.Fatalf("Error adding health check: %v", err)
+		}
+		if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
+			log.Fatalf("Error adding ready check: %v", err)
+		}
+
+		fmt.Println("🚀 Starting metadata controller...")
+		if err := mgr.Start(cmd.Context()); err != nil {
+			log.Fatalf("Controller stopped: %v", err)
+		}
+	},
+}
+
+// providerTagger implements controller.Tagger and controller.Resolver with
+// a manager per call, since the platform is passed in.
+type providerTagger struct {
+	k8sClient client.Client
+}
+
+func (t providerTagger) manager(ctx context.Context, cloudPlatform infraType.CloudPlatform) (*manager.Manager, error) {
+	return manager.New(ctx, manager.Options{Client: t.k8sClient, Platform: cloudPlatform})
+}
+
+func (t providerTagger) ListResources(ctx context.Context,
+	cloudPlatform infraType.CloudPlatform) ([]infraType.CloudResource, error) {
+
+	m, err := t.manager(ctx, cloudPlatform)
+	if err != nil {
+		return nil, err
+	}
+	return m.ListResources(ctx)
+}
+
+func (t providerTagger) ApplyTags(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	resources []infraType.CloudResource, tags map[string]string) error {
+
+	m, err := t.manager(ctx, cloudPlatform)
+	if err != nil {
+		return err
+	}
+	return m.ApplyTags(ctx, resources, tags)
+}
+
+func (t providerTagger) RemoveTags(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	resources []infraType.CloudResource, keys []string) error {
+
+	m, err := t.manager(ctx, cloudPlatform)
+	if err != nil {
+		return err
+	}
+	return m.RemoveTags(ctx, resources, keys)
+}
+
+func (t providerTagger) DescribeResource(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	resource infraType.CloudResource) (infraType.CloudResource, error) {
+
+	m, err := t.manager(ctx, cloudPlatform)
+	if err != nil {
+		return resource, err
+	}
+	return m.DescribeResource(ctx, resource)
+}
+
+func (t providerTagger) FindLoadBalancer(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	hostname, ip string) (*infraType.CloudResource, error) {
+
+	m, err := t.manager(ctx, cloudPlatform)
+	if err != nil {
+		return nil, err
+	}
+	return m.FindLoadBalancer(ctx, hostname, ip)
+}
+
+func init() {
+	controllerCmd.Flags().StringVar(&metricsAddr, "metrics-bind-address", ":8080",
+		"Address the metrics endpoint binds to, 0 disables it")
+	controllerCmd.Flags().StringVar(&probeAddr, "health-probe-bind-address", ":8081",
+		"Address the health probe endpoint binds to")
+	controllerCmd.Flags().BoolVar(&leaderElection, "leader-elect", false,
+		"Enable leader election so only one replica reconciles at a time")
+
+	RootCmd.AddCommand(controllerCmd)
+}
@@ -0,0 +1,231 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/api/v1alpha1"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/controller"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+var (
+	driftTags        []string
+	driftFromCluster bool
+	// driftPolicyPath is a ClusterMetadataPolicy manifest to take the
+	// desired tags, resource types and managed keys from.
+	driftPolicyPath string
+	// driftManagedKeys are keys the tool manages: resources carrying them
+	// while they are not desired have drifted.
+	driftManagedKeys []string
+	driftOutput      string
+)
+
+// resourceDrift is how a resource's tags differ from the desired ones.
+type resourceDrift struct {
+	Type infraType.CloudResourceType `json:"type"`
+	ID   string                      `json:"id"`
+	Name string                      `json:"name,omitempty"`
+	// Missing are desired tags the resource does not carry.
+	Missing map[string]string `json:"missing,omitempty"`
+	// Wrong are desired tags the resource carries with another value.
+	Wrong map[string]tagValues `json:"wrong,omitempty"`
+	// Unexpected are managed tags that are no longer desired.
+	Unexpected map[string]string `json:"unexpected,omitempty"`
+}
+
+type tagValues struct {
+	Current string `json:"current"`
+	Desired string `json:"desired"`
+}
+
+// driftReport is the drift command's JSON output.
+type driftReport struct {
+	Platform  infraType.CloudPlatform `json:"platform"`
+	Desired   map[string]string       `json:"desired"`
+	Resources int                     `json:"resources"`
+	Drifted   int                     `json:"drifted"`
+	Drift     []resourceDrift         `json:"drift"`
+}
+
+var driftCmd = &cobra.Command{
+	Use:   "drift",
+	Short: "Report resources whose tags differ from the desired tags",
+	Long: `Compare the live tags of the cluster's resources to the desired tags and report,
+per resource, the missing keys, the keys with a wrong value and the managed keys
+that are no longer desired. Nothing is written. The command exits with 9 when
+any resource has drifted.`,
+	Example: `  # Check the tags recorded in the Infrastructure status
+  openshift-metadata-manager drift --from-cluster
+
+  # Check a policy, as JSON for alerting
+  openshift-metadata-manager drift --policy config/samples/clustermetadatapolicy.yaml -o json
+
+  # Also report leftovers of keys that were dropped
+  openshift-metadata-manager drift --tags Owner=DevOps --managed-keys Owner,Team`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if driftOutput != "text" && driftOutput != "json" {
+			log.Fatalf("Unsupported --output %q, expected text or json", driftOutput)
+		}
+		if driftPolicyPath != "" && (len(driftTags) > 0 || driftFromCluster) {
+			log.Fatal("--policy takes the tags from the policy and cannot be combined with --tags or --from-cluster")
+		}
+		// The progress messages go to stderr so that stdout is only the
+		// report.
+		if driftOutput == "json" {
+			messages = os.Stderr
+		}
+
+		fmt.Fprintln(messages, "🔍 Checking for drift...")
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		k8sClient := getK8sClient()
+		cloudPlatform, err := getCloudPlatform(ctx, k8sClient)
+		if err != nil {
+			log.Fatalf("Platform detection error: %v", err)
+		}
+		if platform != "" {
+			if cloudPlatform, err = cluster.ParsePlatform(platform); err != nil {
+				fatalError(err, "Invalid --platform")
+			}
+		}
+
+		resources, err := listResources(ctx, k8sClient, cloudPlatform)
+		if err != nil {
+			fatalError(err, "Failed to list %s resources", cloudPlatform)
+		}
+
+		var desired map[string]string
+		managedKeys := driftManagedKeys
+		if driftPolicyPath != "" {
+			policy, err := readPolicy(driftPolicyPath)
+			if err != nil {
+				log.Fatal(err)
+			}
+			if desired, err = controller.DesiredTags(ctx, k8sClient, policy, cloudPlatform); err != nil {
+				log.Fatal(err)
+			}
+			resources = controller.SelectResources(resources, policy.Spec)
+			managedKeys = append(managedKeys, policy.Status.ManagedKeys...)
+		} else if desired, err = desiredTags(ctx, k8sClient, cloudPlatform, driftTags, driftFromCluster, false); err != nil {
+			log.Fatal(err)
+		}
+
+		report := driftReport{Platform: cloudPlatform, Desired: desired, Drift: []resourceDrift{}}
+		for _, res := range resources {
+			if res.NotTaggable {
+				continue
+			}
+			report.Resources++
+			if drift, ok := tagDrift(res, desired, managedKeys); ok {
+				report.Drift = append(report.Drift, drift)
+			}
+		}
+		report.Drifted = len(report.Drift)
+
+		if driftOutput == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				log.Fatal(err)
+			}
+		} else {
+			printDrift(report)
+		}
+		if report.Drifted > 0 {
+			os.Exit(exitDrift)
+		}
+	},
+}
+
+// tagDrift returns how the resource's tags differ from desired, and whether
+// they do.
+func tagDrift(res infraType.CloudResource, desired map[string]string, managedKeys []string) (resourceDrift, bool) {
+	drift := resourceDrift{Type: res.Type, ID: res.ID, Name: res.Name}
+	for k, v := range driftedTags(res.Tags, desired) {
+		if current, ok := res.Tags[k]; ok {
+			if drift.Wrong == nil {
+				drift.Wrong = make(map[string]tagValues)
+			}
+			drift.Wrong[k] = tagValues{Current: current, Desired: v}
+		} else {
+			if drift.Missing == nil {
+				drift.Missing = make(map[string]string)
+			}
+			drift.Missing[k] = v
+		}
+	}
+	for _, k := range managedKeys {
+		current, ok := res.Tags[k]
+		if _, wanted := desired[k]; !ok || wanted {
+			continue
+		}
+		if drift.Unexpected == nil {
+			drift.Unexpected = make(map[string]string)
+		}
+		drift.Unexpected[k] = current
+	}
+	return drift, len(drift.Missing)+len(drift.Wrong)+len(drift.Unexpected) > 0
+}
+
+func printDrift(report driftReport) {
+	for _, drift := range report.Drift {
+		current := make(map[string]string)
+		wanted := make(map[string]string)
+		for k, v := range drift.Missing {
+			wanted[k] = v
+		}
+		for k, v := range drift.Wrong {
+			current[k] = v.Current
+			wanted[k] = v.Desired
+		}
+		for k, v := range drift.Unexpected {
+			current[k] = v
+		}
+
+		label := drift.ID
+		if drift.Name != "" {
+			label = drift.Name
+		}
+		fmt.Printf("  ⚠ %s (%s) has drifted:\n", label, drift.Type)
+		printTagDiff(current, wanted)
+	}
+	fmt.Printf("📊 %d of %d resources have drifted\n", report.Drifted, report.Resources)
+}
+
+// readPolicy reads a ClusterMetadataPolicy manifest.
+func readPolicy(path string) (*v1alpha1.ClusterMetadataPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read policy: %w", err)
+	}
+	policy := &v1alpha1.ClusterMetadataPolicy{}
+	if err := yaml.UnmarshalStrict(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", path, err)
+	}
+	if policy.Kind != "ClusterMetadataPolicy" {
+		return nil, fmt.Errorf("%s is a %q, not a ClusterMetadataPolicy", path, policy.Kind)
+	}
+	return policy, nil
+}
+
+func init() {
+	driftCmd.Flags().StringSliceVarP(&driftTags, "tags", "t", []string{},
+		"Desired tags in KEY=VALUE format (comma-separated)")
+	driftCmd.Flags().BoolVar(&driftFromCluster, "from-cluster", false,
+		"Use the user tags recorded in the Infrastructure status (resourceTags/resourceLabels) instead of --tags")
+	driftCmd.Flags().StringVar(&driftPolicyPath, "policy", "",
+		"Take the desired tags, resource types and managed keys from a ClusterMetadataPolicy manifest")
+	driftCmd.Flags().StringSliceVar(&driftManagedKeys, "managed-keys", nil,
+		"Keys the tool manages; resources carrying them while they are not desired have drifted")
+	driftCmd.Flags().StringVarP(&driftOutput, "output", "o", "text",
+		"Output format, text or json")
+
+	RootCmd.AddCommand(driftCmd)
+}
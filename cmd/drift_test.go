@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func TestTagDrift(t *testing.T) {
+	desired := map[string]string{"Owner": "DevOps", "Team": "infra"}
+
+	tests := []struct {
+		name        string
+		tags        map[string]string
+		managedKeys []string
+		want        resourceDrift
+		wantDrifted bool
+	}{
+		{
+			name: "no drift",
+			tags: map[string]string{"Owner": "DevOps", "Team": "infra", "Env": "prod"},
+		},
+		{
+			name:        "missing tags",
+			tags:        map[string]string{"Owner": "DevOps"},
+			want:        resourceDrift{Missing: map[string]string{"Team": "infra"}},
+			wantDrifted: true,
+		},
+		{
+			name: "wrong value",
+			tags: map[string]string{"Owner": "Old", "Team": "infra"},
+			want: resourceDrift{
+				Wrong: map[string]tagValues{"Owner": {Current: "Old", Desired: "DevOps"}},
+			},
+			wantDrifted: true,
+		},
+		{
+			name:        "unexpected managed key",
+			tags:        map[string]string{"Owner": "DevOps", "Team": "infra", "CostCenter": "42"},
+			managedKeys: []string{"CostCenter", "Owner", "Project"},
+			want:        resourceDrift{Unexpected: map[string]string{"CostCenter": "42"}},
+			wantDrifted: true,
+		},
+		{
+			name:        "unmanaged extra key",
+			tags:        map[string]string{"Owner": "DevOps", "Team": "infra", "CostCenter": "42"},
+			managedKeys: []string{"Project"},
+		},
+		{
+			name:        "all kinds",
+			managedKeys: []string{"CostCenter"},
+			tags:        map[string]string{"Owner": "Old", "CostCenter": "42"},
+			want: resourceDrift{
+				Missing:    map[string]string{"Team": "infra"},
+				Wrong:      map[string]tagValues{"Owner": {Current: "Old", Desired: "DevOps"}},
+				Unexpected: map[string]string{"CostCenter": "42"},
+			},
+			wantDrifted: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEC2Instance, ID: "i-1", Tags: tt.tags}
+			tt.want.Type, tt.want.ID = res.Type, res.ID
+
+			got, drifted := tagDrift(res, desired, tt.managedKeys)
+			if drifted != tt.wantDrifted {
+				t.Errorf("drifted = %v, want %v", drifted, tt.wantDrifted)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("tagDrift() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/manager"
+)
+
+// Exit codes of failed commands. Errors of no known category exit with 1.
+const (
+	exitFailure             = 1
+	exitUnsupportedPlatform = 2
+	exitCredentials         = 3
+	exitPermissionDenied    = 4
+	exitThrottled           = 5
+	exitNotFound            = 6
+	exitTagLimitExceeded    = 7
+	exitTimeout             = 8
+	// exitDrift is returned by drift when resources have drifted.
+	exitDrift = 9
+	// exitInterrupted is the shell's code for a command ended by SIGINT.
+	exitInterrupted = 130
+)
+
+type errorCategory struct {
+	exitCode int
+	hint     string
+}
+
+var errorCategories = map[error]errorCategory{
+	manager.ErrUnsupportedPlatform: {exitUnsupportedPlatform,
+		"Use --platform with one of aws, azure, gcp, ibm, powervs, openstack, vsphere or nutanix."},
+	manager.ErrCredentials: {exitCredentials,
+		"Check the cloud credentials: the AWS profile or access keys, az login or AZURE_* variables, " +
+			"GOOGLE_APPLICATION_CREDENTIALS, IC_API_KEY, clouds.yaml or OS_* variables, the vCenter " +
+			"or NUTANIX_* credentials. They may also have expired."},
+	manager.ErrPermissionDenied: {exitPermissionDenied,
+		"The credentials can not read or tag some resources. Grant the list, describe and tagging " +
+			"permissions of the cluster's resources to the identity in use."},
+	manager.ErrThrottled: {exitThrottled,
+		"The cloud API rate limit was hit. Wait a few minutes and run the command again; " +
+			"resources already tagged are skipped."},
+	manager.ErrNotFound: {exitNotFound,
+		"A resource was deleted while the command ran, or the cluster identity points to another " +
+			"cluster. Run list to check the resources and retry."},
+	manager.ErrTagLimitExceeded: {exitTagLimitExceeded,
+		"Remove unused tags from the resources or sync fewer tags; validate checks the tags " +
+			"against the platform's limits."},
+}
+
+// fatalError logs err after msg, then a hint on how to fix it, and exits
+// with the code of err's category.
+func fatalError(err error, format string, args ...interface{}) {
+	err = manager.Classify(err)
+	log.Printf("%s: %v", fmt.Sprintf(format, args...), err)
+	os.Exit(exitWithHint(err))
+}
+
+// exitWithHint logs the hint for err's category and returns its exit code.
+func exitWithHint(err error) int {
+	if category, ok := errorCategories[manager.Category(err)]; ok {
+		log.Printf("💡 %s", category.hint)
+		return category.exitCode
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return exitInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		log.Print("💡 The command ran into --timeout or a write into --operation-timeout. Raise them, or run " +
+			"the command again to tag the resources left.")
+		return exitTimeout
+	default:
+		return exitFailure
+	}
+}
+
+// failures are the errors of the resource updates a command could not make.
+// The command continues with the other resources and exits with the code
+// of the first failure at the end, see exitOnFailures.
+var failures []error
+
+// logFailure logs err after msg like the per-resource error messages and
+// records it for exitOnFailures.
+func logFailure(err error, msg string) {
+	err = manager.Classify(err)
+	log.Printf("  ❌ %s: %v", msg, err)
+	failures = append(failures, err)
+}
+
+// exitOnFailures exits if logFailure recorded any error.
+func exitOnFailures() {
+	if len(failures) == 0 {
+		return
+	}
+	log.Printf("⚠ %d operations failed", len(failures))
+	os.Exit(exitWithHint(errors.Join(failures...)))
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/manager"
+)
+
+func TestExitWithHint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "unsupported platform", err: fmt.Errorf("%w: baremetal", manager.ErrUnsupportedPlatform), want: exitUnsupportedPlatform},
+		{name: "credentials", err: manager.ErrCredentials, want: exitCredentials},
+		{name: "permissions", err: manager.ErrPermissionDenied, want: exitPermissionDenied},
+		{name: "throttled", err: manager.ErrThrottled, want: exitThrottled},
+		{name: "not found", err: manager.ErrNotFound, want: exitNotFound},
+		{name: "tag limit", err: manager.ErrTagLimitExceeded, want: exitTagLimitExceeded},
+		{name: "timeout", err: fmt.Errorf("listing: %w", context.DeadlineExceeded), want: exitTimeout},
+		{name: "interrupted", err: context.Canceled, want: exitInterrupted},
+		{name: "category wins over cancellation", err: errors.Join(context.Canceled, manager.ErrThrottled), want: exitThrottled},
+		{name: "unknown", err: errors.New("boom"), want: exitFailure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitWithHint(tt.err); got != tt.want {
+				t.Errorf("exitWithHint() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/inventory"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// inventoryFormat is the format export writes, json or yaml.
+	inventoryFormat string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Export the cluster's resources and their tags to a file",
+	Long: `Write all cloud resources of the cluster with their tags to a versioned JSON or
+YAML inventory. The inventory can be edited and applied with import.`,
+	Example: `  # Export to YAML
+  openshift-metadata-manager export tags.yaml
+
+  # Export to JSON
+  openshift-metadata-manager export tags.json --format json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("📤 Exporting cluster resources...")
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		format, err := inventory.ParseFormat(inventoryFormat, args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		k8sClient := getK8sClient()
+		cloudPlatform, err := getCloudPlatform(ctx, k8sClient)
+		if err != nil {
+			log.Fatalf("Platform detection error: %v", err)
+		}
+		if platform != "" {
+			if cloudPlatform, err = cluster.ParsePlatform(platform); err != nil {
+				fatalError(err, "Invalid --platform")
+			}
+		}
+
+		resources, err := listResources(ctx, k8sClient, cloudPlatform)
+		if err != nil {
+			fatalError(err, "Failed to list %s resources", cloudPlatform)
+		}
+		if err := inventory.New(cloudPlatform, resources).Write(args[0], format); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("✅ Exported %d resources to %s\n", len(resources), args[0])
+	},
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Apply the tags of an inventory file to the cluster's resources",
+	Long: `Apply the tags of each resource in a JSON or YAML inventory, as written by export,
+to the cluster resource with the same ID. Tags are added or overwritten; tags
+missing from the inventory are kept. The original tags are saved to a snapshot
+for rollback.`,
+	Example: `  # Preview the changes
+  openshift-metadata-manager import tags.yaml --dry-run
+
+  # Apply the tags
+  openshift-metadata-manager import tags.yaml`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("📥 Importing tags...")
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		inv, err := inventory.Read(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		k8sClient := getK8sClient()
+		cloudPlatform, err := getCloudPlatform(ctx, k8sClient)
+		if err != nil {
+			log.Fatalf("Platform detection error: %v", err)
+		}
+		if platform != "" {
+			if cloudPlatform, err = cluster.ParsePlatform(platform); err != nil {
+				fatalError(err, "Invalid --platform")
+			}
+		}
+		if inv.Platform != "" && inv.Platform != cloudPlatform {
+			log.Fatalf("The inventory is of %s resources, not %s", inv.Platform, cloudPlatform)
+		}
+
+		m := newManager(ctx, k8sClient, cloudPlatform)
+		resources, err := m.ListResources(ctx)
+		if err != nil {
+			fatalError(err, "Failed to list %s resources", cloudPlatform)
+		}
+		byID := make(map[string][]infraType.CloudResource, len(resources))
+		for _, res := range resources {
+			byID[res.ID] = append(byID[res.ID], res)
+		}
+
+		// Resources with the same changes are tagged together.
+		groups := make(map[string][]infraType.CloudResource)
+		changesByGroup := make(map[string]map[string]string)
+		for _, r := range inv.Resources {
+			matches := byID[r.ID]
+			if r.Type != "" {
+				var ofType []infraType.CloudResource
+				for _, res := range matches {
+					if res.Type == r.Type {
+						ofType = append(ofType, res)
+					}
+				}
+				matches = ofType
+			}
+			if len(matches) == 0 {
+				logFailure(fmt.Errorf("%s (%s) is not a resource of the cluster", r.ID, r.Type), "Not importing")
+				continue
+			}
+
+			for _, res := range matches {
+				changes := driftedTags(res.Tags, r.Tags)
+				if len(changes) == 0 {
+					continue
+				}
+				if res.NotTaggable {
+					fmt.Printf("  ⚠ %s does not support tags, skipping\n", resourceLabel(res))
+					continue
+				}
+				if dryRun {
+					fmt.Printf("  🔄 [Dry Run] Would update %s:\n", resourceLabel(res))
+				} else {
+					fmt.Printf("  ✓ Updating %s:\n", resourceLabel(res))
+				}
+				printTagDiff(res.Tags, mergeTags(res.Tags, changes))
+
+				group := tagsLabel(changes)
+				groups[group] = append(groups[group], res)
+				changesByGroup[group] = changes
+			}
+		}
+		if dryRun {
+			exitOnFailures()
+			return
+		}
+
+		progress := &infraType.Progress{}
+		ctx = infraType.WithProgress(ctx, progress)
+		snap := newSyncSnapshot(cloudPlatform, "import-"+syncStarted.Format("20060102-150405")+".snapshot.json")
+		for _, group := range sortedKeys(groups) {
+			if ctx.Err() != nil {
+				break
+			}
+			snap.add(groups[group], changesByGroup[group])
+			if err := m.ApplyTags(ctx, groups[group], changesByGroup[group]); err != nil {
+				logFailure(err, "Failed to apply tags")
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			printProgress(ctx, resources, progress)
+			fmt.Println("⏯ Run the import again to tag the resources left")
+			os.Exit(exitWithHint(err))
+		}
+		exitOnFailures()
+		fmt.Println("✅ Import completed")
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&inventoryFormat, "format", "",
+		"Inventory format, json or yaml (defaults to the file extension, json for other extensions)")
+	importCmd.Flags().StringVar(&snapshotPath, "snapshot", "",
+		"Save the tags of the resources before the import to this file for rollback (defaults to import-<time>.snapshot.json)")
+
+	RootCmd.AddCommand(exportCmd)
+	RootCmd.AddCommand(importCmd)
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/journal"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// syncJournal records the resources of a sync in the journal: planned
+// before syncPlatform writes them, then applied or failed.
+type syncJournal struct {
+	*journal.Journal
+	platform infraType.CloudPlatform
+
+	mu      sync.Mutex
+	planned map[string]journal.Entry
+}
+
+// syncStarted names the files a sync writes by default, see syncFileName.
+var syncStarted = time.Now()
+
+// syncFileName returns the default name of a file the sync writes, e.g.
+// sync-20250101-120000.jsonl for the journal.
+func syncFileName(ext string) string {
+	return "sync-" + syncStarted.Format("20060102-150405") + ext
+}
+
+// openSyncJournal opens the --journal file, the --resume one, or a new
+// journal named after the start of the sync.
+func openSyncJournal(cloudPlatform infraType.CloudPlatform) *syncJournal {
+	path := journalPath
+	if path == "" {
+		path = resumePath
+	}
+	if path == "" {
+		path = syncFileName(".jsonl")
+	}
+	j, err := journal.Open(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("📝 Journal: %s\n", path)
+	return &syncJournal{Journal: j, platform: cloudPlatform, planned: make(map[string]journal.Entry)}
+}
+
+// plan records the resources as planned with the tags they will have.
+func (j *syncJournal) plan(resources []infraType.CloudResource, tags map[string]string) {
+	for _, res := range resources {
+		if res.NotTaggable {
+			continue
+		}
+		e := journal.Entry{
+			State:    journal.StatePlanned,
+			Platform: j.platform,
+			Type:     res.Type,
+			ID:       res.ID,
+			Name:     res.Name,
+			Before:   res.Tags,
+			After:    mergeTags(res.Tags, tags),
+		}
+		j.mu.Lock()
+		j.planned[res.Key()] = e
+		j.mu.Unlock()
+		j.record(e)
+	}
+}
+
+// done records the outcome of a write; it is the OnWrite of the sync's
+// Progress.
+func (j *syncJournal) done(res infraType.CloudResource, err error) {
+	j.mu.Lock()
+	e, ok := j.planned[res.Key()]
+	j.mu.Unlock()
+	if !ok {
+		// Writes must be planned first, or resume can not account for them.
+		log.Printf("  ⚠ %s was written without a journal entry", resourceLabel(res))
+		return
+	}
+
+	e.Time = time.Time{}
+	e.State = journal.StateApplied
+	if err != nil {
+		e.State = journal.StateFailed
+		e.Error = err.Error()
+	}
+	j.record(e)
+}
+
+func (j *syncJournal) record(e journal.Entry) {
+	if err := j.Record(e); err != nil {
+		log.Printf("  ⚠ %v", err)
+	}
+}
+
+// resumeSync continues the sync recorded in the --resume journal with the
+// resources it did not apply. A resource is only written if its tags are
+// still the ones the journal saw before; resources that already carry the
+// new tags are recorded as applied.
+func resumeSync(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform,
+	resources []infraType.CloudResource, j *syncJournal) {
+
+	entries, err := journal.Read(resumePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	latest, keys := journal.Latest(entries)
+
+	current := make(map[string]infraType.CloudResource, len(resources))
+	for _, res := range resources {
+		current[res.Key()] = res
+	}
+
+	// Resources with the same changes are synced together.
+	groups := make(map[string][]infraType.CloudResource)
+	changesByGroup := make(map[string]map[string]string)
+	var outstanding int
+	for _, key := range keys {
+		e := latest[key]
+		if e.State == journal.StateApplied {
+			continue
+		}
+		if e.Platform != cloudPlatform {
+			log.Fatalf("The journal is for %s resources, not %s", e.Platform, cloudPlatform)
+		}
+		outstanding++
+
+		res, ok := current[key]
+		switch {
+		case !ok:
+			logFailure(fmt.Errorf("%s (%s) no longer exists", e.ID, e.Type), "Not resuming")
+			continue
+		case maps.Equal(res.Tags, e.After):
+			fmt.Printf("  ✓ %s already carries the new tags\n", resourceLabel(res))
+			e.Time, e.State, e.Error = time.Time{}, journal.StateApplied, ""
+			j.record(e)
+			continue
+		case !maps.Equal(res.Tags, e.Before):
+			logFailure(fmt.Errorf("the tags of %s (%s) changed since the journal was written", e.ID, e.Type),
+				"Not resuming")
+			continue
+		}
+
+		changes := make(map[string]string)
+		for k, v := range e.After {
+			if before, ok := e.Before[k]; !ok || before != v {
+				changes[k] = v
+			}
+		}
+		group := tagsLabel(changes)
+		groups[group] = append(groups[group], res)
+		changesByGroup[group] = changes
+	}
+	fmt.Printf("⏯ Resuming %d of %d resources from %s\n", outstanding, len(keys), resumePath)
+
+	groupNames := make([]string, 0, len(groups))
+	for group := range groups {
+		groupNames = append(groupNames, group)
+	}
+	sort.Strings(groupNames)
+	for _, group := range groupNames {
+		syncPlatform(ctx, k8sClient, cloudPlatform, groups[group], changesByGroup[group])
+	}
+}
+
+// tagsLabel renders tags as sorted key=value pairs.
+func tagsLabel(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"errors"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/journal"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func TestTagsLabel(t *testing.T) {
+	tests := []struct {
+		tags map[string]string
+		want string
+	}{
+		{tags: nil, want: ""},
+		{tags: map[string]string{"Owner": "DevOps"}, want: "Owner=DevOps"},
+		{tags: map[string]string{"Team": "infra", "Owner": "DevOps", "Env": ""}, want: "Env=,Owner=DevOps,Team=infra"},
+	}
+	for _, tt := range tests {
+		if got := tagsLabel(tt.tags); got != tt.want {
+			t.Errorf("tagsLabel(%v) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestSyncJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync.jsonl")
+	jf, err := journal.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j := &syncJournal{Journal: jf, platform: infraType.CloudPlatformAWS, planned: make(map[string]journal.Entry)}
+
+	resources := []infraType.CloudResource{
+		{Type: infraType.CloudResourceTypeAWSEC2Instance, ID: "i-1", Tags: map[string]string{"Owner": "Old"}},
+		{Type: infraType.CloudResourceTypeAWSEC2Instance, ID: "i-2"},
+		{Type: infraType.CloudResourceTypeAWSIAMRole, ID: "role", NotTaggable: true},
+	}
+	j.plan(resources, map[string]string{"Owner": "DevOps"})
+	j.done(resources[0], nil)
+	j.done(resources[1], errors.New("throttled"))
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := journal.Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.ID+" "+string(e.State)+" "+e.Error)
+	}
+	want := []string{"i-1 planned ", "i-2 planned ", "i-1 applied ", "i-2 failed throttled"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("journal = %q, want %q", got, want)
+	}
+	if e := entries[0]; e.Before["Owner"] != "Old" || e.After["Owner"] != "DevOps" {
+		t.Errorf("planned entry = %+v, want the tags before and after", e)
+	}
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// syncLedger records the keys a sync adds in the ownership ledger, see
+// cluster.Ledger: planned before syncPlatform writes the resources, and
+// kept once a write succeeds.
+type syncLedger struct {
+	*cluster.Ledger
+	k8sClient client.Client
+
+	mu      sync.Mutex
+	planned map[string][]string
+	written map[string]bool
+	// changed reports whether the ledger needs to be saved.
+	changed bool
+}
+
+// readSyncLedger reads the ledger of the cluster.
+func readSyncLedger(ctx context.Context, k8sClient client.Client) (*syncLedger, error) {
+	l, err := cluster.ReadLedger(ctx, k8sClient, cluster.LedgerName)
+	if err != nil {
+		return nil, err
+	}
+	return &syncLedger{Ledger: l, k8sClient: k8sClient, planned: make(map[string][]string),
+		written: make(map[string]bool)}, nil
+}
+
+// plan notes the keys of tags the resources do not carry yet.
+func (l *syncLedger) plan(resources []infraType.CloudResource, tags map[string]string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, res := range resources {
+		var added []string
+		for k := range tags {
+			if _, ok := res.Tags[k]; !ok {
+				added = append(added, k)
+			}
+		}
+		l.planned[res.Key()] = added
+	}
+}
+
+// done records the planned keys of a written resource; it is called by the
+// OnWrite of the sync's Progress.
+func (l *syncLedger) done(res infraType.CloudResource, err error) {
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Add(res, l.planned[res.Key()])
+	l.written[res.Key()] = true
+	l.changed = true
+}
+
+// prune removes the keys the ledger owns that the tags no longer have from
+// the resources. It is supported on AWS, Azure and GCP.
+func (l *syncLedger) prune(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	resources []infraType.CloudResource, tags map[string]string) {
+
+	// Resources with the same stale keys are pruned together.
+	groups := make(map[string][]infraType.CloudResource)
+	staleByGroup := make(map[string][]string)
+	for _, res := range resources {
+		var stale, gone []string
+		l.mu.Lock()
+		for _, k := range l.Managed(res) {
+			if _, desired := tags[k]; desired {
+				continue
+			}
+			if _, ok := res.Tags[k]; ok {
+				stale = append(stale, k)
+			} else {
+				gone = append(gone, k)
+			}
+		}
+		// Keys removed by someone else are forgotten: if they come back,
+		// they are not the tool's. A dry run changes nothing.
+		if len(gone) > 0 && !dryRun {
+			l.Remove(res, gone)
+			l.changed = true
+		}
+		l.mu.Unlock()
+		if len(stale) == 0 {
+			continue
+		}
+
+		// The removals need the tags the sync just wrote: GCP rewrites the
+		// whole label set from them.
+		l.mu.Lock()
+		if l.written[res.Key()] {
+			res.Tags = mergeTags(res.Tags, tags)
+		}
+		l.mu.Unlock()
+
+		if dryRun {
+			fmt.Printf("  🔄 [Dry Run] Would prune %s:\n", resourceLabel(res))
+		} else {
+			fmt.Printf("  ✂ Pruning %s:\n", resourceLabel(res))
+		}
+		pruned := make(map[string]string)
+		for _, k := range stale {
+			pruned[k] = res.Tags[k]
+		}
+		printTagDiff(pruned, nil)
+
+		group := strings.Join(stale, ",")
+		groups[group] = append(groups[group], res)
+		staleByGroup[group] = stale
+	}
+	if dryRun || len(groups) == 0 {
+		return
+	}
+
+	m := newManager(ctx, l.k8sClient, cloudPlatform)
+	// The removals are tracked on their own: the sync's Progress journals
+	// the tags it applies.
+	progress := &infraType.Progress{}
+	ctx = infraType.WithProgress(ctx, progress)
+	for _, group := range sortedKeys(groups) {
+		if ctx.Err() != nil {
+			break
+		}
+		if currentSnapshot != nil {
+			currentSnapshot.addRemovals(groups[group], staleByGroup[group])
+		}
+		if err := m.RemoveTags(ctx, groups[group], staleByGroup[group]); err != nil {
+			logFailure(err, "Failed to prune tags")
+		}
+		for _, res := range groups[group] {
+			if progress.Written(res) {
+				l.mu.Lock()
+				l.Remove(res, staleByGroup[group])
+				l.changed = true
+				l.mu.Unlock()
+			}
+		}
+	}
+}
+
+// save writes the ledger to the cluster if the sync changed it, also after
+// the command was interrupted.
+func (l *syncLedger) save(ctx context.Context) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.changed {
+		return
+	}
+
+	ctx, cancel := infraType.OperationContext(ctx)
+	defer cancel()
+	if err := cluster.WriteLedger(ctx, l.k8sClient, l.Ledger); err != nil {
+		logFailure(err, "Failed to save the ownership ledger")
+		return
+	}
+	fmt.Printf("📒 Ownership ledger: %s/%s\n", cluster.LedgerNamespace, cluster.LedgerName)
+}
@@ -1,17 +1,16 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
-	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/azure"
-	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/gcp"
 	"log"
 	"os"
 	"strings"
 	"text/tabwriter"
 
-	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/aws"
 	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
 	"github.com/spf13/cobra"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 var listCmd = &cobra.Command{
@@ -20,43 +19,31 @@ var listCmd = &cobra.Command{
 	Long:  "Display infrastructure resources managed by the OpenShift cluster",
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("📋 Listing cluster resources...")
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
 
 		// Get cluster platform
 		k8sClient := getK8sClient()
-		cloudPlatform, err := getCloudPlatform(k8sClient)
+		cloudPlatform, err := getCloudPlatform(ctx, k8sClient)
 		if err != nil {
 			log.Fatalf("Error determining cloud platform: %v", err)
 		}
 
-		var resources []infraType.CloudResource
-		switch cloudPlatform {
-		case infraType.CloudPlatformAWS:
-			resources, err = aws.ListAWSResources()
-			if err != nil {
-				log.Fatalf("Failed to list AWS resources: %v", err)
-			}
-
-		case infraType.CloudPlatformAzure:
-			// Placeholder for Azure implementation
-			resources, err = azure.ListAzureResources()
-			if err != nil {
-				log.Fatalf("Failed to list Azure resources: %v", err)
-			}
-			//log.Fatalf("Not Implemented Yet")
-		case infraType.CloudPlatformGCP:
-			resources, err = gcp.ListGCPResources()
-			if err != nil {
-				log.Fatalf("Failed to list GCP resources: %v", err)
-			}
-
-		default:
-			log.Fatalf("Unsupported platform: %s", cloudPlatform)
+		resources, err := listResources(ctx, k8sClient, cloudPlatform)
+		if err != nil {
+			fatalError(err, "Failed to list %s resources", cloudPlatform)
 		}
 
 		printResourceTable(resources)
 	},
 }
 
+// listResources discovers the cluster's resources on the given platform.
+func listResources(ctx context.Context, k8sClient client.Client,
+	cloudPlatform infraType.CloudPlatform) ([]infraType.CloudResource, error) {
+	return newManager(ctx, k8sClient, cloudPlatform).ListResources(ctx)
+}
+
 func printResourceTable(resources []infraType.CloudResource) {
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	defer w.Flush()
@@ -76,6 +63,9 @@ func printResourceTable(resources []infraType.CloudResource) {
 		if len(tags) > 50 {
 			tags = tags[:47] + "..."
 		}
+		if res.NotTaggable {
+			tags = "n/a"
+		}
 
 		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
 			res.CloudProvider,
@@ -125,3 +115,10 @@ func printResourceTable(resources []infraType.CloudResource) {
 func init() {
 	RootCmd.AddCommand(listCmd)
 }
+
+// findLoadBalancer returns the load balancer behind a Service ingress, or
+// nil when there is none.
+func findLoadBalancer(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform,
+	hostname, ip string) (*infraType.CloudResource, error) {
+	return newManager(ctx, k8sClient, cloudPlatform).FindLoadBalancer(ctx, hostname, ip)
+}
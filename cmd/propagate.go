@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	"github.com/spf13/cobra"
+	"log"
+)
+
+var (
+	propagateTags        []string
+	propagateFromCluster bool
+	// recreateStorageClasses opts in to deleting and recreating the
+	// StorageClasses whose tag parameters change.
+	recreateStorageClasses bool
+)
+
+var propagateCmd = &cobra.Command{
+	Use:   "propagate",
+	Short: "Propagate tags to StorageClasses and LoadBalancer Services",
+	Long: `Write the desired tags into the Kubernetes objects cloud resources are
+created from later: the tag parameters of CSI StorageClasses (EBS
+tagSpecification_N, Azure Disk/File tags, GCP PD/Filestore labels) and the
+load balancer tag annotation of LoadBalancer Services (AWS and Azure).
+
+StorageClass parameters are immutable, so changed classes are only recreated
+with --recreate-storage-classes; the cluster storage operator may recreate the
+default classes without the tags. Volumes and load balancers that already exist are tagged by sync.`,
+	Example: `  # Preview the changes
+  openshift-metadata-manager propagate --tags CostCenter=1234 --dry-run
+
+  # Propagate the tags recorded in the Infrastructure status
+  openshift-metadata-manager propagate --from-cluster
+
+  # Also recreate the StorageClasses with the tags
+  openshift-metadata-manager propagate --from-cluster --recreate-storage-classes`,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("🔄 Propagating tags to cluster objects...")
+		if offlineMode() {
+			log.Fatal("propagate updates cluster objects and cannot be used offline")
+		}
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		k8sClient := getK8sClient()
+		cloudPlatform, err := getCloudPlatform(ctx, k8sClient)
+		if err != nil {
+			log.Fatalf("Platform detection error: %v", err)
+		}
+		if platform != "" {
+			if cloudPlatform, err = cluster.ParsePlatform(platform); err != nil {
+				fatalError(err, "Invalid --platform")
+			}
+		}
+
+		tagMap, err := desiredTags(ctx, k8sClient, cloudPlatform, propagateTags, propagateFromCluster, false)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(tagMap) == 0 {
+			fmt.Println("ℹ️ No user tags recorded in the Infrastructure status, nothing to propagate")
+			return
+		}
+
+		fmt.Println("💾 StorageClasses:")
+		changes, err := cluster.UpdateStorageClasses(ctx, k8sClient, tagMap, recreateStorageClasses, dryRun)
+		for i := range changes {
+			printObjectChange(&changes[i])
+		}
+		if len(changes) > 0 && !recreateStorageClasses {
+			fmt.Println("  ℹ️ Pass --recreate-storage-classes to recreate the classes with the tags")
+		}
+		if err != nil {
+			logFailure(err, "Error updating StorageClasses")
+		} else if len(changes) == 0 {
+			fmt.Println("  ✓ Up to date")
+		}
+
+		fmt.Println("🌐 LoadBalancer Services:")
+		changes, err = cluster.UpdateServiceAnnotations(ctx, k8sClient, cloudPlatform, tagMap, dryRun)
+		for i := range changes {
+			printObjectChange(&changes[i])
+		}
+		if err != nil {
+			log.Printf("  ⚠ Services not updated: %v", err)
+		} else if len(changes) == 0 {
+			fmt.Println("  ✓ Up to date")
+		}
+
+		exitOnFailures()
+		fmt.Println("✅ Tag propagation completed")
+	},
+}
+
+func init() {
+	propagateCmd.Flags().StringSliceVarP(&propagateTags, "tags", "t", []string{},
+		"Tags to propagate in KEY=VALUE format (comma-separated)")
+	propagateCmd.Flags().BoolVar(&propagateFromCluster, "from-cluster", false,
+		"Use the user tags recorded in the Infrastructure status instead of --tags")
+	propagateCmd.Flags().BoolVar(&recreateStorageClasses, "recreate-storage-classes", false,
+		"Delete and recreate the StorageClasses whose tag parameters change")
+
+	RootCmd.AddCommand(propagateCmd)
+}
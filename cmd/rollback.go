@@ -0,0 +1,199 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/manager"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/snapshot"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	"github.com/spf13/cobra"
+)
+
+// syncSnapshot saves the original tags of the resources a sync changes.
+type syncSnapshot struct {
+	*snapshot.Snapshot
+	path string
+}
+
+// newSyncSnapshot returns the snapshot saved to --snapshot, or to
+// defaultPath.
+func newSyncSnapshot(cloudPlatform infraType.CloudPlatform, defaultPath string) *syncSnapshot {
+	path := snapshotPath
+	if path == "" {
+		path = defaultPath
+	}
+	fmt.Printf("📸 Snapshot: %s\n", path)
+	return &syncSnapshot{Snapshot: snapshot.New(cloudPlatform), path: path}
+}
+
+// add records the resources before the tags are written to them. The sync
+// does not start without a snapshot to roll back to.
+func (s *syncSnapshot) add(resources []infraType.CloudResource, tags map[string]string) {
+	s.Add(resources, tags)
+	if err := s.Save(s.path); err != nil {
+		log.Fatalf("Not syncing without a snapshot: %v", err)
+	}
+}
+
+// addRemovals records the resources before the keys are pruned from them.
+func (s *syncSnapshot) addRemovals(resources []infraType.CloudResource, keys []string) {
+	s.AddRemovals(resources, keys)
+	if err := s.Save(s.path); err != nil {
+		log.Fatalf("Not pruning without a snapshot: %v", err)
+	}
+}
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <snapshot>",
+	Short: "Restore the tags a sync changed from its snapshot",
+	Long: `Undo a sync with the snapshot it saved: tags the sync overwrote get their
+original values back, tags it pruned are restored and tags it added are
+removed. Tags the sync did not change are left alone. Supported on AWS, Azure and GCP.`,
+	Example: `  # Preview the rollback
+  openshift-metadata-manager rollback sync-20250101-120000.snapshot.json --dry-run
+
+  # Roll back
+  openshift-metadata-manager rollback sync-20250101-120000.snapshot.json`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("⏪ Rolling back metadata synchronization...")
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+
+		snap, err := snapshot.Load(args[0])
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		k8sClient := getK8sClient()
+		cloudPlatform, err := getCloudPlatform(ctx, k8sClient)
+		if err != nil {
+			log.Fatalf("Platform detection error: %v", err)
+		}
+		if platform != "" {
+			if cloudPlatform, err = cluster.ParsePlatform(platform); err != nil {
+				fatalError(err, "Invalid --platform")
+			}
+		}
+		if snap.Platform != cloudPlatform {
+			log.Fatalf("The snapshot is of %s resources, not %s", snap.Platform, cloudPlatform)
+		}
+		switch cloudPlatform {
+		case infraType.CloudPlatformAWS, infraType.CloudPlatformAzure, infraType.CloudPlatformGCP:
+		default:
+			fatalError(fmt.Errorf("%w: %s", manager.ErrUnsupportedPlatform, cloudPlatform),
+				"Rollback not supported")
+		}
+
+		m := newManager(ctx, k8sClient, cloudPlatform)
+		resources, err := m.ListResources(ctx)
+		if err != nil {
+			fatalError(err, "Failed to list %s resources", cloudPlatform)
+		}
+		current := make(map[string]infraType.CloudResource, len(resources))
+		for _, res := range resources {
+			current[res.Key()] = res
+		}
+
+		progress := &infraType.Progress{}
+		ctx = infraType.WithProgress(ctx, progress)
+
+		// Resources with the same changes are rolled back together.
+		restores := make(map[string][]infraType.CloudResource)
+		restoreTags := make(map[string]map[string]string)
+		removals := make(map[string][]infraType.CloudResource)
+		removeKeys := make(map[string][]string)
+		for _, r := range snap.Resources {
+			res, ok := current[r.Key()]
+			if !ok {
+				logFailure(fmt.Errorf("%s (%s) no longer exists", r.ID, r.Type), "Not rolling back")
+				continue
+			}
+
+			restore, remove := r.Rollback(res.Tags)
+			if len(restore) == 0 && len(remove) == 0 {
+				continue
+			}
+			if dryRun {
+				fmt.Printf("  🔄 [Dry Run] Would roll back %s:\n", resourceLabel(res))
+			} else {
+				fmt.Printf("  ⏪ Rolling back %s:\n", resourceLabel(res))
+			}
+			rolledBack := mergeTags(res.Tags, restore)
+			for _, k := range remove {
+				delete(rolledBack, k)
+			}
+			printTagDiff(res.Tags, rolledBack)
+
+			if len(restore) > 0 {
+				group := tagsLabel(restore)
+				restores[group] = append(restores[group], res)
+				restoreTags[group] = restore
+			}
+			if len(remove) > 0 {
+				sort.Strings(remove)
+				group := strings.Join(remove, ",")
+				removals[group] = append(removals[group], res)
+				removeKeys[group] = remove
+			}
+		}
+		if dryRun {
+			exitOnFailures()
+			return
+		}
+
+		// The original values are restored first: GCP rewrites the whole
+		// label set of a resource from its listed labels, so the removals
+		// need the restored ones.
+		restored := make(map[string]map[string]string)
+		for _, group := range sortedKeys(restores) {
+			if err := m.ApplyTags(ctx, restores[group], restoreTags[group]); err != nil {
+				logFailure(err, "Failed to restore tags")
+			}
+			for _, res := range restores[group] {
+				if progress.Written(res) {
+					restored[res.Key()] = restoreTags[group]
+				}
+			}
+		}
+		for _, group := range sortedKeys(removals) {
+			targets := make([]infraType.CloudResource, 0, len(removals[group]))
+			for _, res := range removals[group] {
+				if tags, ok := restored[res.Key()]; ok {
+					res.Tags = mergeTags(res.Tags, tags)
+				}
+				targets = append(targets, res)
+			}
+			if err := m.RemoveTags(ctx, targets, removeKeys[group]); err != nil {
+				logFailure(err, "Failed to remove tags")
+			}
+		}
+
+		if err := ctx.Err(); err != nil {
+			printProgress(ctx, resources, progress)
+			fmt.Println("⏯ Run the rollback again to roll back the resources left")
+			os.Exit(exitWithHint(err))
+		}
+		exitOnFailures()
+		fmt.Println("✅ Rollback completed")
+	},
+}
+
+// sortedKeys returns the keys of groups in order.
+func sortedKeys[T any](groups map[string]T) []string {
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func init() {
+	RootCmd.AddCommand(rollbackCmd)
+}
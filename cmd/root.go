@@ -3,19 +3,49 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/aws"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/manager"
 	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	"github.com/go-logr/logr"
+	"github.com/go-logr/stdr"
 	configv1 "github.com/openshift/api/config/v1"
 	"github.com/spf13/cobra"
-	"k8s.io/client-go/kubernetes/scheme"
+	"io"
+	"log"
 	"os"
-	ctrl "sigs.k8s.io/controller-runtime"
+	"os/signal"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"syscall"
+	"time"
 )
 
 var (
-	kubeconfigPath string
-	platform       string
-	dryRun         bool
+	kubeconfigPath    string
+	kubeContext       string
+	platform          string
+	dryRun            bool
+	gcpNetworkProject string
+	timeout           time.Duration
+	operationTimeout  time.Duration
+
+	// Offline mode identifies the cluster without its API server.
+	metadataPath         string
+	offlineInfraID       string
+	offlineRegion        string
+	offlineResourceGroup string
+	offlineProject       string
+
+	sharedClient  client.Client
+	sharedOffline *cluster.OfflineCluster
+
+	// messages receives the progress messages the shared helpers print.
+	// Commands whose stdout is machine-readable point it at stderr.
+	messages io.Writer = os.Stdout
+
+	// abortCtx is canceled by a second SIGINT or SIGTERM and cancels the
+	// cloud API writes in flight.
+	abortCtx, abort = context.WithCancel(context.Background())
 )
 
 var RootCmd = &cobra.Command{
@@ -51,61 +81,169 @@ var versionCmd = &cobra.Command{
 }
 
 func init() {
-	RootCmd.PersistentFlags().StringVarP(&kubeconfigPath, "kubeconfig", "k", "", "Path to kubeconfig file")
-	RootCmd.PersistentFlags().StringVarP(&platform, "platform", "p", "", "Override cloud platform (aws, azure, gcp)")
+	RootCmd.PersistentFlags().StringVarP(&kubeconfigPath, "kubeconfig", "k", "",
+		"Path to kubeconfig file (defaults to $KUBECONFIG, ~/.kube/config or the in-cluster config)")
+	RootCmd.PersistentFlags().StringVar(&kubeContext, "context", "", "Kubeconfig context to use instead of the current one")
+	RootCmd.PersistentFlags().StringVarP(&platform, "platform", "p", "", "Override cloud platform (aws, azure, gcp, ibm, openstack, vsphere, nutanix, powervs)")
 	RootCmd.PersistentFlags().BoolVarP(&dryRun, "dry-run", "d", false, "Dry run mode")
+	RootCmd.PersistentFlags().StringVar(&gcpNetworkProject, "gcp-network-project", "",
+		"GCP shared-VPC host project (defaults to networkProjectID from the install-config)")
+	RootCmd.PersistentFlags().DurationVar(&timeout, "timeout", 0,
+		"Stop starting new cloud API writes after this long, e.g. 30m (0 means no limit)")
+	RootCmd.PersistentFlags().DurationVar(&operationTimeout, "operation-timeout", infraType.DefaultOperationTimeout,
+		"Cancel a single cloud API write, such as an Azure long-running update, after this long")
+
+	RootCmd.PersistentFlags().StringVar(&metadataPath, "metadata", "",
+		"Discover resources from an openshift-install metadata.json instead of the cluster API")
+	RootCmd.PersistentFlags().StringVar(&offlineInfraID, "infra-id", "",
+		"Discover resources of this infraID instead of the cluster API (requires --platform)")
+	RootCmd.PersistentFlags().StringVar(&offlineRegion, "region", "", "Cluster region in offline mode")
+	RootCmd.PersistentFlags().StringVar(&offlineResourceGroup, "resource-group", "",
+		"Azure, IBM Cloud or PowerVS resource group in offline mode")
+	RootCmd.PersistentFlags().StringVar(&offlineProject, "project", "", "GCP project in offline mode")
 }
 
+// Execute runs the command with a context that is canceled by the first
+// SIGINT or SIGTERM: no new cloud API writes start and the writes in flight
+// finish. A second signal cancels those as well.
 func Execute() {
-	if err := RootCmd.Execute(); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		sig := <-signals
+		log.Printf("⏹ %s received, finishing the writes in flight (repeat to cancel them)", sig)
+		cancel()
+		<-signals
+		log.Print("⏹ Canceling the writes in flight")
+		abort()
+	}()
+
+	if err := RootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
 
+// getK8sClient returns the client shared by the command and the provider
+// packages, connected to the cluster selected by --kubeconfig and --context.
 func getK8sClient() client.Client {
-	cfg, err := ctrl.GetConfig()
-	if err != nil {
-		fmt.Println("Error getting Kubernetes config:", err)
-		os.Exit(1)
+	if sharedClient == nil {
+		var c client.Client
+		var err error
+		if offlineMode() {
+			c, err = cluster.NewOfflineClient(getOfflineCluster())
+		} else {
+			c, err = cluster.NewClient(kubeconfigPath, kubeContext)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		sharedClient = c
 	}
+	return sharedClient
+}
 
-	// Register OpenShift config API schema
-	configv1.AddToScheme(scheme.Scheme)
+// offlineMode reports whether the cluster is identified by --metadata or
+// --infra-id instead of its API server.
+func offlineMode() bool {
+	return metadataPath != "" || offlineInfraID != ""
+}
 
-	// Create a new controller-runtime client
-	k8sClient, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
-	if err != nil {
-		fmt.Println("Error creating Kubernetes client:", err)
-		os.Exit(1)
+// getOfflineCluster combines --metadata with the explicit offline flags,
+// which take precedence.
+func getOfflineCluster() *cluster.OfflineCluster {
+	if sharedOffline != nil {
+		return sharedOffline
+	}
+	c := &cluster.OfflineCluster{}
+	if metadataPath != "" {
+		var err error
+		if c, err = cluster.LoadMetadata(metadataPath); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if platform != "" {
+		p, err := cluster.ParsePlatform(platform)
+		if err != nil {
+			fatalError(err, "Invalid --platform")
+		}
+		c.Platform = p
+	} else if c.Platform == "" {
+		log.Fatal("--platform is required with --infra-id")
+	}
+	if offlineInfraID != "" {
+		c.InfraID = offlineInfraID
+	}
+	if offlineRegion != "" {
+		c.Region = offlineRegion
+	}
+	if offlineResourceGroup != "" {
+		c.ResourceGroup = offlineResourceGroup
 	}
-	return k8sClient
+	if offlineProject != "" {
+		c.Project = offlineProject
+	}
+
+	fmt.Fprintf(messages, "📴 Offline mode: %s cluster %s\n", c.Platform, c.InfraID)
+	sharedOffline = c
+	return c
 }
 
-func getCloudPlatform(k8sClient client.Client) (infraType.CloudPlatform, error) {
+func getCloudPlatform(ctx context.Context, k8sClient client.Client) (infraType.CloudPlatform, error) {
 	infra := &configv1.Infrastructure{}
 	infraKey := client.ObjectKey{Name: "cluster"}
 
 	// Fetch the Infrastructure resource
-	if err := k8sClient.Get(context.Background(), infraKey, infra); err != nil {
+	if err := k8sClient.Get(ctx, infraKey, infra); err != nil {
 		return "", fmt.Errorf("failed to get Infrastructure resource: %v", err)
 	}
-	return mapPlatformType(infra.Status.PlatformStatus.Type), nil
+	return cluster.Platform(infra), nil
+}
+
+// getClusterTags returns the user tags the installer recorded in the
+// Infrastructure status.
+func getClusterTags(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform,
+	tagBindings bool) (map[string]string, error) {
+
+	infra := &configv1.Infrastructure{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "cluster"}, infra); err != nil {
+		return nil, fmt.Errorf("failed to get Infrastructure resource: %v", err)
+	}
+	return cluster.UserTags(infra, cloudPlatform, tagBindings)
 }
 
-func mapPlatformType(platformType configv1.PlatformType) infraType.CloudPlatform {
-	switch platformType {
-	case configv1.AWSPlatformType:
-		return infraType.CloudPlatformAWS
-	case configv1.AzurePlatformType:
-		return infraType.CloudPlatformAzure
-	case configv1.GCPPlatformType:
-		return infraType.CloudPlatformGCP
-	case configv1.IBMCloudPlatformType:
-		return infraType.CloudPlatformIBM
-	case configv1.OpenStackPlatformType:
-		return infraType.CloudPlatformOpenStack
-	default:
-		return infraType.CloudPlatformUnknown
+// commandContext returns the context commands pass to the provider packages:
+// the command's context, done after --timeout, with a logger for their
+// progress messages and warnings and the --operation-timeout for writes.
+func commandContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	ctx, cancel := cmd.Context(), context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	ctx = infraType.WithOperations(ctx, operationTimeout, abortCtx)
+	// The AWS provider takes the region of an offline cluster from the
+	// context, the SDK configuration does not have it.
+	if offlineMode() {
+		if c := getOfflineCluster(); c.Platform == infraType.CloudPlatformAWS && c.Region != "" {
+			ctx = aws.WithRegion(ctx, c.Region)
+		}
+	}
+	return logr.NewContext(ctx, stdr.New(log.Default())), cancel
+}
+
+// newManager returns the manager commands discover and tag resources with.
+func newManager(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform) *manager.Manager {
+	m, err := manager.New(ctx, manager.Options{
+		Client:            k8sClient,
+		Platform:          cloudPlatform,
+		GCPNetworkProject: gcpNetworkProject,
+	})
+	if err != nil {
+		fatalError(err, "Failed to set up the %s resource manager", cloudPlatform)
 	}
+	return m
 }
@@ -1,17 +1,54 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/aws"
 	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/azure"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
 	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/gcp"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/ibm"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/manager"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/nutanix"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/openstack"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/vsphere"
 	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
 	"github.com/spf13/cobra"
 	"log"
+	"os"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"strings"
 )
 
 var (
-	tagsToSync []string
+	tagsToSync     []string
+	gcpTagBindings bool
+	// unbindTagKeys are namespaced tag keys whose bindings are deleted with
+	// --gcp-tag-bindings.
+	unbindTagKeys   []string
+	syncFromCluster bool
+	updateConfig    bool
+	// rollControlPlane opts in to updating a ControlPlaneMachineSet whose
+	// strategy replaces the control plane machines.
+	rollControlPlane bool
+	// namespaceTagKeys are copied from Namespace labels and annotations to
+	// the disks and load balancers created for the namespace.
+	namespaceTagKeys []string
+	// journalPath and resumePath are the sync journal to write and the one
+	// to resume.
+	journalPath string
+	resumePath  string
+	// currentJournal records the resources syncPlatform writes.
+	currentJournal *syncJournal
+	// snapshotPath is where the original tags are saved for rollback.
+	snapshotPath string
+	// currentSnapshot saves the tags of the resources before syncPlatform
+	// writes them.
+	currentSnapshot *syncSnapshot
+	// prune removes the keys the tool added earlier that are no longer
+	// desired, as recorded in currentLedger.
+	prune         bool
+	currentLedger *syncLedger
 	//dryRun     bool
 )
 
@@ -24,62 +61,244 @@ associated with an OpenShift cluster. Existing tags will be preserved.`,
   openshift-metadata-manager sync --tags Owner=DevOps,Environment=Production
   
   # Dry run for AWS
-  openshift-metadata-manager sync --platform aws --tags CostCenter=1234 --dry-run`,
+  openshift-metadata-manager sync --platform aws --tags CostCenter=1234 --dry-run
+
+  # Bind GCP Resource Manager tags instead of labels
+  openshift-metadata-manager sync --platform gcp --gcp-tag-bindings --tags 123456789/env=prod
+
+  # Delete the bindings of a GCP tag key that is no longer wanted
+  openshift-metadata-manager sync --platform gcp --gcp-tag-bindings --remove-tag-bindings 123456789/team
+
+  # Apply the tags recorded in the Infrastructure status and report drift
+  openshift-metadata-manager sync --from-cluster
+
+  # Also tag machines created later by MachineSets
+  openshift-metadata-manager sync --tags CostCenter=1234 --update-cluster-config
+
+  # Add each namespace's cost-center label to its volumes and load balancers
+  openshift-metadata-manager sync --tags Owner=DevOps --namespace-tag-keys cost-center
+
+  # Continue a sync that died halfway
+  openshift-metadata-manager sync --resume sync-20250101-120000.jsonl
+
+  # Remove the tags an earlier sync added that are no longer in --tags
+  openshift-metadata-manager sync --tags Owner=DevOps --prune
+
+  # Undo the sync
+  openshift-metadata-manager rollback sync-20250101-120000.snapshot.json`,
 	Run: func(cmd *cobra.Command, args []string) {
 		fmt.Println("🔄 Starting metadata synchronization...")
-
-		// Parse and validate tags
-		if len(tagsToSync) == 0 {
-			log.Fatal("No tags specified for synchronization")
+		ctx, cancel := commandContext(cmd)
+		defer cancel()
+		if offlineMode() && (syncFromCluster || updateConfig || len(namespaceTagKeys) > 0) {
+			log.Fatal("--from-cluster, --update-cluster-config and --namespace-tag-keys need the cluster API and cannot be used offline")
 		}
-		tagMap, err := parseTags(tagsToSync)
-		if err != nil {
-			log.Fatal(err)
+		if resumePath != "" && (len(tagsToSync) > 0 || syncFromCluster || updateConfig || len(namespaceTagKeys) > 0 ||
+			gcpTagBindings || dryRun || prune) {
+			log.Fatal("--resume takes the tags from the journal and cannot be combined with --tags, --from-cluster, " +
+				"--update-cluster-config, --namespace-tag-keys, --gcp-tag-bindings, --dry-run or --prune")
+		}
+		if len(unbindTagKeys) > 0 && !gcpTagBindings {
+			log.Fatal("--remove-tag-bindings needs --gcp-tag-bindings")
+		}
+		if prune && (offlineMode() || gcpTagBindings) {
+			log.Fatal("--prune needs the ownership ledger in the cluster and cannot be used offline or with --gcp-tag-bindings")
 		}
 
 		// Detect platform
 		k8sClient := getK8sClient()
-		cloudPlatform, err := getCloudPlatform(k8sClient)
+		cloudPlatform, err := getCloudPlatform(ctx, k8sClient)
 		if err != nil {
 			log.Fatalf("Platform detection error: %v", err)
 		}
 
 		// Allow platform override
 		if platform != "" {
-			cloudPlatform = infraType.CloudPlatform(platform)
+			if cloudPlatform, err = cluster.ParsePlatform(platform); err != nil {
+				fatalError(err, "Invalid --platform")
+			}
+		}
+
+		if prune {
+			switch cloudPlatform {
+			case infraType.CloudPlatformAWS, infraType.CloudPlatformAzure, infraType.CloudPlatformGCP:
+			default:
+				fatalError(fmt.Errorf("%w: %s", manager.ErrUnsupportedPlatform, cloudPlatform), "--prune not supported")
+			}
+		}
+
+		// Parse and validate tags
+		var tagMap map[string]string
+		if resumePath == "" {
+			if len(unbindTagKeys) > 0 && len(tagsToSync) == 0 && !syncFromCluster {
+				// Only bindings are deleted.
+				tagMap = map[string]string{}
+			} else if tagMap, err = desiredTags(ctx, k8sClient, cloudPlatform, tagsToSync, syncFromCluster, gcpTagBindings); err != nil {
+				log.Fatal(err)
+			}
+			if len(tagMap) == 0 && len(unbindTagKeys) == 0 {
+				fmt.Println("ℹ️ No user tags recorded in the Infrastructure status, nothing to sync")
+				return
+			}
+		}
+
+		resources, err := listResources(ctx, k8sClient, cloudPlatform)
+		if err != nil {
+			fatalError(err, "Failed to list %s resources", cloudPlatform)
+		}
+		// Every write of a sync is journaled and snapshotted; a dry run
+		// writes nothing on any platform. Tag bindings are not part of the
+		// listed tags, so they are neither journaled, snapshotted nor owned.
+		// The ledger is read in dry runs for --prune.
+		if !offlineMode() && !gcpTagBindings {
+			if currentLedger, err = readSyncLedger(ctx, k8sClient); err != nil {
+				if prune {
+					fatalError(err, "Failed to read the ownership ledger")
+				}
+				log.Printf("⚠ Not recording the tags the sync adds, --prune will not remove them: %v", err)
+			}
+		}
+		if !dryRun && !gcpTagBindings {
+			currentJournal = openSyncJournal(cloudPlatform)
+			defer currentJournal.Close()
+			currentSnapshot = newSyncSnapshot(cloudPlatform, syncFileName(".snapshot.json"))
+		}
+		progress := &infraType.Progress{OnWrite: func(res infraType.CloudResource, err error) {
+			if currentJournal != nil {
+				currentJournal.done(res, err)
+			}
+			if currentLedger != nil {
+				currentLedger.done(res, err)
+			}
+		}}
+		ctx = infraType.WithProgress(ctx, progress)
+
+		// Tag bindings are not part of the listed tags, their drift is
+		// reported per resource by syncGCPTagBindings.
+		if syncFromCluster && !gcpTagBindings {
+			reportDrift(resources, tagMap)
+		}
+
+		if resumePath != "" {
+			resumeSync(ctx, k8sClient, cloudPlatform, resources, currentJournal)
+		} else if len(namespaceTagKeys) == 0 {
+			syncPlatform(ctx, k8sClient, cloudPlatform, resources, tagMap)
+		} else {
+			for _, group := range groupByNamespace(ctx, k8sClient, cloudPlatform, resources, tagMap) {
+				if len(group.Resources) > 0 {
+					syncPlatform(ctx, k8sClient, cloudPlatform, group.Resources, group.Tags)
+				}
+			}
 		}
 
-		// Execute platform-specific sync
-		switch cloudPlatform {
-		case infraType.CloudPlatformAWS:
-			syncAWSTags(tagMap)
-		case infraType.CloudPlatformAzure:
-			syncAzureTags(tagMap)
-		case infraType.CloudPlatformGCP:
-			syncGCPTags(tagMap)
-		default:
-			log.Fatalf("Metadata sync not supported for platform: %s", cloudPlatform)
+		if currentLedger != nil {
+			currentLedger.save(ctx)
+		}
+		if err := ctx.Err(); err != nil {
+			printProgress(ctx, resources, progress)
+			os.Exit(exitWithHint(err))
+		}
+		if updateConfig {
+			updateClusterConfig(ctx, k8sClient, cloudPlatform, tagMap)
 		}
 
+		exitOnFailures()
 		fmt.Println("✅ Metadata synchronization completed")
 	},
 }
 
-// Platform-specific sync implementations
-func syncAWSTags(tags map[string]string) {
-	fmt.Printf("🔄 Syncing %d tags to AWS resources\n", len(tags))
+// syncPlatform executes the platform-specific sync.
+func syncPlatform(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform,
+	resources []infraType.CloudResource, tagMap map[string]string) {
+
+	if currentSnapshot != nil {
+		currentSnapshot.add(resources, tagMap)
+	}
+	if currentJournal != nil {
+		currentJournal.plan(resources, tagMap)
+	}
+	if currentLedger != nil {
+		currentLedger.plan(resources, tagMap)
+	}
+
+	switch cloudPlatform {
+	case infraType.CloudPlatformAWS:
+		syncAWSTags(ctx, resources, tagMap)
+	case infraType.CloudPlatformAzure:
+		syncAzureTags(ctx, resources, tagMap)
+	case infraType.CloudPlatformGCP:
+		if gcpTagBindings {
+			syncGCPTagBindings(ctx, resources, tagMap)
+		} else {
+			syncGCPTags(ctx, resources, tagMap)
+		}
+	case infraType.CloudPlatformIBM:
+		syncIBMTags(ctx, resources, tagMap, "IBM Cloud")
+	case infraType.CloudPlatformPowerVS:
+		syncIBMTags(ctx, resources, tagMap, "PowerVS")
+	case infraType.CloudPlatformOpenStack:
+		syncOpenStackTags(ctx, k8sClient, resources, tagMap)
+	case infraType.CloudPlatformVSphere:
+		syncVSphereTags(ctx, k8sClient, resources, tagMap)
+	case infraType.CloudPlatformNutanix:
+		syncNutanixTags(ctx, k8sClient, resources, tagMap)
+	default:
+		fatalError(fmt.Errorf("%w: %s", manager.ErrUnsupportedPlatform, cloudPlatform), "Metadata sync not supported")
+	}
+
+	if prune {
+		currentLedger.prune(ctx, cloudPlatform, resources, tagMap)
+	}
+}
+
+// groupByNamespace splits the resources by the tags of the namespace their
+// PersistentVolumeClaim or Service lives in, on top of the cluster-wide tags.
+func groupByNamespace(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform,
+	resources []infraType.CloudResource, tags map[string]string) []cluster.ResourceGroup {
+
+	fmt.Printf("🏷️ Mapping disks and load balancers to namespaces (keys: %s)\n", strings.Join(namespaceTagKeys, ", "))
 
-	resources, err := aws.ListAWSResources()
+	tagsByNamespace, err := cluster.ListNamespaceTags(ctx, k8sClient, namespaceTagKeys)
 	if err != nil {
-		log.Fatalf("Failed to list AWS resources: %v", err)
+		fatalError(err, "Failed to read namespace tags")
 	}
+	namespaced, err := cluster.ListNamespacedResources(ctx, k8sClient, func(hostname, ip string) (*infraType.CloudResource, error) {
+		return findLoadBalancer(ctx, k8sClient, cloudPlatform, hostname, ip)
+	})
+	if err != nil {
+		fatalError(err, "Failed to map resources to namespaces")
+	}
+
+	for _, n := range namespaced {
+		if nsTags := tagsByNamespace[n.Namespace]; len(nsTags) > 0 {
+			fmt.Printf("  %s/%s → %s: %v\n", n.Namespace, n.Owner, resourceLabel(n.Resource), nsTags)
+		}
+	}
+	return cluster.GroupByNamespaceTags(resources, namespaced, tagsByNamespace, tags)
+}
+
+func resourceLabel(res infraType.CloudResource) string {
+	if res.ID != "" {
+		return res.ID
+	}
+	return res.Name
+}
+
+// Platform-specific sync implementations
+func syncAWSTags(ctx context.Context, resources []infraType.CloudResource, tags map[string]string) {
+	fmt.Printf("🔄 Syncing %d tags to AWS resources\n", len(tags))
+	if dryRun {
+		printDryRunTags(resources, tags)
+		return
+	}
+
 	for _, res := range resources {
 		fmt.Println("Resources to be updated:", res.Type)
 		fmt.Println("ResourceID :", res.ID, res.Name)
 	}
 
-	if err := aws.UpdateResourceTags(resources, tags); err != nil {
-		log.Printf("  ❌ Error updating tags: %v", err)
+	if err := aws.UpdateResourceTags(ctx, resources, tags); err != nil {
+		logFailure(err, "Error updating tags")
 	} else {
 		fmt.Println("  ✓ Tags updated successfully")
 	}
@@ -114,12 +333,11 @@ func syncAWSTags(tags map[string]string) {
 	//}
 }
 
-func syncAzureTags(tags map[string]string) {
+func syncAzureTags(ctx context.Context, resources []infraType.CloudResource, tags map[string]string) {
 	fmt.Printf("🔄 Syncing %d tags to Azure resources\n", len(tags))
-
-	resources, err := azure.ListAzureResources()
-	if err != nil {
-		log.Fatalf("Failed to list Azure resources: %v", err)
+	if dryRun {
+		printDryRunTags(resources, tags)
+		return
 	}
 
 	for _, res := range resources {
@@ -127,8 +345,8 @@ func syncAzureTags(tags map[string]string) {
 		fmt.Println("ResourceID :", res.ID, res.Name)
 	}
 
-	if err = azure.UpdateResourceTags(resources, tags); err != nil {
-		log.Printf("  ❌ Error updating tags: %v", err)
+	if err := azure.UpdateResourceTags(ctx, resources, tags); err != nil {
+		logFailure(err, "Error updating tags")
 	} else {
 		fmt.Println("  ✓ Tags updated successfully")
 	}
@@ -151,17 +369,20 @@ func syncAzureTags(tags map[string]string) {
 	//}
 }
 
-func syncGCPTags(tags map[string]string) {
+func syncGCPTags(ctx context.Context, resources []infraType.CloudResource, tags map[string]string) {
 	fmt.Printf("🔄 Syncing %d labels to GCP resources\n", len(tags))
 
-	resources, err := gcp.ListGCPResources()
-	if err != nil {
-		log.Fatalf("Failed to list GCP resources: %v", err)
-	}
-
 	for _, res := range resources {
+		if ctx.Err() != nil {
+			break
+		}
 		fmt.Printf("Processing %s (%s)\n", res.ID, res.Type)
 
+		if res.NotTaggable {
+			fmt.Println("  ⚠ Resource does not support labels, skipping")
+			continue
+		}
+
 		newLabels := mergeTags(res.Tags, tags)
 		if dryRun {
 			fmt.Println("  🔄 [Dry Run] Label changes:")
@@ -169,14 +390,326 @@ func syncGCPTags(tags map[string]string) {
 			continue
 		}
 
-		if err := gcp.UpdateResourceTags(res, newLabels); err != nil {
-			log.Printf("  ❌ Error updating labels: %v", err)
+		if err := writeResource(ctx, res, func(ctx context.Context) error {
+			return gcp.UpdateResourceTags(ctx, res, newLabels)
+		}); err != nil {
+			logFailure(err, "Error updating labels")
 		} else {
 			fmt.Println("  ✓ Labels updated successfully")
 		}
 	}
 }
 
+// printDryRunTags prints the tag changes a sync would make to the
+// resources, like syncGCPTags does per resource in a dry run.
+func printDryRunTags(resources []infraType.CloudResource, tags map[string]string) {
+	for _, res := range resources {
+		fmt.Printf("Processing %s (%s)\n", res.ID, res.Type)
+		if res.NotTaggable {
+			fmt.Println("  ⚠ Resource does not support tags, skipping")
+			continue
+		}
+		fmt.Println("  🔄 [Dry Run] Tag changes:")
+		printTagDiff(res.Tags, mergeTags(res.Tags, tags))
+	}
+}
+
+// syncGCPTagBindings binds Resource Manager tags instead of setting labels,
+// and deletes the bindings of the --remove-tag-bindings keys. Tags are given
+// as <org-id|project-id>/<key>=<value>. Bindings are not
+// journaled, snapshotted or recorded in the ownership ledger: --resume,
+// rollback and --prune do not cover them.
+func syncGCPTagBindings(ctx context.Context, resources []infraType.CloudResource, tags map[string]string) {
+	fmt.Printf("🔄 Syncing %d tag bindings to GCP resources\n", len(tags))
+
+	if err := gcp.IsValidGCPResourceTag(tags); err != nil {
+		fatalError(err, "Invalid GCP tags")
+	}
+	for _, k := range unbindTagKeys {
+		if !strings.Contains(k, "/") {
+			log.Fatalf("--remove-tag-bindings: %q is not a namespaced tag key (<org-id|project-id>/<key>)", k)
+		}
+		if _, ok := tags[k]; ok {
+			log.Fatalf("--remove-tag-bindings: %q is also in the tags to bind", k)
+		}
+	}
+	// Fail early, before touching any resource, if a tag value does not
+	// exist. The values are resolved once for all resources.
+	resolved, err := gcp.ResolveTagValues(ctx, tags)
+	if err != nil {
+		fatalError(err, "Failed to resolve GCP tag values")
+	}
+
+	for _, res := range resources {
+		if ctx.Err() != nil {
+			break
+		}
+		fmt.Printf("Processing %s (%s)\n", res.ID, res.Type)
+
+		if !gcp.SupportsTagBindings(res) {
+			fmt.Println("  ⚠ Resource does not support tag bindings, skipping")
+			continue
+		}
+
+		current, err := gcp.GetResourceTagBindings(ctx, res)
+		if err != nil {
+			logFailure(err, "Error reading tag bindings")
+			continue
+		}
+
+		if syncFromCluster {
+			if drifted := driftedTags(current, tags); len(drifted) > 0 {
+				fmt.Println("  ⚠ Tag bindings have drifted from the cluster configuration")
+			}
+		}
+
+		var remove []string
+		for _, k := range unbindTagKeys {
+			if _, ok := current[k]; ok {
+				remove = append(remove, k)
+			}
+		}
+
+		if dryRun {
+			after := mergeTags(current, tags)
+			for _, k := range remove {
+				delete(after, k)
+			}
+			fmt.Println("  🔄 [Dry Run] Tag binding changes:")
+			printTagDiff(current, after)
+			continue
+		}
+
+		if err := writeResource(ctx, res, func(ctx context.Context) error {
+			return gcp.UpdateResourceTagBindings(ctx, res, resolved, remove)
+		}); err != nil {
+			logFailure(err, "Error updating tag bindings")
+		} else {
+			fmt.Println("  ✓ Tag bindings updated successfully")
+		}
+	}
+}
+
+// syncIBMTags serves IBM Cloud VPC and PowerVS clusters, which share the
+// Global Tagging API.
+func syncIBMTags(ctx context.Context, resources []infraType.CloudResource, tags map[string]string, name string) {
+	fmt.Printf("🔄 Syncing %d tags to %s resources\n", len(tags), name)
+
+	if err := ibm.IsValidIBMTag(tags); err != nil {
+		fatalError(err, "Invalid %s tags", name)
+	}
+
+	for _, res := range resources {
+		fmt.Printf("Processing %s (%s)\n", res.ID, res.Type)
+		if dryRun {
+			fmt.Println("  🔄 [Dry Run] Tag changes:")
+			printTagDiff(res.Tags, mergeTags(res.Tags, tags))
+		}
+	}
+	if dryRun {
+		return
+	}
+
+	if err := ibm.UpdateResourceTags(ctx, resources, tags); err != nil {
+		logFailure(err, "Error updating tags")
+	} else {
+		fmt.Println("  ✓ Tags updated successfully")
+	}
+}
+
+func syncOpenStackTags(ctx context.Context, k8sClient client.Client, resources []infraType.CloudResource, tags map[string]string) {
+	fmt.Printf("🔄 Syncing %d tags to OpenStack resources\n", len(tags))
+
+	if err := openstack.IsValidOpenStackTag(tags); err != nil {
+		fatalError(err, "Invalid OpenStack tags")
+	}
+
+	for _, res := range resources {
+		fmt.Printf("Processing %s (%s)\n", res.ID, res.Type)
+		if dryRun {
+			fmt.Println("  🔄 [Dry Run] Tag changes:")
+			printTagDiff(res.Tags, mergeTags(res.Tags, tags))
+		}
+	}
+	if dryRun {
+		return
+	}
+
+	if err := openstack.UpdateResourceTags(ctx, k8sClient, resources, tags); err != nil {
+		logFailure(err, "Error updating tags")
+	} else {
+		fmt.Println("  ✓ Tags updated successfully")
+	}
+}
+
+func syncVSphereTags(ctx context.Context, k8sClient client.Client, resources []infraType.CloudResource, tags map[string]string) {
+	fmt.Printf("🔄 Syncing %d tags to vSphere resources\n", len(tags))
+
+	if err := vsphere.IsValidVSphereTag(tags); err != nil {
+		fatalError(err, "Invalid vSphere tags")
+	}
+
+	var taggable []infraType.CloudResource
+	for _, res := range resources {
+		fmt.Printf("Processing %s (%s)\n", res.ID, res.Type)
+		if res.NotTaggable {
+			fmt.Println("  ⚠ Resource does not support vCenter tags, skipping")
+			continue
+		}
+		taggable = append(taggable, res)
+		if dryRun {
+			fmt.Println("  🔄 [Dry Run] Tag changes:")
+			printTagDiff(res.Tags, mergeTags(res.Tags, tags))
+		}
+	}
+	if dryRun {
+		return
+	}
+
+	if err := vsphere.UpdateResourceTags(ctx, k8sClient, taggable, tags); err != nil {
+		logFailure(err, "Error updating tags")
+	} else {
+		fmt.Println("  ✓ Tags updated successfully")
+	}
+}
+
+func syncNutanixTags(ctx context.Context, k8sClient client.Client, resources []infraType.CloudResource, tags map[string]string) {
+	fmt.Printf("🔄 Syncing %d categories to Nutanix resources\n", len(tags))
+
+	if err := nutanix.IsValidNutanixTag(tags); err != nil {
+		fatalError(err, "Invalid Nutanix categories")
+	}
+
+	for _, res := range resources {
+		fmt.Printf("Processing %s (%s)\n", res.ID, res.Type)
+		if dryRun {
+			fmt.Println("  🔄 [Dry Run] Category changes:")
+			printTagDiff(res.Tags, mergeTags(res.Tags, tags))
+		}
+	}
+	if dryRun {
+		return
+	}
+
+	if err := nutanix.UpdateResourceTags(ctx, k8sClient, resources, tags); err != nil {
+		logFailure(err, "Error updating categories")
+	} else {
+		fmt.Println("  ✓ Categories updated successfully")
+	}
+}
+
+// updateClusterConfig writes the tags where the cluster picks them up for
+// resources it creates later: the Infrastructure spec and the machine
+// templates of MachineSets and ControlPlaneMachineSets.
+func updateClusterConfig(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform,
+	tags map[string]string) {
+
+	fmt.Println("🔧 Updating cluster configuration...")
+
+	change, err := cluster.UpdateInfrastructureTags(ctx, k8sClient, cloudPlatform, tags, dryRun)
+	if err != nil {
+		log.Printf("  ⚠ Infrastructure not updated: %v", err)
+	} else {
+		printObjectChange(change)
+	}
+
+	changes, err := cluster.UpdateMachineTemplates(ctx, k8sClient, cloudPlatform, tags, gcpTagBindings,
+		rollControlPlane, dryRun)
+	for i := range changes {
+		printObjectChange(&changes[i])
+		if changes[i].Skipped != "" {
+			fmt.Println("    ℹ️ Pass --roll-control-plane to update it and replace the control plane machines")
+		}
+	}
+	if err != nil {
+		logFailure(err, "Error updating machine templates")
+	}
+}
+
+// desiredTags returns the tags given with --tags, or the user tags recorded
+// in the Infrastructure status when fromCluster is set.
+func desiredTags(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform, tags []string,
+	fromCluster, tagBindings bool) (map[string]string, error) {
+
+	if !fromCluster {
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("no tags specified, use --tags or --from-cluster")
+		}
+		return parseTags(tags)
+	}
+
+	tagMap, err := getClusterTags(ctx, k8sClient, cloudPlatform, tagBindings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tags from the cluster: %w", err)
+	}
+	if len(tagMap) > 0 {
+		fmt.Fprintf(messages, "📥 Using %d tags from the Infrastructure status\n", len(tagMap))
+	}
+	return tagMap, nil
+}
+
+// writeResource runs a write the command makes to a single resource itself,
+// the way the provider packages run theirs: with the --operation-timeout,
+// and recorded for printProgress.
+func writeResource(ctx context.Context, res infraType.CloudResource, write func(ctx context.Context) error) error {
+	opCtx, cancel := infraType.OperationContext(ctx)
+	defer cancel()
+	err := write(opCtx)
+	infraType.RecordWrite(ctx, res, err)
+	return err
+}
+
+// printProgress reports which resources were written before the command was
+// interrupted or ran into --timeout.
+func printProgress(ctx context.Context, resources []infraType.CloudResource, progress *infraType.Progress) {
+	var applied, notApplied []string
+	for _, res := range resources {
+		if progress.Written(res) {
+			applied = append(applied, resourceLabel(res))
+		} else {
+			notApplied = append(notApplied, resourceLabel(res))
+		}
+	}
+
+	fmt.Printf("⏹ Stopped early: %v\n", context.Cause(ctx))
+	fmt.Printf("  ✓ Applied to %d resources\n", len(applied))
+	for _, label := range applied {
+		fmt.Printf("    %s\n", label)
+	}
+	fmt.Printf("  ✗ Not applied to %d resources\n", len(notApplied))
+	for _, label := range notApplied {
+		fmt.Printf("    %s\n", label)
+	}
+	if updateConfig {
+		fmt.Println("  ✗ Cluster configuration not updated")
+	}
+	if currentJournal != nil {
+		fmt.Printf("⏯ Continue with: sync --resume %s\n", currentJournal.Path())
+	}
+}
+
+func printObjectChange(change *cluster.ObjectChange) {
+	if change == nil {
+		return
+	}
+
+	name := change.Name
+	if change.Namespace != "" {
+		name = change.Namespace + "/" + name
+	}
+	if change.Skipped != "" {
+		fmt.Printf("  ⏭ Skipped %s %s, %s:\n", change.Kind, name, change.Skipped)
+	} else if dryRun {
+		fmt.Printf("  🔄 [Dry Run] %s %s:\n", change.Kind, name)
+	} else {
+		fmt.Printf("  ✓ Updated %s %s:\n", change.Kind, name)
+	}
+	printTagDiff(change.Old, change.New)
+	if change.Warning != "" {
+		fmt.Printf("    ⚠ %s\n", change.Warning)
+	}
+}
+
 // Helper functions
 func mergeTags(existing, updates map[string]string) map[string]string {
 	merged := make(map[string]string)
@@ -189,6 +722,45 @@ func mergeTags(existing, updates map[string]string) map[string]string {
 	return merged
 }
 
+// driftedTags returns the desired tags that are missing from current or set
+// to a different value.
+func driftedTags(current, desired map[string]string) map[string]string {
+	drifted := make(map[string]string)
+	for k, v := range desired {
+		if currentVal, exists := current[k]; !exists || currentVal != v {
+			drifted[k] = v
+		}
+	}
+	return drifted
+}
+
+// reportDrift prints, for every taggable resource, the desired tags it is
+// missing or carries with a different value.
+func reportDrift(resources []infraType.CloudResource, desired map[string]string) int {
+	count := 0
+	for _, res := range resources {
+		if res.NotTaggable {
+			continue
+		}
+		drifted := driftedTags(res.Tags, desired)
+		if len(drifted) == 0 {
+			continue
+		}
+
+		count++
+		current := make(map[string]string)
+		for k := range drifted {
+			if v, exists := res.Tags[k]; exists {
+				current[k] = v
+			}
+		}
+		fmt.Printf("  ⚠ %s (%s) has drifted from the cluster configuration:\n", res.ID, res.Type)
+		printTagDiff(current, drifted)
+	}
+	fmt.Printf("📊 %d of %d resources have drifted\n", count, len(resources))
+	return count
+}
+
 func printTagDiff(oldTags, newTags map[string]string) {
 	for k, newVal := range newTags {
 		if oldVal, exists := oldTags[k]; exists {
@@ -211,7 +783,27 @@ func init() {
 		"Tags to sync in KEY=VALUE format (comma-separated)")
 	syncCmd.Flags().BoolVarP(&dryRun, "dry-run", "d", false,
 		"Preview changes without applying")
-	syncCmd.MarkFlagRequired("tags")
+	syncCmd.Flags().BoolVar(&gcpTagBindings, "gcp-tag-bindings", false,
+		"On GCP, bind Resource Manager tags (<org-id|project-id>/<key>=<value>) instead of setting labels; bindings are not journaled or snapshotted")
+	syncCmd.Flags().StringSliceVar(&unbindTagKeys, "remove-tag-bindings", nil,
+		"With --gcp-tag-bindings, delete the bindings of these namespaced tag keys (<org-id|project-id>/<key>) from the resources")
+	syncCmd.Flags().BoolVar(&syncFromCluster, "from-cluster", false,
+		"Use the user tags recorded in the Infrastructure status (resourceTags/resourceLabels) instead of --tags")
+	syncCmd.Flags().BoolVar(&updateConfig, "update-cluster-config", false,
+		"Also write the tags to the Infrastructure spec and to the providerSpec of MachineSets and ControlPlaneMachineSets")
+	syncCmd.Flags().BoolVar(&rollControlPlane, "roll-control-plane", false,
+		"With --update-cluster-config, also update a ControlPlaneMachineSet with the RollingUpdate strategy, which replaces the control plane machines")
+
+	syncCmd.Flags().StringSliceVar(&namespaceTagKeys, "namespace-tag-keys", nil,
+		"Namespace label/annotation keys (e.g. cost-center) to add to the disks of its PVCs and the load balancers of its Services")
+	syncCmd.Flags().StringVar(&journalPath, "journal", "",
+		"Append the planned, applied and failed resources to this file (defaults to sync-<time>.jsonl)")
+	syncCmd.Flags().StringVar(&resumePath, "resume", "",
+		"Continue the sync recorded in this journal with the resources it did not apply")
+	syncCmd.Flags().BoolVar(&prune, "prune", false,
+		"Remove the tags earlier syncs added that are no longer desired (AWS, Azure and GCP); other tags are kept")
+	syncCmd.Flags().StringVar(&snapshotPath, "snapshot", "",
+		"Save the tags of the resources before the sync to this file for rollback (defaults to sync-<time>.snapshot.json)")
 
 	RootCmd.AddCommand(syncCmd)
 }
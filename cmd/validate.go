@@ -2,13 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/gcp"
 	"log"
 	"strings"
 
-	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/aws"
-	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/azure"
-	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/manager"
 	"github.com/spf13/cobra"
 )
 
@@ -35,14 +33,16 @@ Checks if specified tags exist and have correct values on all resources.`,
 		}
 
 		k8sClient := getK8sClient()
-		cloudPlatform, err := getCloudPlatform(k8sClient)
+		cloudPlatform, err := getCloudPlatform(cmd.Context(), k8sClient)
 		if err != nil {
 			log.Fatalf("Error determining cloud platform: %v", err)
 		}
 
 		// Allow platform override
 		if platform != "" {
-			cloudPlatform = infraType.CloudPlatform(platform)
+			if cloudPlatform, err = cluster.ParsePlatform(platform); err != nil {
+				fatalError(err, "Invalid --platform")
+			}
 		}
 		parsedTags, err := parseTags(validateTags)
 		if err != nil {
@@ -52,19 +52,8 @@ Checks if specified tags exist and have correct values on all resources.`,
 		fmt.Printf("Validating tags on %s platform\n", cloudPlatform)
 		fmt.Printf("Tags to validate: %v\n", validateTags)
 
-		switch cloudPlatform {
-		case infraType.CloudPlatformAWS:
-			err = aws.IsValidAWSTag(parsedTags)
-		case infraType.CloudPlatformAzure:
-			err = azure.IsValidAzureTag(parsedTags)
-		case infraType.CloudPlatformGCP:
-			err = gcp.IsValidGCPTag(parsedTags)
-		default:
-			log.Fatalf("Unsupported platform for validation: %s", cloudPlatform)
-		}
-
-		if err != nil {
-			log.Fatalf("Validation failed: %v", err)
+		if err := manager.ValidateTags(cloudPlatform, parsedTags, gcpTagBindings); err != nil {
+			fatalError(err, "Validation failed")
 		}
 
 		fmt.Println("✅ All specified valid")
@@ -74,6 +63,8 @@ Checks if specified tags exist and have correct values on all resources.`,
 func init() {
 	validateCmd.Flags().StringSliceVarP(&validateTags, "tags", "t", []string{},
 		"Comma-separated list of tags to validate")
+	validateCmd.Flags().BoolVar(&gcpTagBindings, "gcp-tag-bindings", false,
+		"On GCP, validate Resource Manager tags instead of labels")
 	RootCmd.AddCommand(validateCmd)
 }
 
@@ -3,16 +3,12 @@ package aws
 import (
 	"context"
 	"fmt"
-	"log"
 
 	configv1 "github.com/openshift/api/config/v1"
-	"k8s.io/client-go/kubernetes/scheme"
-	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
@@ -21,56 +17,57 @@ import (
 	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/go-logr/logr"
 )
 
-var ClusterTagKey = "kubernetes.io/cluster/%s"
+// ClusterTagKey is the format of the tag the installer puts on the
+// cluster's resources, with the infraID as argument.
+const ClusterTagKey = "kubernetes.io/cluster/%s"
 
 const ClusterTagValue = "owned"
 
-func ListAWSResources() ([]infraType.CloudResource, error) {
-	fmt.Printf("Listing AWS resources\n")
-	ctx := context.TODO()
+func ListAWSResources(ctx context.Context, k8sClient client.Client) ([]infraType.CloudResource, error) {
+	logr.FromContextOrDiscard(ctx).Info("Listing AWS resources")
 	var resources []infraType.CloudResource
 
-	k8sClient := getK8sClient()
-	clusterName, err := getClusterNameFromInfrastructure(k8sClient)
+	clusterName, err := getClusterNameFromInfrastructure(ctx, k8sClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cluster name: %w", err)
 	}
-	ClusterTagKey = fmt.Sprintf(ClusterTagKey, clusterName)
+	clusterTag := fmt.Sprintf(ClusterTagKey, clusterName)
 
-	awscfg, err := config.LoadDefaultConfig(ctx)
+	awscfg, err := loadConfig(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	// Collect all resources
-	if ec2Res, err := listEC2Instances(ctx, awscfg); err == nil {
+	if ec2Res, err := listEC2Instances(ctx, awscfg, clusterTag); err == nil {
 		resources = append(resources, ec2Res...)
 	}
-	if s3Res, err := listS3Buckets(ctx, awscfg); err == nil {
+	if s3Res, err := listS3Buckets(ctx, awscfg, clusterTag); err == nil {
 		resources = append(resources, s3Res...)
 	}
-	if ebsRes, err := listEBSVolumes(ctx, awscfg); err == nil {
+	if ebsRes, err := listEBSVolumes(ctx, awscfg, clusterTag); err == nil {
 		resources = append(resources, ebsRes...)
 	}
-	if lbRes, err := listLoadBalancers(ctx, awscfg); err == nil {
+	if lbRes, err := listLoadBalancers(ctx, awscfg, clusterTag); err == nil {
 		resources = append(resources, lbRes...)
 	}
-	if iamRes, err := listIAMRoles(ctx, awscfg); err == nil {
+	if iamRes, err := listIAMRoles(ctx, awscfg, clusterTag); err == nil {
 		resources = append(resources, iamRes...)
 	}
-	if vpcRes, err := listVPCs(ctx, awscfg); err == nil {
+	if vpcRes, err := listVPCs(ctx, awscfg, clusterTag); err == nil {
 		resources = append(resources, vpcRes...)
 	}
-	if subnetRes, err := listSubnets(ctx, awscfg); err == nil {
+	if subnetRes, err := listSubnets(ctx, awscfg, clusterTag); err == nil {
 		resources = append(resources, subnetRes...)
 	}
 
 	return resources, nil
 }
 
-func listEC2Instances(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource, error) {
+func listEC2Instances(ctx context.Context, cfg aws.Config, clusterTag string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 	client := ec2.NewFromConfig(cfg)
 
@@ -81,7 +78,7 @@ func listEC2Instances(ctx context.Context, cfg aws.Config) ([]infraType.CloudRes
 
 	for _, reservation := range result.Reservations {
 		for _, instance := range reservation.Instances {
-			if hasTag(instance.Tags, ClusterTagKey, ClusterTagValue) {
+			if hasTag(instance.Tags, clusterTag, ClusterTagValue) {
 				resources = append(resources, infraType.CloudResource{
 					CloudProvider: infraType.CloudPlatformAWS,
 					Type:          infraType.CloudResourceTypeAWSEC2Instance,
@@ -95,7 +92,7 @@ func listEC2Instances(ctx context.Context, cfg aws.Config) ([]infraType.CloudRes
 	return resources, nil
 }
 
-func listS3Buckets(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource, error) {
+func listS3Buckets(ctx context.Context, cfg aws.Config, clusterTag string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 	client := s3.NewFromConfig(cfg)
 
@@ -112,7 +109,7 @@ func listS3Buckets(ctx context.Context, cfg aws.Config) ([]infraType.CloudResour
 			continue // Skip buckets without tags or access issues
 		}
 
-		if hasS3Tag(tagResult.TagSet, ClusterTagKey, ClusterTagValue) {
+		if hasS3Tag(tagResult.TagSet, clusterTag, ClusterTagValue) {
 			resources = append(resources, infraType.CloudResource{
 				CloudProvider: infraType.CloudPlatformAWS,
 				Type:          infraType.CloudResourceTypeAWSS3Bucket,
@@ -125,7 +122,7 @@ func listS3Buckets(ctx context.Context, cfg aws.Config) ([]infraType.CloudResour
 	return resources, nil
 }
 
-func listEBSVolumes(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource, error) {
+func listEBSVolumes(ctx context.Context, cfg aws.Config, clusterTag string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 	client := ec2.NewFromConfig(cfg)
 
@@ -135,7 +132,7 @@ func listEBSVolumes(ctx context.Context, cfg aws.Config) ([]infraType.CloudResou
 	}
 
 	for _, volume := range result.Volumes {
-		if hasTag(volume.Tags, ClusterTagKey, ClusterTagValue) {
+		if hasTag(volume.Tags, clusterTag, ClusterTagValue) {
 			resources = append(resources, infraType.CloudResource{
 				CloudProvider: infraType.CloudPlatformAWS,
 				Type:          infraType.CloudResourceTypeAWSEBSVolume,
@@ -148,7 +145,7 @@ func listEBSVolumes(ctx context.Context, cfg aws.Config) ([]infraType.CloudResou
 	return resources, nil
 }
 
-func listIAMRoles(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource, error) {
+func listIAMRoles(ctx context.Context, cfg aws.Config, clusterTag string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 	client := iam.NewFromConfig(cfg)
 
@@ -165,7 +162,7 @@ func listIAMRoles(ctx context.Context, cfg aws.Config) ([]infraType.CloudResourc
 			continue
 		}
 
-		if hasIAMTag(tagResult.Tags, ClusterTagKey, ClusterTagValue) {
+		if hasIAMTag(tagResult.Tags, clusterTag, ClusterTagValue) {
 			resources = append(resources, infraType.CloudResource{
 				CloudProvider: infraType.CloudPlatformAWS,
 				Type:          infraType.CloudResourceTypeAWSIAMRole,
@@ -178,7 +175,7 @@ func listIAMRoles(ctx context.Context, cfg aws.Config) ([]infraType.CloudResourc
 	return resources, nil
 }
 
-func listVPCs(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource, error) {
+func listVPCs(ctx context.Context, cfg aws.Config, clusterTag string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 	client := ec2.NewFromConfig(cfg)
 
@@ -188,7 +185,7 @@ func listVPCs(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource, e
 	}
 
 	for _, vpc := range result.Vpcs {
-		if hasTag(vpc.Tags, ClusterTagKey, ClusterTagValue) {
+		if hasTag(vpc.Tags, clusterTag, ClusterTagValue) {
 			resources = append(resources, infraType.CloudResource{
 				CloudProvider: infraType.CloudPlatformAWS,
 				Type:          infraType.CloudResourceTypeAWSVPC,
@@ -201,7 +198,7 @@ func listVPCs(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource, e
 	return resources, nil
 }
 
-func listSubnets(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource, error) {
+func listSubnets(ctx context.Context, cfg aws.Config, clusterTag string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 	client := ec2.NewFromConfig(cfg)
 
@@ -211,7 +208,7 @@ func listSubnets(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource
 	}
 
 	for _, subnet := range result.Subnets {
-		if hasTag(subnet.Tags, ClusterTagKey, ClusterTagValue) {
+		if hasTag(subnet.Tags, clusterTag, ClusterTagValue) {
 			resources = append(resources, infraType.CloudResource{
 				CloudProvider: infraType.CloudPlatformAWS,
 				Type:          infraType.CloudResourceTypeAWSSubnet,
@@ -224,7 +221,7 @@ func listSubnets(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource
 	return resources, nil
 }
 
-func listLoadBalancers(ctx context.Context, cfg aws.Config) ([]infraType.CloudResource, error) {
+func listLoadBalancers(ctx context.Context, cfg aws.Config, clusterTag string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 	client := elasticloadbalancingv2.NewFromConfig(cfg)
 
@@ -242,7 +239,7 @@ func listLoadBalancers(ctx context.Context, cfg aws.Config) ([]infraType.CloudRe
 		}
 
 		for _, tagDesc := range tagResult.TagDescriptions {
-			if hasELBv2Tag(tagDesc.Tags, ClusterTagKey, ClusterTagValue) {
+			if hasELBv2Tag(tagDesc.Tags, clusterTag, ClusterTagValue) {
 				resources = append(resources, infraType.CloudResource{
 					CloudProvider: infraType.CloudPlatformAWS,
 					Type:          infraType.CloudResourceTypeAWSLoadBalancer,
@@ -334,45 +331,27 @@ func convertELBv2Tags(tags []elbv2Types.Tag) map[string]string {
 	return result
 }
 
-// Kubernetes client functions (keep existing implementations)
-func getK8sClient() client.Client {
-	cfg, err := ctrl.GetConfig()
-	if err != nil {
-		log.Fatalf("Error getting Kubernetes config: %v", err)
-	}
-
-	configv1.AddToScheme(scheme.Scheme)
-	k8sClient, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
-	if err != nil {
-		log.Fatalf("Error creating Kubernetes client: %v", err)
-	}
-	return k8sClient
-}
-
-func getClusterNameFromInfrastructure(k8sClient client.Client) (string, error) {
+func getClusterNameFromInfrastructure(ctx context.Context, k8sClient client.Client) (string, error) {
 	infra := &configv1.Infrastructure{}
-	if err := k8sClient.Get(context.Background(), client.ObjectKey{Name: "cluster"}, infra); err != nil {
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "cluster"}, infra); err != nil {
 		return "", fmt.Errorf("failed to get Infrastructure resource: %w", err)
 	}
 	return infra.Status.InfrastructureName, nil
 }
 
-func UpdateResourceTags(resources []infraType.CloudResource, tags map[string]string) error {
-	ctx := context.TODO()
-	cfg, err := config.LoadDefaultConfig(ctx)
+func UpdateResourceTags(ctx context.Context, resources []infraType.CloudResource, tags map[string]string) error {
+	cfg, err := loadConfig(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	var errs []error
-
 	// Initialize clients
 	ec2Client := ec2.NewFromConfig(cfg)
 	s3Client := s3.NewFromConfig(cfg)
 	iamClient := iam.NewFromConfig(cfg)
 	elbClient := elasticloadbalancingv2.NewFromConfig(cfg)
 
-	for _, resource := range resources {
+	return infraType.ForEachResource(ctx, resources, "update", func(ctx context.Context, resource infraType.CloudResource) error {
 		var err error
 		switch resource.Type {
 		case infraType.CloudResourceTypeAWSS3Bucket:
@@ -390,16 +369,8 @@ func UpdateResourceTags(resources []infraType.CloudResource, tags map[string]str
 			err = fmt.Errorf("unsupported resource type: %s", resource.Type)
 		}
 
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to update %s (%s): %w",
-				resource.ID, resource.Type, err))
-		}
-	}
-
-	if len(errs) > 0 {
-		return fmt.Errorf("encountered %d errors: %v", len(errs), errs)
-	}
-	return nil
+		return err
+	})
 }
 
 func updateS3Tags(ctx context.Context, client *s3.Client, resource infraType.CloudResource, tags map[string]string) error {
@@ -496,3 +467,78 @@ func convertToELBv2Tags(tags map[string]string) []elbv2Types.Tag {
 	}
 	return elbTags
 }
+
+// RemoveResourceTags deletes the given tag keys from the resources. Keys a
+// resource does not carry are ignored.
+func RemoveResourceTags(ctx context.Context, resources []infraType.CloudResource, keys []string) error {
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	ec2Client := ec2.NewFromConfig(cfg)
+	s3Client := s3.NewFromConfig(cfg)
+	iamClient := iam.NewFromConfig(cfg)
+	elbClient := elasticloadbalancingv2.NewFromConfig(cfg)
+
+	return infraType.ForEachResource(ctx, resources, "remove tags from", func(ctx context.Context, resource infraType.CloudResource) error {
+		var err error
+		switch resource.Type {
+		case infraType.CloudResourceTypeAWSS3Bucket:
+			err = removeS3Tags(ctx, s3Client, resource, keys)
+		case infraType.CloudResourceTypeAWSEC2Instance,
+			infraType.CloudResourceTypeAWSEBSVolume,
+			infraType.CloudResourceTypeAWSVPC,
+			infraType.CloudResourceTypeAWSSubnet:
+			var ec2Tags []types.Tag
+			for _, k := range keys {
+				ec2Tags = append(ec2Tags, types.Tag{Key: aws.String(k)})
+			}
+			_, err = ec2Client.DeleteTags(ctx, &ec2.DeleteTagsInput{
+				Resources: []string{resource.ID},
+				Tags:      ec2Tags,
+			})
+		case infraType.CloudResourceTypeAWSIAMRole:
+			_, err = iamClient.UntagRole(ctx, &iam.UntagRoleInput{
+				RoleName: aws.String(resource.Name),
+				TagKeys:  keys,
+			})
+		case infraType.CloudResourceTypeAWSLoadBalancer:
+			_, err = elbClient.RemoveTags(ctx, &elasticloadbalancingv2.RemoveTagsInput{
+				ResourceArns: []string{resource.ID},
+				TagKeys:      keys,
+			})
+		default:
+			err = fmt.Errorf("unsupported resource type: %s", resource.Type)
+		}
+
+		return err
+	})
+}
+
+// removeS3Tags rewrites the bucket tag set without the keys; S3 has no call
+// to delete single tags.
+func removeS3Tags(ctx context.Context, client *s3.Client, resource infraType.CloudResource, keys []string) error {
+	remaining := make(map[string]string)
+	for k, v := range resource.Tags {
+		remaining[k] = v
+	}
+	for _, k := range keys {
+		delete(remaining, k)
+	}
+
+	if len(remaining) == 0 {
+		_, err := client.DeleteBucketTagging(ctx, &s3.DeleteBucketTaggingInput{
+			Bucket: aws.String(resource.ID),
+		})
+		return err
+	}
+
+	_, err := client.PutBucketTagging(ctx, &s3.PutBucketTaggingInput{
+		Bucket: aws.String(resource.ID),
+		Tagging: &s3Types.Tagging{
+			TagSet: convertToS3Tags(remaining),
+		},
+	})
+	return err
+}
@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+type (
+	configKey struct{}
+	regionKey struct{}
+)
+
+// WithConfig returns a context that makes the package use cfg instead of
+// the SDK's default configuration, e.g. to act on several accounts in one
+// process.
+func WithConfig(ctx context.Context, cfg aws.Config) context.Context {
+	return context.WithValue(ctx, configKey{}, cfg)
+}
+
+// WithRegion returns a context that makes the package use region when the
+// configuration does not set one, e.g. the region of an offline cluster.
+func WithRegion(ctx context.Context, region string) context.Context {
+	return context.WithValue(ctx, regionKey{}, region)
+}
+
+// loadConfig returns the configuration set with WithConfig, or the default
+// one from the environment and shared config files. The default credentials
+// are retrieved right away, so that missing ones fail with ErrCredentials
+// instead of on the first API call.
+func loadConfig(ctx context.Context) (aws.Config, error) {
+	region, _ := ctx.Value(regionKey{}).(string)
+	if cfg, ok := ctx.Value(configKey{}).(aws.Config); ok {
+		if cfg.Region == "" {
+			cfg.Region = region
+		}
+		return cfg, nil
+	}
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return cfg, fmt.Errorf("%w: %w", infraType.ErrCredentials, err)
+	}
+	if cfg.Region == "" {
+		cfg.Region = region
+	}
+	if cfg.Credentials != nil {
+		if _, err := cfg.Credentials.Retrieve(ctx); err != nil {
+			return cfg, fmt.Errorf("%w: %w", infraType.ErrCredentials, err)
+		}
+	}
+	return cfg, nil
+}
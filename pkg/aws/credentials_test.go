@@ -0,0 +1,71 @@
+package aws
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+func TestLoadConfigRegion(t *testing.T) {
+	// The default configuration comes from static credentials in the
+	// environment and no shared config files.
+	missing := filepath.Join(t.TempDir(), "missing")
+	t.Setenv("AWS_CONFIG_FILE", missing)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", missing)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+
+	tests := []struct {
+		name      string
+		envRegion string
+		ctx       func(ctx context.Context) context.Context
+		region    string
+	}{
+		{
+			name:      "default configuration",
+			envRegion: "us-east-1",
+			ctx:       func(ctx context.Context) context.Context { return ctx },
+			region:    "us-east-1",
+		},
+		{
+			name:      "environment region is kept",
+			envRegion: "us-east-1",
+			ctx:       func(ctx context.Context) context.Context { return WithRegion(ctx, "eu-west-1") },
+			region:    "us-east-1",
+		},
+		{
+			name:   "region fills in the default configuration without one",
+			ctx:    func(ctx context.Context) context.Context { return WithRegion(ctx, "eu-west-1") },
+			region: "eu-west-1",
+		},
+		{
+			name: "region fills in a configuration without one",
+			ctx: func(ctx context.Context) context.Context {
+				return WithRegion(WithConfig(ctx, aws.Config{}), "eu-west-1")
+			},
+			region: "eu-west-1",
+		},
+		{
+			name: "configuration region is kept",
+			ctx: func(ctx context.Context) context.Context {
+				return WithRegion(WithConfig(ctx, aws.Config{Region: "ap-south-1"}), "eu-west-1")
+			},
+			region: "ap-south-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("AWS_REGION", tt.envRegion)
+			cfg, err := loadConfig(tt.ctx(context.Background()))
+			if err != nil {
+				t.Fatalf("loadConfig() error = %v", err)
+			}
+			if cfg.Region != tt.region {
+				t.Errorf("region = %q, want %q", cfg.Region, tt.region)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// DescribeResource returns the resource with its current tags. It covers
+// the types that back Kubernetes objects: EC2 instances, EBS volumes and
+// load balancers.
+func DescribeResource(ctx context.Context, resource infraType.CloudResource) (infraType.CloudResource, error) {
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return resource, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	resource.CloudProvider = infraType.CloudPlatformAWS
+	switch resource.Type {
+	case infraType.CloudResourceTypeAWSEC2Instance,
+		infraType.CloudResourceTypeAWSEBSVolume:
+		client := ec2.NewFromConfig(cfg)
+		paginator := ec2.NewDescribeTagsPaginator(client, &ec2.DescribeTagsInput{
+			Filters: []types.Filter{{Name: aws.String("resource-id"), Values: []string{resource.ID}}},
+		})
+
+		resource.Tags = make(map[string]string)
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return resource, fmt.Errorf("error describing tags of %s: %w", resource.ID, err)
+			}
+			for _, tag := range page.Tags {
+				resource.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+		}
+		if resource.Name == "" {
+			resource.Name = resource.Tags["Name"]
+		}
+	case infraType.CloudResourceTypeAWSLoadBalancer:
+		client := elasticloadbalancingv2.NewFromConfig(cfg)
+		result, err := client.DescribeTags(ctx, &elasticloadbalancingv2.DescribeTagsInput{
+			ResourceArns: []string{resource.ID},
+		})
+		if err != nil {
+			return resource, fmt.Errorf("error describing tags of %s: %w", resource.ID, err)
+		}
+		resource.Tags = make(map[string]string)
+		for _, tagDesc := range result.TagDescriptions {
+			for k, v := range convertELBv2Tags(tagDesc.Tags) {
+				resource.Tags[k] = v
+			}
+		}
+	default:
+		return resource, fmt.Errorf("unsupported resource type: %s", resource.Type)
+	}
+	return resource, nil
+}
+
+// FindLoadBalancer returns the load balancer with the given DNS name, as
+// reported in a Service's status, or nil when there is none. Only ALBs and
+// NLBs are found, like in ListAWSResources.
+func FindLoadBalancer(ctx context.Context, dnsName string) (*infraType.CloudResource, error) {
+	cfg, err := loadConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	client := elasticloadbalancingv2.NewFromConfig(cfg)
+	paginator := elasticloadbalancingv2.NewDescribeLoadBalancersPaginator(client,
+		&elasticloadbalancingv2.DescribeLoadBalancersInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error describing load balancers: %w", err)
+		}
+
+		for _, lb := range page.LoadBalancers {
+			if !strings.EqualFold(aws.ToString(lb.DNSName), dnsName) {
+				continue
+			}
+			resource, err := DescribeResource(ctx, infraType.CloudResource{
+				Type: infraType.CloudResourceTypeAWSLoadBalancer,
+				ID:   aws.ToString(lb.LoadBalancerArn),
+				Name: aws.ToString(lb.LoadBalancerName),
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &resource, nil
+		}
+	}
+	return nil, nil
+}
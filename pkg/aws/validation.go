@@ -1,8 +1,9 @@
 package aws
 
 import (
-	"errors"
 	"fmt"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
 )
 
 const (
@@ -14,7 +15,7 @@ const (
 // isValidAWSTag validates AWS tags
 func IsValidAWSTag(tags map[string]string) error {
 	if len(tags) > awsMaxTags {
-		return errors.New("AWS allows a maximum of 50 tags per resource")
+		return fmt.Errorf("%w: AWS allows a maximum of %d tags per resource", infraType.ErrTagLimitExceeded, awsMaxTags)
 	}
 	for key, value := range tags {
 		if len(key) == 0 || len(key) > awsKeyMaxLength {
@@ -7,44 +7,33 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-
-	"log"
-	"os"
-	_ "strings"
 	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
-	"k8s.io/client-go/kubernetes/scheme"
-	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
-	//"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
-	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
-	//"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
 
 	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
 )
 
-var (
+const (
 	ClusterTagKey   = "kubernetes.io/cluster/%s"
 	ClusterTagValue = "owned"
 )
 
-func ListAzureResources() ([]infraType.CloudResource, error) {
-	ctx := context.TODO()
+func ListAzureResources(ctx context.Context, k8sClient client.Client) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 
-	k8sClient := getK8sClient()
-	resourceGroup, clusterName, err := getClusterResourceGroup(k8sClient)
+	resourceGroup, _, err := getClusterResourceGroup(ctx, k8sClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get cluster info: %w", err)
 	}
-	ClusterTagKey = fmt.Sprintf(ClusterTagKey, clusterName)
 
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	cred, err := getCredential(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("azure authentication failed: %w", err)
 	}
@@ -74,13 +63,36 @@ func ListAzureResources() ([]infraType.CloudResource, error) {
 	if nsgRes, err := listNetworkSecurityGroups(ctx, cred, resourceGroup); err == nil {
 		resources = append(resources, nsgRes...)
 	}
+	if nicRes, err := listNetworkInterfaces(ctx, cred, resourceGroup); err == nil {
+		resources = append(resources, nicRes...)
+	}
+	if rtRes, err := listRouteTables(ctx, cred, resourceGroup); err == nil {
+		resources = append(resources, rtRes...)
+	}
+	if peRes, err := listPrivateEndpoints(ctx, cred, resourceGroup); err == nil {
+		resources = append(resources, peRes...)
+	}
+	if asRes, err := listAvailabilitySets(ctx, cred, resourceGroup); err == nil {
+		resources = append(resources, asRes...)
+	}
+	if galleryRes, err := listGalleries(ctx, cred, resourceGroup); err == nil {
+		resources = append(resources, galleryRes...)
+	}
+	for _, generic := range genericResourceTypes {
+		if genRes, err := listGenericResources(ctx, cred, resourceGroup, generic.armType, generic.cloudType); err == nil {
+			resources = append(resources, genRes...)
+		}
+	}
+	if rgRes, err := getResourceGroup(ctx, cred, resourceGroup); err == nil {
+		resources = append(resources, rgRes...)
+	}
 
 	return resources, nil
 }
 
-func listVirtualMachines(ctx context.Context, cred *azidentity.DefaultAzureCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+func listVirtualMachines(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
-	client, err := armcompute.NewVirtualMachinesClient(os.Getenv("AZURE_SUBSCRIPTION_ID"), cred, nil)
+	client, err := armcompute.NewVirtualMachinesClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -105,9 +117,9 @@ func listVirtualMachines(ctx context.Context, cred *azidentity.DefaultAzureCrede
 	return resources, nil
 }
 
-func listDisks(ctx context.Context, cred *azidentity.DefaultAzureCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+func listDisks(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
-	client, err := armcompute.NewDisksClient(os.Getenv("AZURE_SUBSCRIPTION_ID"), cred, nil)
+	client, err := armcompute.NewDisksClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -132,9 +144,9 @@ func listDisks(ctx context.Context, cred *azidentity.DefaultAzureCredential, res
 	return resources, nil
 }
 
-func listVirtualNetworks(ctx context.Context, cred *azidentity.DefaultAzureCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+func listVirtualNetworks(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
-	client, err := armnetwork.NewVirtualNetworksClient(os.Getenv("AZURE_SUBSCRIPTION_ID"), cred, nil)
+	client, err := armnetwork.NewVirtualNetworksClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -159,9 +171,9 @@ func listVirtualNetworks(ctx context.Context, cred *azidentity.DefaultAzureCrede
 	return resources, nil
 }
 
-func listLoadBalancers(ctx context.Context, cred *azidentity.DefaultAzureCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+func listLoadBalancers(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
-	client, err := armnetwork.NewLoadBalancersClient(os.Getenv("AZURE_SUBSCRIPTION_ID"), cred, nil)
+	client, err := armnetwork.NewLoadBalancersClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -186,9 +198,9 @@ func listLoadBalancers(ctx context.Context, cred *azidentity.DefaultAzureCredent
 	return resources, nil
 }
 
-func listPublicIPs(ctx context.Context, cred *azidentity.DefaultAzureCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+func listPublicIPs(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
-	client, err := armnetwork.NewPublicIPAddressesClient(os.Getenv("AZURE_SUBSCRIPTION_ID"), cred, nil)
+	client, err := armnetwork.NewPublicIPAddressesClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -213,9 +225,9 @@ func listPublicIPs(ctx context.Context, cred *azidentity.DefaultAzureCredential,
 	return resources, nil
 }
 
-func listStorageAccounts(ctx context.Context, cred *azidentity.DefaultAzureCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+func listStorageAccounts(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
-	client, err := armstorage.NewAccountsClient(os.Getenv("AZURE_SUBSCRIPTION_ID"), cred, nil)
+	client, err := armstorage.NewAccountsClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -240,14 +252,14 @@ func listStorageAccounts(ctx context.Context, cred *azidentity.DefaultAzureCrede
 	return resources, nil
 }
 
-func listSubnets(ctx context.Context, cred *azidentity.DefaultAzureCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+func listSubnets(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
-	client, err := armnetwork.NewSubnetsClient(os.Getenv("AZURE_SUBSCRIPTION_ID"), cred, nil)
+	client, err := armnetwork.NewSubnetsClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
 
-	vnetClient, _ := armnetwork.NewVirtualNetworksClient(os.Getenv("AZURE_SUBSCRIPTION_ID"), cred, nil)
+	vnetClient, _ := armnetwork.NewVirtualNetworksClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	vnetPager := vnetClient.NewListPager(resourceGroup, nil)
 
 	for vnetPager.More() {
@@ -270,7 +282,9 @@ func listSubnets(ctx context.Context, cred *azidentity.DefaultAzureCredential, r
 						Type:          infraType.CloudResourceTypeAzureSubnet,
 						ID:            *subnet.ID,
 						Name:          *subnet.Name,
-						//Tags:          convertTags(subnet.Etag),
+						// Subnets are not ARM resources of their own and
+						// carry no tags.
+						NotTaggable: true,
 					})
 				}
 			}
@@ -279,9 +293,9 @@ func listSubnets(ctx context.Context, cred *azidentity.DefaultAzureCredential, r
 	return resources, nil
 }
 
-func listNetworkSecurityGroups(ctx context.Context, cred *azidentity.DefaultAzureCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+func listNetworkSecurityGroups(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
-	client, err := armnetwork.NewSecurityGroupsClient(os.Getenv("AZURE_SUBSCRIPTION_ID"), cred, nil)
+	client, err := armnetwork.NewSecurityGroupsClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	if err != nil {
 		return nil, err
 	}
@@ -306,33 +320,241 @@ func listNetworkSecurityGroups(ctx context.Context, cred *azidentity.DefaultAzur
 	return resources, nil
 }
 
-func convertTags(azureTags map[string]*string) map[string]string {
-	tags := make(map[string]string)
-	for k, v := range azureTags {
-		if v != nil {
-			tags[k] = *v
+func listNetworkInterfaces(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+	client, err := armnetwork.NewInterfacesClient(subscriptionID(ctx), cred, clientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	pager := client.NewListPager(resourceGroup, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing NICs: %w", err)
+		}
+
+		for _, nic := range page.Value {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformAzure,
+				Type:          infraType.CloudResourceTypeAzureNetworkInterface,
+				ID:            *nic.ID,
+				Name:          *nic.Name,
+				Tags:          convertTags(nic.Tags),
+			})
 		}
 	}
-	return tags
+	return resources, nil
 }
 
-func getK8sClient() client.Client {
-	cfg, err := ctrl.GetConfig()
+func listRouteTables(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+	client, err := armnetwork.NewRouteTablesClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	if err != nil {
-		log.Fatalf("Error getting Kubernetes config: %v", err)
+		return nil, err
 	}
 
-	configv1.AddToScheme(scheme.Scheme)
-	k8sClient, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
+	pager := client.NewListPager(resourceGroup, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing route tables: %w", err)
+		}
+
+		for _, rt := range page.Value {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformAzure,
+				Type:          infraType.CloudResourceTypeAzureRouteTable,
+				ID:            *rt.ID,
+				Name:          *rt.Name,
+				Tags:          convertTags(rt.Tags),
+			})
+		}
+	}
+	return resources, nil
+}
+
+func listPrivateEndpoints(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+	client, err := armnetwork.NewPrivateEndpointsClient(subscriptionID(ctx), cred, clientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	pager := client.NewListPager(resourceGroup, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing private endpoints: %w", err)
+		}
+
+		for _, pe := range page.Value {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformAzure,
+				Type:          infraType.CloudResourceTypeAzurePrivateEndpoint,
+				ID:            *pe.ID,
+				Name:          *pe.Name,
+				Tags:          convertTags(pe.Tags),
+			})
+		}
+	}
+	return resources, nil
+}
+
+func listAvailabilitySets(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+	client, err := armcompute.NewAvailabilitySetsClient(subscriptionID(ctx), cred, clientOptions(ctx))
 	if err != nil {
-		log.Fatalf("Error creating Kubernetes client: %v", err)
+		return nil, err
+	}
+
+	pager := client.NewListPager(resourceGroup, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing availability sets: %w", err)
+		}
+
+		for _, as := range page.Value {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformAzure,
+				Type:          infraType.CloudResourceTypeAzureAvailabilitySet,
+				ID:            *as.ID,
+				Name:          *as.Name,
+				Tags:          convertTags(as.Tags),
+			})
+		}
+	}
+	return resources, nil
+}
+
+// listGalleries returns the cluster's Shared Image Galleries together with
+// the image definitions the installer publishes into them.
+func listGalleries(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+	client, err := armcompute.NewGalleriesClient(subscriptionID(ctx), cred, clientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	imageClient, err := armcompute.NewGalleryImagesClient(subscriptionID(ctx), cred, clientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	pager := client.NewListByResourceGroupPager(resourceGroup, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing galleries: %w", err)
+		}
+
+		for _, gallery := range page.Value {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformAzure,
+				Type:          infraType.CloudResourceTypeAzureImageGallery,
+				ID:            *gallery.ID,
+				Name:          *gallery.Name,
+				Tags:          convertTags(gallery.Tags),
+			})
+
+			imagePager := imageClient.NewListByGalleryPager(resourceGroup, *gallery.Name, nil)
+			for imagePager.More() {
+				imagePage, err := imagePager.NextPage(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("error listing gallery images: %w", err)
+				}
+
+				for _, image := range imagePage.Value {
+					resources = append(resources, infraType.CloudResource{
+						CloudProvider: infraType.CloudPlatformAzure,
+						Type:          infraType.CloudResourceTypeAzureGalleryImage,
+						ID:            *image.ID,
+						Name:          *image.Name,
+						Tags:          convertTags(image.Tags),
+					})
+				}
+			}
+		}
+	}
+	return resources, nil
+}
+
+// genericResourceTypes lists ARM resource types that have no client in the
+// SDK modules we depend on. They are discovered through the generic
+// resources API and tagged at scope.
+var genericResourceTypes = []struct {
+	armType   string
+	cloudType infraType.CloudResourceType
+}{
+	{"Microsoft.Network/privateDnsZones", infraType.CloudResourceTypeAzurePrivateDNSZone},
+	{"Microsoft.Network/privateDnsZones/virtualNetworkLinks", infraType.CloudResourceTypeAzurePrivateDNSZoneVNetLink},
+	{"Microsoft.ManagedIdentity/userAssignedIdentities", infraType.CloudResourceTypeAzureManagedIdentity},
+}
+
+func listGenericResources(ctx context.Context, cred azcore.TokenCredential, resourceGroup string,
+	resourceType string, cloudType infraType.CloudResourceType) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+	client, err := armresources.NewClient(subscriptionID(ctx), cred, clientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	pager := client.NewListByResourceGroupPager(resourceGroup, &armresources.ClientListByResourceGroupOptions{
+		Filter: to.Ptr(fmt.Sprintf("resourceType eq '%s'", resourceType)),
+	})
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s: %w", resourceType, err)
+		}
+
+		for _, res := range page.Value {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformAzure,
+				Type:          cloudType,
+				ID:            *res.ID,
+				Name:          *res.Name,
+				Tags:          convertTags(res.Tags),
+			})
+		}
+	}
+	return resources, nil
+}
+
+func getResourceGroup(ctx context.Context, cred azcore.TokenCredential, resourceGroup string) ([]infraType.CloudResource, error) {
+	client, err := armresources.NewResourceGroupsClient(subscriptionID(ctx), cred, clientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	rg, err := client.Get(ctx, resourceGroup, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error getting resource group: %w", err)
+	}
+
+	return []infraType.CloudResource{{
+		CloudProvider: infraType.CloudPlatformAzure,
+		Type:          infraType.CloudResourceTypeAzureResourceGroup,
+		ID:            *rg.ID,
+		Name:          *rg.Name,
+		Tags:          convertTags(rg.Tags),
+	}}, nil
+}
+
+func convertTags(azureTags map[string]*string) map[string]string {
+	tags := make(map[string]string)
+	for k, v := range azureTags {
+		if v != nil {
+			tags[k] = *v
+		}
 	}
-	return k8sClient
+	return tags
 }
 
-func getClusterResourceGroup(k8sClient client.Client) (string, string, error) {
+func getClusterResourceGroup(ctx context.Context, k8sClient client.Client) (string, string, error) {
 	infra := &configv1.Infrastructure{}
-	if err := k8sClient.Get(context.Background(),
+	if err := k8sClient.Get(ctx,
 		client.ObjectKey{Name: "cluster"}, infra); err != nil {
 		return "", "", fmt.Errorf("failed to get Infrastructure: %w", err)
 	}
@@ -344,21 +566,18 @@ func getClusterResourceGroup(k8sClient client.Client) (string, string, error) {
 	return infra.Status.PlatformStatus.Azure.ResourceGroupName, infra.Status.InfrastructureName, nil
 }
 
-func UpdateResourceTags(resources []infraType.CloudResource, tags map[string]string) error {
-	ctx := context.Background()
-	cred, err := azidentity.NewDefaultAzureCredential(nil)
+func UpdateResourceTags(ctx context.Context, resources []infraType.CloudResource, tags map[string]string) error {
+	cred, err := getCredential(ctx)
 	if err != nil {
 		return fmt.Errorf("azure authentication failed: %w", err)
 	}
 
-	subscriptionID := os.Getenv("AZURE_SUBSCRIPTION_ID")
+	subscriptionID := subscriptionID(ctx)
 	if subscriptionID == "" {
 		return fmt.Errorf("AZURE_SUBSCRIPTION_ID environment variable not set")
 	}
 
-	var errs []error
-
-	for _, resource := range resources {
+	return infraType.ForEachResource(ctx, resources, "update", func(ctx context.Context, resource infraType.CloudResource) error {
 		var err error
 		switch resource.Type {
 		case infraType.CloudResourceTypeAzureVM:
@@ -373,31 +592,37 @@ func UpdateResourceTags(resources []infraType.CloudResource, tags map[string]str
 			err = updateIPTags(ctx, cred, subscriptionID, resource, tags)
 		case infraType.CloudResourceTypeAzureStorageAccount:
 			err = updateStorageTags(ctx, cred, subscriptionID, resource, tags)
-		case infraType.CloudResourceTypeAzureSubnet:
-			err = updateSubnetTags(ctx, cred, subscriptionID, resource, tags)
 		case infraType.CloudResourceTypeAzureNetworkSecurityGroup:
 			err = updateNSGTags(ctx, cred, subscriptionID, resource, tags)
+		case infraType.CloudResourceTypeAzureNetworkInterface:
+			err = updateNICTags(ctx, cred, subscriptionID, resource, tags)
+		case infraType.CloudResourceTypeAzureRouteTable:
+			err = updateRouteTableTags(ctx, cred, subscriptionID, resource, tags)
+		case infraType.CloudResourceTypeAzureAvailabilitySet:
+			err = updateAvailabilitySetTags(ctx, cred, subscriptionID, resource, tags)
+		case infraType.CloudResourceTypeAzureImageGallery:
+			err = updateGalleryTags(ctx, cred, subscriptionID, resource, tags)
+		case infraType.CloudResourceTypeAzureGalleryImage:
+			err = updateGalleryImageTags(ctx, cred, subscriptionID, resource, tags)
+		case infraType.CloudResourceTypeAzurePrivateEndpoint,
+			infraType.CloudResourceTypeAzurePrivateDNSZone,
+			infraType.CloudResourceTypeAzurePrivateDNSZoneVNetLink,
+			infraType.CloudResourceTypeAzureManagedIdentity,
+			infraType.CloudResourceTypeAzureResourceGroup:
+			err = updateTagsAtScope(ctx, cred, subscriptionID, resource, tags)
 		default:
 			err = fmt.Errorf("unsupported resource type: %s", resource.Type)
 		}
 
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed to update %s (%s): %w",
-				resource.ID, resource.Type, err))
-		}
-	}
-
-	if len(errs) > 0 {
-		return fmt.Errorf("encountered %d errors: %v", len(errs), errs)
-	}
-	return nil
+		return err
+	})
 }
 
 // Virtual Machine Tags Update
 func updateVMTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
 	resource infraType.CloudResource, tags map[string]string) error {
 
-	client, err := armcompute.NewVirtualMachinesClient(subscriptionID, cred, nil)
+	client, err := armcompute.NewVirtualMachinesClient(subscriptionID, cred, clientOptions(ctx))
 	if err != nil {
 		return err
 	}
@@ -429,7 +654,7 @@ func updateVMTags(ctx context.Context, cred azcore.TokenCredential, subscription
 func updateDiskTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
 	resource infraType.CloudResource, tags map[string]string) error {
 
-	client, err := armcompute.NewDisksClient(subscriptionID, cred, nil)
+	client, err := armcompute.NewDisksClient(subscriptionID, cred, clientOptions(ctx))
 	if err != nil {
 		return err
 	}
@@ -461,7 +686,7 @@ func updateDiskTags(ctx context.Context, cred azcore.TokenCredential, subscripti
 func updateVNetTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
 	resource infraType.CloudResource, tags map[string]string) error {
 
-	client, err := armnetwork.NewVirtualNetworksClient(subscriptionID, cred, nil)
+	client, err := armnetwork.NewVirtualNetworksClient(subscriptionID, cred, clientOptions(ctx))
 	if err != nil {
 		return err
 	}
@@ -486,7 +711,7 @@ func updateVNetTags(ctx context.Context, cred azcore.TokenCredential, subscripti
 func updateLBTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
 	resource infraType.CloudResource, tags map[string]string) error {
 
-	client, err := armnetwork.NewLoadBalancersClient(subscriptionID, cred, nil)
+	client, err := armnetwork.NewLoadBalancersClient(subscriptionID, cred, clientOptions(ctx))
 	if err != nil {
 		return err
 	}
@@ -511,7 +736,7 @@ func updateLBTags(ctx context.Context, cred azcore.TokenCredential, subscription
 func updateIPTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
 	resource infraType.CloudResource, tags map[string]string) error {
 
-	client, err := armnetwork.NewPublicIPAddressesClient(subscriptionID, cred, nil)
+	client, err := armnetwork.NewPublicIPAddressesClient(subscriptionID, cred, clientOptions(ctx))
 	if err != nil {
 		return err
 	}
@@ -536,7 +761,7 @@ func updateIPTags(ctx context.Context, cred azcore.TokenCredential, subscription
 func updateStorageTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
 	resource infraType.CloudResource, tags map[string]string) error {
 
-	client, err := armstorage.NewAccountsClient(subscriptionID, cred, nil)
+	client, err := armstorage.NewAccountsClient(subscriptionID, cred, clientOptions(ctx))
 	if err != nil {
 		return err
 	}
@@ -557,47 +782,130 @@ func updateStorageTags(ctx context.Context, cred azcore.TokenCredential, subscri
 	return err
 }
 
-// Subnet Tags Update
-func updateSubnetTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
+// Network Security Group Tags Update
+func updateNSGTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
 	resource infraType.CloudResource, tags map[string]string) error {
 
-	client, err := armnetwork.NewSubnetsClient(subscriptionID, cred, nil)
+	client, err := armnetwork.NewSecurityGroupsClient(subscriptionID, cred, clientOptions(ctx))
 	if err != nil {
 		return err
 	}
 
 	parsedID, err := arm.ParseResourceID(resource.ID)
 	if err != nil {
-		return fmt.Errorf("failed to parse Subnet ID: %w", err)
+		return fmt.Errorf("failed to parse NSG ID: %w", err)
 	}
 
-	// Get parent VNet name from resource ID
-	vnetName := parsedID.Parent.Name
-	subnetName := parsedID.Name
+	mergedTags := mergeAzureTags(resource.Tags, tags)
 
-	// Get existing subnet configuration
-	subnet, err := client.Get(ctx,
+	_, err = client.UpdateTags(ctx,
 		parsedID.ResourceGroupName,
-		vnetName,
-		subnetName,
-		nil)
+		parsedID.Name,
+		armnetwork.TagsObject{
+			Tags: mergedTags,
+		}, nil)
+	return err
+}
+
+// Network Interface Tags Update
+func updateNICTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
+	resource infraType.CloudResource, tags map[string]string) error {
+
+	client, err := armnetwork.NewInterfacesClient(subscriptionID, cred, clientOptions(ctx))
 	if err != nil {
-		return fmt.Errorf("failed to get subnet: %w", err)
+		return err
 	}
 
-	// Merge tags
-	//mergedTags := mergeAzureTags(resource.Tags, tags)
-	//subnet.Subnet = mergedTags
+	parsedID, err := arm.ParseResourceID(resource.ID)
+	if err != nil {
+		return fmt.Errorf("failed to parse NIC ID: %w", err)
+	}
 
-	// Update subnet
-	poller, err := client.BeginCreateOrUpdate(ctx,
+	mergedTags := mergeAzureTags(resource.Tags, tags)
+
+	_, err = client.UpdateTags(ctx,
 		parsedID.ResourceGroupName,
-		vnetName,
-		subnetName,
-		subnet.Subnet,
-		nil)
+		parsedID.Name,
+		armnetwork.TagsObject{
+			Tags: mergedTags,
+		}, nil)
+	return err
+}
+
+// Route Table Tags Update
+func updateRouteTableTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
+	resource infraType.CloudResource, tags map[string]string) error {
+
+	client, err := armnetwork.NewRouteTablesClient(subscriptionID, cred, clientOptions(ctx))
+	if err != nil {
+		return err
+	}
+
+	parsedID, err := arm.ParseResourceID(resource.ID)
+	if err != nil {
+		return fmt.Errorf("failed to parse Route Table ID: %w", err)
+	}
+
+	mergedTags := mergeAzureTags(resource.Tags, tags)
+
+	_, err = client.UpdateTags(ctx,
+		parsedID.ResourceGroupName,
+		parsedID.Name,
+		armnetwork.TagsObject{
+			Tags: mergedTags,
+		}, nil)
+	return err
+}
+
+// Availability Set Tags Update
+func updateAvailabilitySetTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
+	resource infraType.CloudResource, tags map[string]string) error {
+
+	client, err := armcompute.NewAvailabilitySetsClient(subscriptionID, cred, clientOptions(ctx))
 	if err != nil {
-		return fmt.Errorf("failed to start subnet update: %w", err)
+		return err
+	}
+
+	parsedID, err := arm.ParseResourceID(resource.ID)
+	if err != nil {
+		return fmt.Errorf("failed to parse Availability Set ID: %w", err)
+	}
+
+	mergedTags := mergeAzureTags(resource.Tags, tags)
+
+	_, err = client.Update(ctx,
+		parsedID.ResourceGroupName,
+		parsedID.Name,
+		armcompute.AvailabilitySetUpdate{
+			Tags: mergedTags,
+		}, nil)
+	return err
+}
+
+// Shared Image Gallery Tags Update
+func updateGalleryTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
+	resource infraType.CloudResource, tags map[string]string) error {
+
+	client, err := armcompute.NewGalleriesClient(subscriptionID, cred, clientOptions(ctx))
+	if err != nil {
+		return err
+	}
+
+	parsedID, err := arm.ParseResourceID(resource.ID)
+	if err != nil {
+		return fmt.Errorf("failed to parse Gallery ID: %w", err)
+	}
+
+	mergedTags := mergeAzureTags(resource.Tags, tags)
+
+	poller, err := client.BeginUpdate(ctx,
+		parsedID.ResourceGroupName,
+		parsedID.Name,
+		armcompute.GalleryUpdate{
+			Tags: mergedTags,
+		}, nil)
+	if err != nil {
+		return err
 	}
 
 	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
@@ -606,28 +914,56 @@ func updateSubnetTags(ctx context.Context, cred azcore.TokenCredential, subscrip
 	return err
 }
 
-// Network Security Group Tags Update
-func updateNSGTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
+// Gallery Image Definition Tags Update
+func updateGalleryImageTags(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
 	resource infraType.CloudResource, tags map[string]string) error {
 
-	client, err := armnetwork.NewSecurityGroupsClient(subscriptionID, cred, nil)
+	client, err := armcompute.NewGalleryImagesClient(subscriptionID, cred, clientOptions(ctx))
 	if err != nil {
 		return err
 	}
 
 	parsedID, err := arm.ParseResourceID(resource.ID)
 	if err != nil {
-		return fmt.Errorf("failed to parse NSG ID: %w", err)
+		return fmt.Errorf("failed to parse Gallery Image ID: %w", err)
 	}
 
 	mergedTags := mergeAzureTags(resource.Tags, tags)
 
-	_, err = client.UpdateTags(ctx,
+	poller, err := client.BeginUpdate(ctx,
 		parsedID.ResourceGroupName,
+		parsedID.Parent.Name,
 		parsedID.Name,
-		armnetwork.TagsObject{
+		armcompute.GalleryImageUpdate{
 			Tags: mergedTags,
 		}, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = poller.PollUntilDone(ctx, &runtime.PollUntilDoneOptions{
+		Frequency: 5 * time.Second,
+	})
+	return err
+}
+
+// updateTagsAtScope merges tags through the Microsoft.Resources tags API. It
+// works for any taggable ARM resource, including resource groups, and is used
+// for types we have no dedicated client for.
+func updateTagsAtScope(ctx context.Context, cred azcore.TokenCredential, subscriptionID string,
+	resource infraType.CloudResource, tags map[string]string) error {
+
+	client, err := armresources.NewTagsClient(subscriptionID, cred, clientOptions(ctx))
+	if err != nil {
+		return err
+	}
+
+	_, err = client.UpdateAtScope(ctx, resource.ID, armresources.TagsPatchResource{
+		Operation: to.Ptr(armresources.TagsPatchOperationMerge),
+		Properties: &armresources.Tags{
+			Tags: mergeAzureTags(nil, tags),
+		},
+	}, nil)
 	return err
 }
 
@@ -647,3 +983,44 @@ func mergeAzureTags(existing map[string]string, newTags map[string]string) map[s
 
 	return merged
 }
+
+// RemoveResourceTags deletes the given tag keys from the resources through
+// the Tags API, which works at any resource scope.
+func RemoveResourceTags(ctx context.Context, resources []infraType.CloudResource, keys []string) error {
+	cred, err := getCredential(ctx)
+	if err != nil {
+		return fmt.Errorf("azure authentication failed: %w", err)
+	}
+
+	subscriptionID := subscriptionID(ctx)
+	if subscriptionID == "" {
+		return fmt.Errorf("AZURE_SUBSCRIPTION_ID environment variable not set")
+	}
+
+	client, err := armresources.NewTagsClient(subscriptionID, cred, clientOptions(ctx))
+	if err != nil {
+		return err
+	}
+
+	return infraType.ForEachResource(ctx, resources, "remove tags from", func(ctx context.Context, resource infraType.CloudResource) error {
+		// The Delete operation matches on name and value, so pass the
+		// current values of the keys the resource carries.
+		toDelete := make(map[string]*string)
+		for _, k := range keys {
+			if v, ok := resource.Tags[k]; ok {
+				toDelete[k] = to.Ptr(v)
+			}
+		}
+		if len(toDelete) == 0 {
+			return nil
+		}
+
+		_, err := client.UpdateAtScope(ctx, resource.ID, armresources.TagsPatchResource{
+			Operation: to.Ptr(armresources.TagsPatchOperationDelete),
+			Properties: &armresources.Tags{
+				Tags: toDelete,
+			},
+		}, nil)
+		return err
+	})
+}
@@ -0,0 +1,327 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+const (
+	testSubscription  = "sub"
+	testResourceGroup = "mycluster-rg"
+	rgPath            = "/subscriptions/" + testSubscription + "/resourceGroups/" + testResourceGroup
+)
+
+// fakeARM is an HTTP stand-in for the Azure Resource Manager API.
+type fakeARM struct {
+	mu sync.Mutex
+	// collections are the items of the collections a GET lists, by
+	// lower-cased path and, for the generic resources API, "?" and the
+	// $filter.
+	collections map[string][]map[string]interface{}
+	// patches are the PATCH requests, as "<path> <tags>", with the tags
+	// sorted as key=value and, for the Tags API, prefixed by the operation.
+	patches []string
+}
+
+func (f *fakeARM) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path := strings.ToLower(r.URL.Path)
+
+	switch r.Method {
+	case http.MethodGet:
+		key := path
+		if filter := r.URL.Query().Get("$filter"); filter != "" {
+			key += "?" + filter
+		}
+		items, ok := f.collections[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":{"code":"NotFound","message":"not found"}}`))
+			return
+		}
+		if strings.HasSuffix(path, "/resourcegroups/"+strings.ToLower(testResourceGroup)) {
+			_ = json.NewEncoder(w).Encode(items[0])
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"value": items})
+	case http.MethodPatch:
+		var body struct {
+			Tags       map[string]string `json:"tags"`
+			Operation  string            `json:"operation"`
+			Properties struct {
+				Tags map[string]string `json:"tags"`
+			} `json:"properties"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		tags := body.Tags
+		prefix := ""
+		if body.Operation != "" {
+			tags = body.Properties.Tags
+			prefix = body.Operation + " "
+		}
+		f.patches = append(f.patches, r.URL.Path+" "+prefix+tagsLabel(tags))
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func tagsLabel(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for k, v := range tags {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+type fakeCredential struct{}
+
+func (fakeCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// startFakeARM returns a context that points the package at a fakeARM.
+func startFakeARM(t *testing.T, f *fakeARM) context.Context {
+	t.Helper()
+	server := httptest.NewTLSServer(f)
+	t.Cleanup(server.Close)
+
+	ctx := WithCredential(context.Background(), fakeCredential{}, testSubscription)
+	return WithClientOptions(ctx, &arm.ClientOptions{ClientOptions: policy.ClientOptions{
+		Cloud: cloud.Configuration{
+			ActiveDirectoryAuthorityHost: server.URL,
+			Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+				cloud.ResourceManager: {Audience: "https://management.azure.com", Endpoint: server.URL},
+			},
+		},
+		Transport: server.Client(),
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	}})
+}
+
+func item(id string, tags map[string]string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "name": id[strings.LastIndex(id, "/")+1:], "tags": tags}
+}
+
+func TestListAzureResources(t *testing.T) {
+	network := rgPath + "/providers/Microsoft.Network"
+	compute := rgPath + "/providers/Microsoft.Compute"
+	collections := map[string][]map[string]interface{}{
+		network + "/virtualNetworks":              {item(network+"/virtualNetworks/vnet", nil)},
+		network + "/virtualNetworks/vnet/subnets": {item(network+"/virtualNetworks/vnet/subnets/master", nil)},
+		network + "/networkInterfaces":            {item(network+"/networkInterfaces/master-0-nic", map[string]string{"Owner": "x"})},
+		network + "/routeTables":                  {item(network+"/routeTables/node-rt", nil)},
+		network + "/privateEndpoints":             {item(network+"/privateEndpoints/pe", nil)},
+		compute + "/availabilitySets":             {item(compute+"/availabilitySets/as", nil)},
+		compute + "/galleries":                    {item(compute+"/galleries/gallery", nil)},
+		compute + "/galleries/gallery/images":     {item(compute+"/galleries/gallery/images/rhcos", nil)},
+		rgPath + "/resources?resourceType eq 'Microsoft.Network/privateDnsZones'": {
+			item(network+"/privateDnsZones/example.com", nil),
+		},
+		rgPath + "/resources?resourceType eq 'Microsoft.ManagedIdentity/userAssignedIdentities'": {
+			item(rgPath+"/providers/Microsoft.ManagedIdentity/userAssignedIdentities/identity", nil),
+		},
+		"/subscriptions/" + testSubscription + "/resourcegroups/" + testResourceGroup: {item(rgPath, nil)},
+	}
+	f := &fakeARM{collections: make(map[string][]map[string]interface{})}
+	for path, items := range collections {
+		before, filter, _ := strings.Cut(path, "?")
+		key := strings.ToLower(before)
+		if filter != "" {
+			key += "?" + filter
+		}
+		f.collections[key] = items
+	}
+	ctx := startFakeARM(t, f)
+
+	scheme := runtime.NewScheme()
+	if err := configv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{
+				Type:  configv1.AzurePlatformType,
+				Azure: &configv1.AzurePlatformStatus{ResourceGroupName: testResourceGroup},
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).WithStatusSubresource(infra).Build()
+
+	resources, err := ListAzureResources(ctx, k8sClient)
+	if err != nil {
+		t.Fatalf("ListAzureResources() error = %v", err)
+	}
+	var got []string
+	for _, res := range resources {
+		label := string(res.Type) + " " + res.Name
+		if res.NotTaggable {
+			label += " (not taggable)"
+		}
+		got = append(got, label)
+	}
+	want := []string{
+		"AzureVirtualNetwork vnet",
+		"AzureSubnet master (not taggable)",
+		"AzureNetworkInterface master-0-nic",
+		"AzureRouteTable node-rt",
+		"AzurePrivateEndpoint pe",
+		"AzureAvailabilitySet as",
+		"AzureImageGallery gallery",
+		"AzureGalleryImage rhcos",
+		"AzurePrivateDNSZone example.com",
+		"AzureManagedIdentity identity",
+		"AzureResourceGroup " + testResourceGroup,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("discovered\n%q\nwant\n%q", got, want)
+	}
+	if tags := resources[2].Tags; tags["Owner"] != "x" {
+		t.Errorf("tags of the NIC = %v, want Owner=x", tags)
+	}
+}
+
+func TestUpdateResourceTags(t *testing.T) {
+	tags := map[string]string{"Owner": "DevOps"}
+	current := map[string]string{"Env": "prod"}
+
+	tests := []struct {
+		resourceType infraType.CloudResourceType
+		id           string
+		want         string
+		wantErr      bool
+	}{
+		{
+			resourceType: infraType.CloudResourceTypeAzureNetworkInterface,
+			id:           rgPath + "/providers/Microsoft.Network/networkInterfaces/nic",
+			want:         "Env=prod,Owner=DevOps",
+		},
+		{
+			resourceType: infraType.CloudResourceTypeAzureRouteTable,
+			id:           rgPath + "/providers/Microsoft.Network/routeTables/rt",
+			want:         "Env=prod,Owner=DevOps",
+		},
+		{
+			resourceType: infraType.CloudResourceTypeAzureAvailabilitySet,
+			id:           rgPath + "/providers/Microsoft.Compute/availabilitySets/as",
+			want:         "Env=prod,Owner=DevOps",
+		},
+		{
+			resourceType: infraType.CloudResourceTypeAzureImageGallery,
+			id:           rgPath + "/providers/Microsoft.Compute/galleries/gallery",
+			want:         "Env=prod,Owner=DevOps",
+		},
+		{
+			resourceType: infraType.CloudResourceTypeAzureGalleryImage,
+			id:           rgPath + "/providers/Microsoft.Compute/galleries/gallery/images/rhcos",
+			want:         "Env=prod,Owner=DevOps",
+		},
+		{
+			resourceType: infraType.CloudResourceTypeAzurePrivateDNSZone,
+			id:           rgPath + "/providers/Microsoft.Network/privateDnsZones/example.com",
+			want:         "Merge Owner=DevOps",
+		},
+		{
+			resourceType: infraType.CloudResourceTypeAzureResourceGroup,
+			id:           rgPath,
+			want:         "Merge Owner=DevOps",
+		},
+		{
+			resourceType: infraType.CloudResourceTypeAzureSubnet,
+			id:           rgPath + "/providers/Microsoft.Network/virtualNetworks/vnet/subnets/master",
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.resourceType), func(t *testing.T) {
+			f := &fakeARM{}
+			ctx := startFakeARM(t, f)
+
+			resources := []infraType.CloudResource{{
+				CloudProvider: infraType.CloudPlatformAzure,
+				Type:          tt.resourceType,
+				ID:            tt.id,
+				Tags:          current,
+			}}
+			err := UpdateResourceTags(ctx, resources, tags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateResourceTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var want []string
+			if !tt.wantErr {
+				path := tt.id
+				if strings.HasPrefix(tt.want, "Merge ") {
+					path += "/providers/Microsoft.Resources/tags/default"
+				}
+				want = []string{path + " " + tt.want}
+			}
+			if !reflect.DeepEqual(f.patches, want) {
+				t.Errorf("patches = %q, want %q", f.patches, want)
+			}
+		})
+	}
+}
+
+func TestRemoveResourceTags(t *testing.T) {
+	f := &fakeARM{}
+	ctx := startFakeARM(t, f)
+
+	var resources []infraType.CloudResource
+	for i, tags := range []map[string]string{
+		{"Owner": "DevOps", "Team": "infra", "Env": "prod"},
+		{"Env": "prod"},
+		{"Team": "storage"},
+	} {
+		resources = append(resources, infraType.CloudResource{
+			CloudProvider: infraType.CloudPlatformAzure,
+			Type:          infraType.CloudResourceTypeAzureManagedDisk,
+			ID:            fmt.Sprintf("%s/providers/Microsoft.Compute/disks/disk-%d", rgPath, i),
+			Tags:          tags,
+		})
+	}
+	if err := RemoveResourceTags(ctx, resources, []string{"Owner", "Team"}); err != nil {
+		t.Fatalf("RemoveResourceTags() error = %v", err)
+	}
+
+	// The resource without the keys is not written.
+	suffix := "/providers/Microsoft.Resources/tags/default "
+	want := []string{
+		resources[0].ID + suffix + "Delete Owner=DevOps,Team=infra",
+		resources[2].ID + suffix + "Delete Team=storage",
+	}
+	if !reflect.DeepEqual(f.patches, want) {
+		t.Errorf("patches = %q, want %q", f.patches, want)
+	}
+}
@@ -0,0 +1,63 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+type credentialKey struct{}
+
+type clientOptionsKey struct{}
+
+type credential struct {
+	cred           azcore.TokenCredential
+	subscriptionID string
+}
+
+// WithCredential returns a context that makes the package use cred and the
+// subscription instead of DefaultAzureCredential and AZURE_SUBSCRIPTION_ID.
+func WithCredential(ctx context.Context, cred azcore.TokenCredential, subscriptionID string) context.Context {
+	return context.WithValue(ctx, credentialKey{}, credential{cred: cred, subscriptionID: subscriptionID})
+}
+
+// getCredential returns the credential set with WithCredential, or
+// DefaultAzureCredential.
+func getCredential(ctx context.Context) (azcore.TokenCredential, error) {
+	if c, ok := ctx.Value(credentialKey{}).(credential); ok {
+		return c.cred, nil
+	}
+	cred, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", infraType.ErrCredentials, err)
+	}
+	return cred, nil
+}
+
+// subscriptionID returns the subscription set with WithCredential, or
+// AZURE_SUBSCRIPTION_ID.
+func subscriptionID(ctx context.Context) string {
+	if c, ok := ctx.Value(credentialKey{}).(credential); ok {
+		return c.subscriptionID
+	}
+	return os.Getenv("AZURE_SUBSCRIPTION_ID")
+}
+
+// WithClientOptions returns a context that makes the package create its ARM
+// clients with opts, e.g. to talk to another cloud or a test server.
+func WithClientOptions(ctx context.Context, opts *arm.ClientOptions) context.Context {
+	return context.WithValue(ctx, clientOptionsKey{}, opts)
+}
+
+// clientOptions returns the options set with WithClientOptions, or nil for
+// the SDK defaults.
+func clientOptions(ctx context.Context) *arm.ClientOptions {
+	opts, _ := ctx.Value(clientOptionsKey{}).(*arm.ClientOptions)
+	return opts
+}
@@ -0,0 +1,92 @@
+package azure
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// DescribeResource returns the resource with its current tags, read through
+// the Tags API so any resource type works.
+func DescribeResource(ctx context.Context, resource infraType.CloudResource) (infraType.CloudResource, error) {
+	cred, err := getCredential(ctx)
+	if err != nil {
+		return resource, fmt.Errorf("azure authentication failed: %w", err)
+	}
+
+	subscriptionID := subscriptionID(ctx)
+	if subscriptionID == "" {
+		return resource, fmt.Errorf("AZURE_SUBSCRIPTION_ID environment variable not set")
+	}
+
+	client, err := armresources.NewTagsClient(subscriptionID, cred, clientOptions(ctx))
+	if err != nil {
+		return resource, err
+	}
+
+	resp, err := client.GetAtScope(ctx, resource.ID, nil)
+	if err != nil {
+		return resource, fmt.Errorf("error getting tags of %s: %w", resource.ID, err)
+	}
+
+	resource.CloudProvider = infraType.CloudPlatformAzure
+	resource.Tags = make(map[string]string)
+	if resp.Properties != nil {
+		resource.Tags = convertTags(resp.Properties.Tags)
+	}
+	if resource.Name == "" {
+		if parsedID, err := arm.ParseResourceID(resource.ID); err == nil {
+			resource.Name = parsedID.Name
+		}
+	}
+	return resource, nil
+}
+
+// FindLoadBalancer returns the public IP with the given address in the
+// cluster resource group, as reported in a Service's status, or nil when
+// there is none. The public IP is the per-Service resource; the load
+// balancer itself is shared by all Services.
+func FindLoadBalancer(ctx context.Context, k8sClient client.Client, ipAddress string) (*infraType.CloudResource, error) {
+	cred, err := getCredential(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("azure authentication failed: %w", err)
+	}
+
+	resourceGroup, _, err := getClusterResourceGroup(ctx, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := armnetwork.NewPublicIPAddressesClient(subscriptionID(ctx), cred, clientOptions(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	pager := client.NewListPager(resourceGroup, nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Public IPs: %w", err)
+		}
+
+		for _, ip := range page.Value {
+			if ip.Properties == nil || ip.Properties.IPAddress == nil || *ip.Properties.IPAddress != ipAddress {
+				continue
+			}
+			return &infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformAzure,
+				Type:          infraType.CloudResourceTypeAzurePublicIP,
+				ID:            *ip.ID,
+				Name:          *ip.Name,
+				Tags:          convertTags(ip.Tags),
+			}, nil
+		}
+	}
+	return nil, nil
+}
@@ -1,8 +1,9 @@
 package azure
 
 import (
-	"errors"
 	"fmt"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
 )
 
 const (
@@ -14,7 +15,7 @@ const (
 // isValidAzureTag validates Azure tags
 func IsValidAzureTag(tags map[string]string) error {
 	if len(tags) > azureMaxTags {
-		return errors.New("Azure allows a maximum of 50 tags per resource")
+		return fmt.Errorf("%w: Azure allows a maximum of %d tags per resource", infraType.ErrTagLimitExceeded, azureMaxTags)
 	}
 	for key, value := range tags {
 		if len(key) == 0 || len(key) > azureKeyMaxLength {
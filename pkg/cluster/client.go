@@ -0,0 +1,55 @@
+package cluster
+
+import (
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/api/v1alpha1"
+)
+
+// Scheme holds the Kubernetes, OpenShift config and ClusterMetadataPolicy
+// types every client of this tool works with.
+var Scheme = runtime.NewScheme()
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(Scheme))
+	utilruntime.Must(configv1.AddToScheme(Scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(Scheme))
+}
+
+// RESTConfig loads the cluster connection the way kubectl does: from the
+// kubeconfig file if set, else $KUBECONFIG or ~/.kube/config, using
+// kubeContext instead of the current context if set. Without any kubeconfig,
+// e.g. when running as a pod, the in-cluster config is used.
+func RESTConfig(kubeconfig, kubeContext string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubeContext}
+
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load Kubernetes config: %w", err)
+	}
+	return cfg, nil
+}
+
+// NewClient returns a client for the cluster selected by RESTConfig.
+func NewClient(kubeconfig, kubeContext string) (client.Client, error) {
+	cfg, err := RESTConfig(kubeconfig, kubeContext)
+	if err != nil {
+		return nil, err
+	}
+
+	k8sClient, err := client.New(cfg, client.Options{Scheme: Scheme})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+	return k8sClient, nil
+}
@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+var infrastructureGVK = schema.GroupVersionKind{Group: "config.openshift.io", Version: "v1", Kind: "Infrastructure"}
+
+// infrastructureTagPaths lists the platforms whose Infrastructure spec
+// accepts user tags after installation. Azure and GCP user tags can only be
+// set at install time.
+var infrastructureTagPaths = map[infraType.CloudPlatform][]string{
+	infraType.CloudPlatformAWS: {"spec", "platformSpec", "aws", "resourceTags"},
+}
+
+// UpdateInfrastructureTags merges the tags into the user tags of
+// Infrastructure.spec.platformSpec, from where operators propagate them to
+// the resources they create. Older clusters prune the field silently, so
+// the object is read back to confirm the tags were kept.
+func UpdateInfrastructureTags(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform,
+	tags map[string]string, dryRun bool) (*ObjectChange, error) {
+
+	path, ok := infrastructureTagPaths[cloudPlatform]
+	if !ok {
+		return nil, fmt.Errorf("the Infrastructure spec does not accept user tags for platform %s", cloudPlatform)
+	}
+
+	infra := &unstructured.Unstructured{}
+	infra.SetGroupVersionKind(infrastructureGVK)
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "cluster"}, infra); err != nil {
+		return nil, fmt.Errorf("failed to get Infrastructure: %w", err)
+	}
+
+	parent := path[:len(path)-1]
+	value, _, _ := unstructured.NestedMap(infra.Object, parent...)
+	if value == nil {
+		value = make(map[string]interface{})
+	}
+
+	field := listField(path[len(path)-1], "key", "value")
+	current := field.get(value)
+	field.set(value, tags)
+	merged := field.get(value)
+	if equalTags(current, merged) {
+		return nil, nil
+	}
+
+	change := &ObjectChange{Kind: "Infrastructure", Name: "cluster", Old: current, New: merged}
+	if dryRun {
+		return change, nil
+	}
+
+	if err := unstructured.SetNestedMap(infra.Object, value, parent...); err != nil {
+		return nil, err
+	}
+	if err := k8sClient.Update(ctx, infra); err != nil {
+		return nil, fmt.Errorf("failed to update Infrastructure: %w", err)
+	}
+
+	updated := &unstructured.Unstructured{}
+	updated.SetGroupVersionKind(infrastructureGVK)
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "cluster"}, updated); err != nil {
+		return nil, fmt.Errorf("failed to get Infrastructure: %w", err)
+	}
+	stored, _, _ := unstructured.NestedMap(updated.Object, parent...)
+	if !equalTags(field.get(stored), merged) {
+		return nil, fmt.Errorf("this cluster version does not support %s", strings.Join(path, "."))
+	}
+	return change, nil
+}
@@ -0,0 +1,196 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// LedgerNamespace and LedgerName locate the ConfigMap the ownership
+	// ledger of the sync command is kept in. Each ClusterMetadataPolicy has a
+	// ledger of its own, see PolicyLedgerName.
+	LedgerNamespace = "openshift-config"
+	LedgerName      = "openshift-metadata-manager-ledger"
+
+	ledgerDataKey = "ledger.json"
+)
+
+// Ledger records which tag keys of each resource the tool added, like the
+// last-applied annotation of kubectl, so that they can be pruned once they
+// are no longer desired. Keys a resource already carried when they were
+// synced belong to someone else and are never recorded.
+type Ledger struct {
+	// Keys are the sorted keys the tool added, by CloudResource.Key.
+	Keys map[string][]string `json:"keys"`
+
+	name      string
+	configMap *corev1.ConfigMap
+}
+
+// PolicyLedgerName returns the name of the ledger of a ClusterMetadataPolicy.
+func PolicyLedgerName(policy string) string {
+	return LedgerName + "-" + policy
+}
+
+// ReadLedger reads the ledger with the name from the cluster. A missing
+// ledger is empty.
+func ReadLedger(ctx context.Context, k8sClient client.Client, name string) (*Ledger, error) {
+	cm := &corev1.ConfigMap{}
+	err := k8sClient.Get(ctx, client.ObjectKey{Namespace: LedgerNamespace, Name: name}, cm)
+	if apierrors.IsNotFound(err) {
+		return &Ledger{Keys: make(map[string][]string), name: name}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", LedgerNamespace, name, err)
+	}
+
+	l := &Ledger{name: name, configMap: cm}
+	if data := cm.Data[ledgerDataKey]; data != "" {
+		if err := json.Unmarshal([]byte(data), l); err != nil {
+			return nil, fmt.Errorf("failed to parse ConfigMap %s/%s: %w", LedgerNamespace, name, err)
+		}
+	}
+	if l.Keys == nil {
+		l.Keys = make(map[string][]string)
+	}
+	return l, nil
+}
+
+// Managed returns the keys the tool added to the resource.
+func (l *Ledger) Managed(res infraType.CloudResource) []string {
+	return l.Keys[res.Key()]
+}
+
+// Add records keys as added to the resource.
+func (l *Ledger) Add(res infraType.CloudResource, keys []string) {
+	set := make(map[string]bool)
+	for _, k := range l.Keys[res.Key()] {
+		set[k] = true
+	}
+	for _, k := range keys {
+		set[k] = true
+	}
+	l.set(res, set)
+}
+
+// Own records the keys of tags that the resource, with the tags it had
+// before they were written, did not carry yet.
+func (l *Ledger) Own(res infraType.CloudResource, tags map[string]string) {
+	var added []string
+	for k := range tags {
+		if _, ok := res.Tags[k]; !ok {
+			added = append(added, k)
+		}
+	}
+	if len(added) > 0 {
+		l.Add(res, added)
+	}
+}
+
+// Stale returns the keys the tool added to the resource that are no longer
+// desired and the resource still carries.
+func (l *Ledger) Stale(res infraType.CloudResource, desired map[string]string) []string {
+	var stale []string
+	for _, k := range l.Managed(res) {
+		if _, ok := desired[k]; ok {
+			continue
+		}
+		if _, ok := res.Tags[k]; ok {
+			stale = append(stale, k)
+		}
+	}
+	return stale
+}
+
+// Remove forgets keys of the resource.
+func (l *Ledger) Remove(res infraType.CloudResource, keys []string) {
+	set := make(map[string]bool)
+	for _, k := range l.Keys[res.Key()] {
+		set[k] = true
+	}
+	for _, k := range keys {
+		delete(set, k)
+	}
+	l.set(res, set)
+}
+
+func (l *Ledger) set(res infraType.CloudResource, set map[string]bool) {
+	if len(set) == 0 {
+		delete(l.Keys, res.Key())
+		return
+	}
+	keys := make([]string, 0, len(set))
+	for k := range set {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	l.Keys[res.Key()] = keys
+}
+
+// WriteLedger saves the ledger to the cluster, creating the ConfigMap on
+// first use.
+func WriteLedger(ctx context.Context, k8sClient client.Client, l *Ledger) error {
+	data, err := json.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	if l.configMap == nil {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: LedgerNamespace, Name: l.name},
+			Data:       map[string]string{ledgerDataKey: string(data)},
+		}
+		if err := k8sClient.Create(ctx, cm); err != nil {
+			return fmt.Errorf("failed to create ConfigMap %s/%s: %w", LedgerNamespace, l.name, err)
+		}
+		l.configMap = cm
+		return nil
+	}
+
+	cm := l.configMap.DeepCopy()
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[ledgerDataKey] = string(data)
+	if err := k8sClient.Update(ctx, cm); err != nil {
+		return fmt.Errorf("failed to update ConfigMap %s/%s: %w", LedgerNamespace, l.name, err)
+	}
+	l.configMap = cm
+	return nil
+}
+
+// UpdateLedger applies update to the ledger with the name and saves it.
+// When another writer saved the ledger in the meantime, the ledger is read
+// again and update applied to it, so that neither writer's records are
+// lost.
+func UpdateLedger(ctx context.Context, k8sClient client.Client, name string, update func(*Ledger)) error {
+	conflict := func(err error) bool {
+		return apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err)
+	}
+	return retry.OnError(retry.DefaultRetry, conflict, func() error {
+		l, err := ReadLedger(ctx, k8sClient, name)
+		if err != nil {
+			return err
+		}
+		update(l)
+		return WriteLedger(ctx, k8sClient, l)
+	})
+}
+
+// DeleteLedger deletes the ledger with the name from the cluster.
+func DeleteLedger(ctx context.Context, k8sClient client.Client, name string) error {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: LedgerNamespace, Name: name}}
+	if err := client.IgnoreNotFound(k8sClient.Delete(ctx, cm)); err != nil {
+		return fmt.Errorf("failed to delete ConfigMap %s/%s: %w", LedgerNamespace, name, err)
+	}
+	return nil
+}
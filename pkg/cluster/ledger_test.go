@@ -0,0 +1,232 @@
+package cluster
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestLedgerAddRemove(t *testing.T) {
+	res := infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEC2Instance, ID: "i-1"}
+
+	tests := []struct {
+		name   string
+		keys   []string
+		add    []string
+		remove []string
+		want   []string
+	}{
+		{name: "add to an empty ledger", add: []string{"Team", "Owner"}, want: []string{"Owner", "Team"}},
+		{name: "add is idempotent", keys: []string{"Owner"}, add: []string{"Owner", "Team"}, want: []string{"Owner", "Team"}},
+		{name: "remove", keys: []string{"Owner", "Team"}, remove: []string{"Team"}, want: []string{"Owner"}},
+		{name: "remove unknown key", keys: []string{"Owner"}, remove: []string{"Env"}, want: []string{"Owner"}},
+		{name: "remove the last key", keys: []string{"Owner"}, remove: []string{"Owner"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Ledger{Keys: make(map[string][]string)}
+			if tt.keys != nil {
+				l.Keys[res.Key()] = tt.keys
+			}
+			if tt.add != nil {
+				l.Add(res, tt.add)
+			}
+			if tt.remove != nil {
+				l.Remove(res, tt.remove)
+			}
+			if got := l.Managed(res); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Managed() = %v, want %v", got, tt.want)
+			}
+			if _, ok := l.Keys[res.Key()]; ok != (tt.want != nil) {
+				t.Errorf("ledger has the resource = %v, want %v", ok, tt.want != nil)
+			}
+		})
+	}
+}
+
+func TestLedgerOwnStale(t *testing.T) {
+	tests := []struct {
+		name      string
+		tags      map[string]string
+		managed   []string
+		written   map[string]string
+		desired   map[string]string
+		wantOwned []string
+		wantStale []string
+	}{
+		{
+			name:      "new keys are owned",
+			tags:      map[string]string{"Env": "prod"},
+			written:   map[string]string{"Env": "dev", "Owner": "DevOps"},
+			wantOwned: []string{"Owner"},
+		},
+		{
+			name:    "keys the resource carried are not owned",
+			tags:    map[string]string{"Owner": "Old"},
+			written: map[string]string{"Owner": "DevOps"},
+		},
+		{
+			name:      "undesired managed key is stale",
+			tags:      map[string]string{"Owner": "DevOps", "Team": "infra", "Env": "prod"},
+			managed:   []string{"Owner", "Team"},
+			desired:   map[string]string{"Owner": "DevOps"},
+			wantOwned: []string{"Owner", "Team"},
+			wantStale: []string{"Team"},
+		},
+		{
+			name:      "managed key removed by hand is not stale",
+			tags:      map[string]string{"Owner": "DevOps"},
+			managed:   []string{"Owner", "Team"},
+			desired:   map[string]string{"Owner": "DevOps"},
+			wantOwned: []string{"Owner", "Team"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEC2Instance, ID: "i-1", Tags: tt.tags}
+			l := &Ledger{Keys: make(map[string][]string)}
+			if tt.managed != nil {
+				l.Keys[res.Key()] = tt.managed
+			}
+
+			l.Own(res, tt.written)
+			if got := l.Managed(res); !reflect.DeepEqual(got, tt.wantOwned) {
+				t.Errorf("Managed() = %v, want %v", got, tt.wantOwned)
+			}
+			stale := l.Stale(res, tt.desired)
+			sort.Strings(stale)
+			if !reflect.DeepEqual(stale, tt.wantStale) {
+				t.Errorf("Stale() = %v, want %v", stale, tt.wantStale)
+			}
+		})
+	}
+}
+
+func TestLedgerReadWriteDelete(t *testing.T) {
+	ctx := context.Background()
+	k8sClient := fake.NewClientBuilder().WithScheme(Scheme).Build()
+	name := PolicyLedgerName("default")
+	res := infraType.CloudResource{Type: infraType.CloudResourceTypeAWSS3Bucket, ID: "bucket"}
+
+	l, err := ReadLedger(ctx, k8sClient, name)
+	if err != nil {
+		t.Fatalf("ReadLedger() error = %v", err)
+	}
+	if len(l.Keys) != 0 {
+		t.Errorf("missing ledger has keys %v", l.Keys)
+	}
+
+	// The first write creates the ConfigMap, the second updates it.
+	l.Add(res, []string{"Owner"})
+	if err := WriteLedger(ctx, k8sClient, l); err != nil {
+		t.Fatalf("WriteLedger() error = %v", err)
+	}
+	l.Add(res, []string{"Team"})
+	if err := WriteLedger(ctx, k8sClient, l); err != nil {
+		t.Fatalf("WriteLedger() error = %v", err)
+	}
+
+	l, err = ReadLedger(ctx, k8sClient, name)
+	if err != nil {
+		t.Fatalf("ReadLedger() error = %v", err)
+	}
+	if got, want := l.Managed(res), []string{"Owner", "Team"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Managed() = %v, want %v", got, want)
+	}
+
+	if err := DeleteLedger(ctx, k8sClient, name); err != nil {
+		t.Fatalf("DeleteLedger() error = %v", err)
+	}
+	err = k8sClient.Get(ctx, client.ObjectKey{Namespace: LedgerNamespace, Name: name}, &corev1.ConfigMap{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("ledger ConfigMap still exists, err = %v", err)
+	}
+	if err := DeleteLedger(ctx, k8sClient, name); err != nil {
+		t.Errorf("DeleteLedger() of a missing ledger error = %v", err)
+	}
+}
+
+func TestUpdateLedger(t *testing.T) {
+	ours := infraType.CloudResource{Type: infraType.CloudResourceTypeAWSS3Bucket, ID: "bucket"}
+	theirs := infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEC2Instance, ID: "i-1"}
+	name := PolicyLedgerName("default")
+
+	tests := []struct {
+		name     string
+		existing bool
+	}{
+		{name: "concurrent create"},
+		{name: "concurrent update", existing: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			base := fake.NewClientBuilder().WithScheme(Scheme).Build()
+			if tt.existing {
+				l, _ := ReadLedger(ctx, base, name)
+				l.Add(ours, []string{"Owner"})
+				if err := WriteLedger(ctx, base, l); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Another writer records its resource right before the first
+			// write.
+			raced := false
+			race := func(ctx context.Context, c client.WithWatch) {
+				if raced {
+					return
+				}
+				raced = true
+				l, err := ReadLedger(ctx, c, name)
+				if err != nil {
+					t.Fatal(err)
+				}
+				l.Add(theirs, []string{"Team"})
+				if err := WriteLedger(ctx, c, l); err != nil {
+					t.Fatal(err)
+				}
+			}
+			k8sClient := interceptor.NewClient(base, interceptor.Funcs{
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					race(ctx, c)
+					return c.Create(ctx, obj, opts...)
+				},
+				Update: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.UpdateOption) error {
+					race(ctx, c)
+					return c.Update(ctx, obj, opts...)
+				},
+			})
+
+			err := UpdateLedger(ctx, k8sClient, name, func(l *Ledger) {
+				l.Add(ours, []string{"Env"})
+			})
+			if err != nil {
+				t.Fatalf("UpdateLedger() error = %v", err)
+			}
+
+			l, err := ReadLedger(ctx, base, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantOurs := []string{"Env"}
+			if tt.existing {
+				wantOurs = []string{"Env", "Owner"}
+			}
+			if got := l.Managed(ours); !reflect.DeepEqual(got, wantOurs) {
+				t.Errorf("Managed(ours) = %v, want %v", got, wantOurs)
+			}
+			if got := l.Managed(theirs); !reflect.DeepEqual(got, []string{"Team"}) {
+				t.Errorf("Managed(theirs) = %v, want the concurrent record", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,280 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+const machineAPINamespace = "openshift-machine-api"
+
+// ObjectChange describes the tags of a Kubernetes object before and after
+// an update.
+type ObjectChange struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Old       map[string]string
+	New       map[string]string
+	// Warning is set when applying the change has side effects, such as a
+	// control plane rollout.
+	Warning string
+	// Skipped is why the change was not applied, if it was not.
+	Skipped string
+}
+
+// machineTemplates lists where each Machine API kind keeps the providerSpec
+// new machines are created from.
+var machineTemplates = []struct {
+	gvk  schema.GroupVersionKind
+	path []string
+}{
+	{
+		gvk:  schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineSetList"},
+		path: []string{"spec", "template", "spec", "providerSpec", "value"},
+	},
+	{
+		gvk:  schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1", Kind: "ControlPlaneMachineSetList"},
+		path: []string{"spec", "template", "machines_v1beta1_machine_openshift_io", "spec", "providerSpec", "value"},
+	},
+}
+
+// UpdateMachineTemplates merges the tags into the providerSpec of every
+// MachineSet and ControlPlaneMachineSet so that new machines and their disks
+// are created with them. Objects are only written when dryRun is false.
+// With tagBindings set, GCP tags are written as resourceManagerTags instead
+// of labels. A ControlPlaneMachineSet whose strategy replaces the control
+// plane machines on change is only updated with rollControlPlane set,
+// otherwise its change is reported as skipped.
+func UpdateMachineTemplates(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform,
+	tags map[string]string, tagBindings, rollControlPlane, dryRun bool) ([]ObjectChange, error) {
+
+	field, err := providerTagField(cloudPlatform, tagBindings)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ObjectChange
+	for _, template := range machineTemplates {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(template.gvk)
+		if err := k8sClient.List(ctx, list, client.InNamespace(machineAPINamespace)); err != nil {
+			// ControlPlaneMachineSets are not available on every cluster.
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return changes, fmt.Errorf("failed to list %s: %w", template.gvk.Kind, err)
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			value, found, err := unstructured.NestedMap(obj.Object, template.path...)
+			if err != nil || !found {
+				continue
+			}
+
+			current := field.get(value)
+			merged := mergeMaps(current, tags)
+			if equalTags(current, merged) {
+				continue
+			}
+
+			change := ObjectChange{
+				Kind:      obj.GetKind(),
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				Old:       current,
+				New:       merged,
+			}
+			if obj.GetKind() == "ControlPlaneMachineSet" {
+				strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "strategy", "type")
+				if strategy != "OnDelete" {
+					if rollControlPlane {
+						change.Warning = "the RollingUpdate strategy will replace the control plane machines"
+					} else {
+						change.Skipped = "its RollingUpdate strategy would replace the control plane machines"
+					}
+				}
+			}
+			changes = append(changes, change)
+
+			if dryRun || change.Skipped != "" {
+				continue
+			}
+
+			field.set(value, tags)
+			if err := unstructured.SetNestedMap(obj.Object, value, template.path...); err != nil {
+				return changes, err
+			}
+			if err := k8sClient.Update(ctx, obj); err != nil {
+				return changes, fmt.Errorf("failed to update %s %s: %w", obj.GetKind(), obj.GetName(), err)
+			}
+		}
+	}
+	return changes, nil
+}
+
+// tagField reads and merges the tags of a providerSpec value.
+type tagField struct {
+	get func(value map[string]interface{}) map[string]string
+	set func(value map[string]interface{}, tags map[string]string)
+}
+
+// providerTagField returns how tags are stored in the providerSpec of the
+// platform's machines.
+func providerTagField(cloudPlatform infraType.CloudPlatform, tagBindings bool) (tagField, error) {
+	switch cloudPlatform {
+	case infraType.CloudPlatformAWS:
+		return listField("tags", "name", "value"), nil
+	case infraType.CloudPlatformAzure:
+		return mapField("tags"), nil
+	case infraType.CloudPlatformGCP:
+		if tagBindings {
+			return resourceManagerTagsField(), nil
+		}
+		return mapField("labels"), nil
+	case infraType.CloudPlatformOpenStack:
+		return mapField("serverMetadata"), nil
+	case infraType.CloudPlatformNutanix:
+		return listField("categories", "key", "value"), nil
+	default:
+		return tagField{}, fmt.Errorf("machine providerSpec tags are not supported for platform %s", cloudPlatform)
+	}
+}
+
+// mapField handles tags stored as a string map, e.g. Azure tags.
+func mapField(name string) tagField {
+	return tagField{
+		get: func(value map[string]interface{}) map[string]string {
+			tags := make(map[string]string)
+			existing, _, _ := unstructured.NestedStringMap(value, name)
+			for k, v := range existing {
+				tags[k] = v
+			}
+			return tags
+		},
+		set: func(value map[string]interface{}, tags map[string]string) {
+			existing, _, _ := unstructured.NestedStringMap(value, name)
+			if existing == nil {
+				existing = make(map[string]string)
+			}
+			for k, v := range tags {
+				existing[k] = v
+			}
+			_ = unstructured.SetNestedStringMap(value, existing, name)
+		},
+	}
+}
+
+// listField handles tags stored as a list of key/value objects, e.g. AWS
+// [{name: ..., value: ...}]. Existing entries keep their position.
+func listField(name, keyField, valueField string) tagField {
+	return tagField{
+		get: func(value map[string]interface{}) map[string]string {
+			tags := make(map[string]string)
+			existing, _, _ := unstructured.NestedSlice(value, name)
+			for _, item := range existing {
+				if entry, ok := item.(map[string]interface{}); ok {
+					k, _ := entry[keyField].(string)
+					v, _ := entry[valueField].(string)
+					tags[k] = v
+				}
+			}
+			return tags
+		},
+		set: func(value map[string]interface{}, tags map[string]string) {
+			existing, _, _ := unstructured.NestedSlice(value, name)
+			seen := make(map[string]bool)
+			for _, item := range existing {
+				if entry, ok := item.(map[string]interface{}); ok {
+					k, _ := entry[keyField].(string)
+					if v, ok := tags[k]; ok {
+						entry[valueField] = v
+					}
+					seen[k] = true
+				}
+			}
+			for _, k := range sortedKeys(tags) {
+				if !seen[k] {
+					existing = append(existing, map[string]interface{}{keyField: k, valueField: tags[k]})
+				}
+			}
+			_ = unstructured.SetNestedSlice(value, existing, name)
+		},
+	}
+}
+
+// resourceManagerTagsField handles GCP resourceManagerTags, keyed here by
+// <parentID>/<key> like the tag bindings sync.
+func resourceManagerTagsField() tagField {
+	return tagField{
+		get: func(value map[string]interface{}) map[string]string {
+			tags := make(map[string]string)
+			existing, _, _ := unstructured.NestedSlice(value, "resourceManagerTags")
+			for _, item := range existing {
+				if entry, ok := item.(map[string]interface{}); ok {
+					parent, _ := entry["parentID"].(string)
+					k, _ := entry["key"].(string)
+					v, _ := entry["value"].(string)
+					tags[parent+"/"+k] = v
+				}
+			}
+			return tags
+		},
+		set: func(value map[string]interface{}, tags map[string]string) {
+			existing, _, _ := unstructured.NestedSlice(value, "resourceManagerTags")
+			seen := make(map[string]bool)
+			for _, item := range existing {
+				if entry, ok := item.(map[string]interface{}); ok {
+					parent, _ := entry["parentID"].(string)
+					k, _ := entry["key"].(string)
+					if v, ok := tags[parent+"/"+k]; ok {
+						entry["value"] = v
+					}
+					seen[parent+"/"+k] = true
+				}
+			}
+			for _, namespaced := range sortedKeys(tags) {
+				if seen[namespaced] {
+					continue
+				}
+				parent, k, _ := strings.Cut(namespaced, "/")
+				existing = append(existing, map[string]interface{}{
+					"parentID": parent,
+					"key":      k,
+					"value":    tags[namespaced],
+				})
+			}
+			_ = unstructured.SetNestedSlice(value, existing, "resourceManagerTags")
+		},
+	}
+}
+
+func equalTags(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,73 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUpdateMachineTemplatesControlPlane(t *testing.T) {
+	tags := map[string]string{"Owner": "DevOps"}
+
+	tests := []struct {
+		name             string
+		strategy         string
+		rollControlPlane bool
+		wantSkipped      bool
+	}{
+		{name: "RollingUpdate is skipped", strategy: "RollingUpdate", wantSkipped: true},
+		{name: "default strategy is skipped", wantSkipped: true},
+		{name: "RollingUpdate with opt-in", strategy: "RollingUpdate", rollControlPlane: true},
+		{name: "OnDelete", strategy: "OnDelete"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gvk := schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1", Kind: "ControlPlaneMachineSet"}
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(gvk, meta.RESTScopeNamespace)
+			mapper.Add(schema.GroupVersionKind{Group: "machine.openshift.io", Version: "v1beta1", Kind: "MachineSet"},
+				meta.RESTScopeNamespace)
+
+			cpms := &unstructured.Unstructured{}
+			cpms.SetGroupVersionKind(gvk)
+			cpms.SetNamespace(machineAPINamespace)
+			cpms.SetName("cluster")
+			if tt.strategy != "" {
+				_ = unstructured.SetNestedField(cpms.Object, tt.strategy, "spec", "strategy", "type")
+			}
+			_ = unstructured.SetNestedMap(cpms.Object, map[string]interface{}{"tags": []interface{}{}},
+				"spec", "template", "machines_v1beta1_machine_openshift_io", "spec", "providerSpec", "value")
+			k8sClient := fake.NewClientBuilder().WithScheme(Scheme).WithRESTMapper(mapper).WithObjects(cpms).Build()
+
+			changes, err := UpdateMachineTemplates(context.Background(), k8sClient, infraType.CloudPlatformAWS, tags,
+				false, tt.rollControlPlane, false)
+			if err != nil {
+				t.Fatalf("UpdateMachineTemplates() error = %v", err)
+			}
+			if len(changes) != 1 {
+				t.Fatalf("got %d changes, want 1", len(changes))
+			}
+			if got := changes[0].Skipped != ""; got != tt.wantSkipped {
+				t.Errorf("skipped = %v, want %v", got, tt.wantSkipped)
+			}
+
+			got := &unstructured.Unstructured{}
+			got.SetGroupVersionKind(gvk)
+			if err := k8sClient.Get(context.Background(), client.ObjectKeyFromObject(cpms), got); err != nil {
+				t.Fatal(err)
+			}
+			value, _, _ := unstructured.NestedMap(got.Object,
+				"spec", "template", "machines_v1beta1_machine_openshift_io", "spec", "providerSpec", "value")
+			updated := len(listField("tags", "name", "value").get(value)) > 0
+			if updated == tt.wantSkipped {
+				t.Errorf("updated = %v, want %v", updated, !tt.wantSkipped)
+			}
+		})
+	}
+}
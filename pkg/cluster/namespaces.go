@@ -0,0 +1,171 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// NamespacedResource is a cloud resource created for a namespaced object.
+type NamespacedResource struct {
+	// Resource identifies the cloud resource; only the fields needed to
+	// match it against discovered resources are set.
+	Resource infraType.CloudResource
+	// Namespace and Owner are the namespace and the PersistentVolumeClaim or
+	// Service the resource was created for, e.g. "PersistentVolumeClaim/db".
+	Namespace string
+	Owner     string
+}
+
+// LoadBalancerFinder returns the load balancer behind a Service ingress
+// hostname or IP, or nil when there is none.
+type LoadBalancerFinder func(hostname, ip string) (*infraType.CloudResource, error)
+
+// ListNamespacedResources maps the disks of bound CSI PersistentVolumes and
+// the load balancers of LoadBalancer Services to their namespaces.
+func ListNamespacedResources(ctx context.Context, k8sClient client.Client,
+	findLoadBalancer LoadBalancerFinder) ([]NamespacedResource, error) {
+
+	var namespaced []NamespacedResource
+
+	var pvs corev1.PersistentVolumeList
+	if err := k8sClient.List(ctx, &pvs); err != nil {
+		return nil, fmt.Errorf("failed to list PersistentVolumes: %w", err)
+	}
+	for i := range pvs.Items {
+		pv := &pvs.Items[i]
+		if pv.Spec.ClaimRef == nil {
+			continue
+		}
+		res, ok := ResourceFromVolume(pv)
+		if !ok {
+			continue
+		}
+		namespaced = append(namespaced, NamespacedResource{
+			Resource:  res,
+			Namespace: pv.Spec.ClaimRef.Namespace,
+			Owner:     "PersistentVolumeClaim/" + pv.Spec.ClaimRef.Name,
+		})
+	}
+
+	var services corev1.ServiceList
+	if err := k8sClient.List(ctx, &services); err != nil {
+		return nil, fmt.Errorf("failed to list Services: %w", err)
+	}
+	for _, svc := range services.Items {
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+		for _, ingress := range svc.Status.LoadBalancer.Ingress {
+			res, err := findLoadBalancer(ingress.Hostname, ingress.IP)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find the load balancer of Service %s/%s: %w",
+					svc.Namespace, svc.Name, err)
+			}
+			if res == nil {
+				continue
+			}
+			namespaced = append(namespaced, NamespacedResource{
+				Resource:  *res,
+				Namespace: svc.Namespace,
+				Owner:     "Service/" + svc.Name,
+			})
+		}
+	}
+
+	return namespaced, nil
+}
+
+// NamespaceTags returns the values of the keys set on the namespace. Labels
+// are read first and annotations override them, since annotations also allow
+// values that are not valid label values.
+func NamespaceTags(ns *corev1.Namespace, keys []string) map[string]string {
+	tags := make(map[string]string)
+	for _, k := range keys {
+		if v, ok := ns.Labels[k]; ok {
+			tags[k] = v
+		}
+		if v, ok := ns.Annotations[k]; ok {
+			tags[k] = v
+		}
+	}
+	return tags
+}
+
+// ListNamespaceTags returns NamespaceTags for every namespace that sets at
+// least one of the keys.
+func ListNamespaceTags(ctx context.Context, k8sClient client.Client, keys []string) (map[string]map[string]string, error) {
+	var namespaces corev1.NamespaceList
+	if err := k8sClient.List(ctx, &namespaces); err != nil {
+		return nil, fmt.Errorf("failed to list Namespaces: %w", err)
+	}
+
+	tagsByNamespace := make(map[string]map[string]string)
+	for i := range namespaces.Items {
+		if tags := NamespaceTags(&namespaces.Items[i], keys); len(tags) > 0 {
+			tagsByNamespace[namespaces.Items[i].Name] = tags
+		}
+	}
+	return tagsByNamespace, nil
+}
+
+// SameResource reports whether two resources of the same type are the same.
+// Resources resolved from Kubernetes objects carry an ID, or for GCP a name
+// and location, while discovered resources carry all of them.
+func SameResource(a, b infraType.CloudResource) bool {
+	if a.Type != b.Type {
+		return false
+	}
+	if a.ID != "" && b.ID != "" {
+		// Azure resource IDs are case-insensitive.
+		return strings.EqualFold(a.ID, b.ID)
+	}
+	return a.Name != "" && a.Name == b.Name && a.Location == b.Location
+}
+
+// ResourceGroup is a set of resources that get the same tags.
+type ResourceGroup struct {
+	Resources []infraType.CloudResource
+	Tags      map[string]string
+}
+
+// GroupByNamespaceTags splits the resources into the ones created for a
+// namespace with tags, which get the namespace's tags on top of the
+// cluster-wide ones, and the rest, which get the cluster-wide tags. The
+// first group always holds the latter.
+func GroupByNamespaceTags(resources []infraType.CloudResource, namespaced []NamespacedResource,
+	tagsByNamespace map[string]map[string]string, tags map[string]string) []ResourceGroup {
+
+	groups := []ResourceGroup{{Tags: tags}}
+	index := make(map[string]int)
+
+	for _, res := range resources {
+		var nsTags map[string]string
+		for _, n := range namespaced {
+			if SameResource(n.Resource, res) {
+				nsTags = tagsByNamespace[n.Namespace]
+				break
+			}
+		}
+		if len(nsTags) == 0 {
+			groups[0].Resources = append(groups[0].Resources, res)
+			continue
+		}
+
+		merged := mergeMaps(tags, nsTags)
+		key := formatKeyValueList(nsTags)
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, ResourceGroup{Tags: merged})
+		}
+		groups[i].Resources = append(groups[i].Resources, res)
+	}
+	return groups
+}
@@ -0,0 +1,91 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func TestNamespaceTags(t *testing.T) {
+	ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Labels:      map[string]string{"cost-center": "1234", "team": "db", "other": "x"},
+		Annotations: map[string]string{"team": "Database Team"},
+	}}
+
+	tests := []struct {
+		keys []string
+		want map[string]string
+	}{
+		{keys: []string{"cost-center"}, want: map[string]string{"cost-center": "1234"}},
+		{keys: []string{"team"}, want: map[string]string{"team": "Database Team"}},
+		{keys: []string{"missing"}, want: map[string]string{}},
+		{keys: nil, want: map[string]string{}},
+	}
+	for _, tt := range tests {
+		if got := NamespaceTags(ns, tt.keys); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("NamespaceTags(%v) = %v, want %v", tt.keys, got, tt.want)
+		}
+	}
+}
+
+func TestSameResource(t *testing.T) {
+	disk := infraType.CloudResource{Type: infraType.CloudResourceTypeGCPDisk, Name: "pvc-1", Location: "us-central1-a"}
+
+	tests := []struct {
+		name string
+		a, b infraType.CloudResource
+		want bool
+	}{
+		{
+			name: "Azure IDs ignore case",
+			a:    infraType.CloudResource{Type: infraType.CloudResourceTypeAzureManagedDisk, ID: "/subscriptions/s/resourceGroups/RG/disks/d"},
+			b:    infraType.CloudResource{Type: infraType.CloudResourceTypeAzureManagedDisk, ID: "/subscriptions/s/resourcegroups/rg/disks/d"},
+			want: true,
+		},
+		{
+			name: "different types",
+			a:    infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEBSVolume, ID: "vol-1"},
+			b:    infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEC2Instance, ID: "vol-1"},
+		},
+		{name: "GCP name and location", a: disk, b: disk, want: true},
+		{
+			name: "GCP other zone",
+			a:    disk,
+			b:    infraType.CloudResource{Type: infraType.CloudResourceTypeGCPDisk, Name: "pvc-1", Location: "us-central1-b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SameResource(tt.a, tt.b); got != tt.want {
+				t.Errorf("SameResource() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupByNamespaceTags(t *testing.T) {
+	vol := func(id string) infraType.CloudResource {
+		return infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEBSVolume, ID: id}
+	}
+	resources := []infraType.CloudResource{vol("vol-1"), vol("vol-2"), vol("vol-3"), vol("vol-4")}
+	namespaced := []NamespacedResource{
+		{Resource: vol("vol-2"), Namespace: "db"},
+		{Resource: vol("vol-3"), Namespace: "web"},
+		{Resource: vol("vol-4"), Namespace: "db"},
+	}
+	tagsByNamespace := map[string]map[string]string{"db": {"team": "db"}}
+	tags := map[string]string{"Owner": "DevOps"}
+
+	want := []ResourceGroup{
+		{Resources: []infraType.CloudResource{vol("vol-1"), vol("vol-3")}, Tags: tags},
+		{Resources: []infraType.CloudResource{vol("vol-2"), vol("vol-4")},
+			Tags: map[string]string{"Owner": "DevOps", "team": "db"}},
+	}
+	if got := GroupByNamespaceTags(resources, namespaced, tagsByNamespace, tags); !reflect.DeepEqual(got, want) {
+		t.Errorf("GroupByNamespaceTags() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,268 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// OfflineCluster identifies a cluster without its API server, from the
+// metadata.json openshift-install writes to the install directory or from
+// explicit values.
+type OfflineCluster struct {
+	Platform infraType.CloudPlatform
+	InfraID  string
+	// Region is the AWS, GCP, IBM Cloud or PowerVS region.
+	Region string
+	// ResourceGroup is the Azure, IBM Cloud or PowerVS resource group.
+	ResourceGroup string
+	// Project and NetworkProject are the GCP project and shared-VPC host
+	// project.
+	Project        string
+	NetworkProject string
+	// CloudName is the clouds.yaml entry of an OpenStack cluster.
+	CloudName string
+	// Server and Port locate the vCenter or Prism Central.
+	Server string
+	Port   int32
+}
+
+// installMetadata is the part of openshift-install's metadata.json the
+// resource discovery needs.
+type installMetadata struct {
+	InfraID string `json:"infraID"`
+	AWS     *struct {
+		Region string `json:"region"`
+	} `json:"aws"`
+	Azure *struct {
+		Region            string `json:"region"`
+		ResourceGroupName string `json:"resourceGroupName"`
+	} `json:"azure"`
+	GCP *struct {
+		Region           string `json:"region"`
+		ProjectID        string `json:"projectID"`
+		NetworkProjectID string `json:"networkProjectID"`
+	} `json:"gcp"`
+	IBMCloud *struct {
+		Region            string `json:"region"`
+		ResourceGroupName string `json:"resourceGroupName"`
+	} `json:"ibmcloud"`
+	PowerVS *struct {
+		Region               string `json:"region"`
+		PowerVSResourceGroup string `json:"powerVSResourceGroup"`
+	} `json:"powervs"`
+	OpenStack *struct {
+		Cloud string `json:"cloud"`
+	} `json:"openstack"`
+	VSphere *struct {
+		VCenter  string `json:"vCenter"`
+		VCenters []struct {
+			VCenter string `json:"vCenter"`
+		} `json:"vCenters"`
+	} `json:"vsphere"`
+	Nutanix *struct {
+		PrismCentral string `json:"prismCentral"`
+		Port         string `json:"port"`
+	} `json:"nutanix"`
+}
+
+// LoadMetadata reads the cluster identity from an openshift-install
+// metadata.json.
+func LoadMetadata(path string) (*OfflineCluster, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+	var m installMetadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata %s: %w", path, err)
+	}
+
+	c := &OfflineCluster{InfraID: m.InfraID}
+	switch {
+	case m.AWS != nil:
+		c.Platform = infraType.CloudPlatformAWS
+		c.Region = m.AWS.Region
+	case m.Azure != nil:
+		c.Platform = infraType.CloudPlatformAzure
+		c.Region = m.Azure.Region
+		c.ResourceGroup = m.Azure.ResourceGroupName
+	case m.GCP != nil:
+		c.Platform = infraType.CloudPlatformGCP
+		c.Region = m.GCP.Region
+		c.Project = m.GCP.ProjectID
+		c.NetworkProject = m.GCP.NetworkProjectID
+	case m.IBMCloud != nil:
+		c.Platform = infraType.CloudPlatformIBM
+		c.Region = m.IBMCloud.Region
+		c.ResourceGroup = m.IBMCloud.ResourceGroupName
+	case m.PowerVS != nil:
+		c.Platform = infraType.CloudPlatformPowerVS
+		c.Region = m.PowerVS.Region
+		c.ResourceGroup = m.PowerVS.PowerVSResourceGroup
+	case m.OpenStack != nil:
+		c.Platform = infraType.CloudPlatformOpenStack
+		c.CloudName = m.OpenStack.Cloud
+	case m.VSphere != nil:
+		c.Platform = infraType.CloudPlatformVSphere
+		c.Server = m.VSphere.VCenter
+		if len(m.VSphere.VCenters) > 0 {
+			c.Server = m.VSphere.VCenters[0].VCenter
+		}
+	case m.Nutanix != nil:
+		c.Platform = infraType.CloudPlatformNutanix
+		c.Server = m.Nutanix.PrismCentral
+		if m.Nutanix.Port != "" {
+			port, err := strconv.ParseInt(m.Nutanix.Port, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Prism Central port %q in %s: %w", m.Nutanix.Port, path, err)
+			}
+			c.Port = int32(port)
+		}
+	default:
+		return nil, fmt.Errorf("metadata %s does not describe a supported platform", path)
+	}
+	return c, nil
+}
+
+// ParsePlatform returns the platform for a --platform value such as "aws"
+// or "vsphere".
+func ParsePlatform(name string) (infraType.CloudPlatform, error) {
+	for _, p := range []infraType.CloudPlatform{
+		infraType.CloudPlatformAWS, infraType.CloudPlatformAzure, infraType.CloudPlatformGCP,
+		infraType.CloudPlatformIBM, infraType.CloudPlatformPowerVS, infraType.CloudPlatformOpenStack,
+		infraType.CloudPlatformVSphere, infraType.CloudPlatformNutanix,
+	} {
+		if strings.EqualFold(name, string(p)) {
+			return p, nil
+		}
+	}
+	return infraType.CloudPlatformUnknown, fmt.Errorf("%w: %s", infraType.ErrUnsupportedPlatform, name)
+}
+
+// Infrastructure returns the Infrastructure object the cluster would have,
+// with the fields the provider packages read.
+func (c *OfflineCluster) Infrastructure() (*configv1.Infrastructure, error) {
+	if c.InfraID == "" {
+		return nil, fmt.Errorf("the infraID is required")
+	}
+
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{InfrastructureName: c.InfraID},
+	}
+	status := &configv1.PlatformStatus{}
+	switch c.Platform {
+	case infraType.CloudPlatformAWS:
+		status.Type = configv1.AWSPlatformType
+		status.AWS = &configv1.AWSPlatformStatus{Region: c.Region}
+	case infraType.CloudPlatformAzure:
+		// The installer names the cluster resource group after the infraID
+		// unless an existing one was given.
+		resourceGroup := c.ResourceGroup
+		if resourceGroup == "" {
+			resourceGroup = c.InfraID + "-rg"
+		}
+		status.Type = configv1.AzurePlatformType
+		status.Azure = &configv1.AzurePlatformStatus{ResourceGroupName: resourceGroup}
+	case infraType.CloudPlatformGCP:
+		if c.Project == "" {
+			return nil, fmt.Errorf("the GCP project is required")
+		}
+		status.Type = configv1.GCPPlatformType
+		status.GCP = &configv1.GCPPlatformStatus{ProjectID: c.Project, Region: c.Region}
+	case infraType.CloudPlatformIBM:
+		if c.Region == "" || c.ResourceGroup == "" {
+			return nil, fmt.Errorf("the IBM Cloud region and resource group are required")
+		}
+		status.Type = configv1.IBMCloudPlatformType
+		status.IBMCloud = &configv1.IBMCloudPlatformStatus{Location: c.Region, ResourceGroupName: c.ResourceGroup}
+	case infraType.CloudPlatformPowerVS:
+		if c.Region == "" || c.ResourceGroup == "" {
+			return nil, fmt.Errorf("the PowerVS region and resource group are required")
+		}
+		status.Type = configv1.PowerVSPlatformType
+		status.PowerVS = &configv1.PowerVSPlatformStatus{Region: c.Region, ResourceGroup: c.ResourceGroup}
+	case infraType.CloudPlatformOpenStack:
+		status.Type = configv1.OpenStackPlatformType
+		status.OpenStack = &configv1.OpenStackPlatformStatus{CloudName: c.CloudName}
+	case infraType.CloudPlatformVSphere:
+		status.Type = configv1.VSpherePlatformType
+		infra.Spec.PlatformSpec.VSphere = &configv1.VSpherePlatformSpec{}
+		if c.Server != "" {
+			infra.Spec.PlatformSpec.VSphere.VCenters = []configv1.VSpherePlatformVCenterSpec{{Server: c.Server}}
+		}
+	case infraType.CloudPlatformNutanix:
+		status.Type = configv1.NutanixPlatformType
+		infra.Spec.PlatformSpec.Nutanix = &configv1.NutanixPlatformSpec{
+			PrismCentral: configv1.NutanixPrismEndpoint{Address: c.Server, Port: c.Port},
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", infraType.ErrUnsupportedPlatform, c.Platform)
+	}
+	infra.Spec.PlatformSpec.Type = status.Type
+	infra.Status.PlatformStatus = status
+	return infra, nil
+}
+
+// errOffline is returned for every write to an offline client.
+var errOffline = errors.New("the cluster API is not used in offline mode")
+
+// NewOfflineClient returns an in-memory client that serves the cluster's
+// Infrastructure and, for GCP, its install-config, so the provider packages
+// discover resources without an API server. Other objects are not found and
+// writes fail.
+func NewOfflineClient(c *OfflineCluster) (client.Client, error) {
+	infra, err := c.Infrastructure()
+	if err != nil {
+		return nil, err
+	}
+
+	objs := []client.Object{infra}
+	if c.Platform == infraType.CloudPlatformGCP {
+		objs = append(objs, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "cluster-config-v1"},
+			Data: map[string]string{
+				"install-config": fmt.Sprintf("platform:\n  gcp:\n    networkProjectID: %q\n", c.NetworkProject),
+			},
+		})
+	}
+
+	return fake.NewClientBuilder().
+		WithScheme(Scheme).
+		WithObjects(objs...).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(context.Context, client.WithWatch, client.Object, ...client.CreateOption) error {
+				return errOffline
+			},
+			Update: func(context.Context, client.WithWatch, client.Object, ...client.UpdateOption) error {
+				return errOffline
+			},
+			Patch: func(context.Context, client.WithWatch, client.Object, client.Patch, ...client.PatchOption) error {
+				return errOffline
+			},
+			Delete: func(context.Context, client.WithWatch, client.Object, ...client.DeleteOption) error {
+				return errOffline
+			},
+			SubResourceUpdate: func(context.Context, client.Client, string, client.Object, ...client.SubResourceUpdateOption) error {
+				return errOffline
+			},
+			SubResourcePatch: func(context.Context, client.Client, string, client.Object, client.Patch, ...client.SubResourcePatchOption) error {
+				return errOffline
+			},
+		}).
+		Build(), nil
+}
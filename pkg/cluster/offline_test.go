@@ -0,0 +1,180 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func TestParsePlatform(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    infraType.CloudPlatform
+		wantErr bool
+	}{
+		{name: "aws", want: infraType.CloudPlatformAWS},
+		{name: "AWS", want: infraType.CloudPlatformAWS},
+		{name: "azure", want: infraType.CloudPlatformAzure},
+		{name: "gcp", want: infraType.CloudPlatformGCP},
+		{name: "ibm", want: infraType.CloudPlatformIBM},
+		{name: "powervs", want: infraType.CloudPlatformPowerVS},
+		{name: "openstack", want: infraType.CloudPlatformOpenStack},
+		{name: "vsphere", want: infraType.CloudPlatformVSphere},
+		{name: "nutanix", want: infraType.CloudPlatformNutanix},
+		{name: "baremetal", want: infraType.CloudPlatformUnknown, wantErr: true},
+		{name: "", want: infraType.CloudPlatformUnknown, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePlatform(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePlatform() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, infraType.ErrUnsupportedPlatform) {
+				t.Errorf("ParsePlatform() error = %v, want ErrUnsupportedPlatform", err)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePlatform() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadMetadata(t *testing.T) {
+	tests := []struct {
+		name     string
+		metadata string
+		want     *OfflineCluster
+		wantErr  bool
+	}{
+		{
+			name:     "AWS",
+			metadata: `{"infraID": "mycluster-x7k2p", "aws": {"region": "us-east-1"}}`,
+			want:     &OfflineCluster{Platform: infraType.CloudPlatformAWS, InfraID: "mycluster-x7k2p", Region: "us-east-1"},
+		},
+		{
+			name:     "Azure",
+			metadata: `{"infraID": "mycluster-x7k2p", "azure": {"region": "eastus", "resourceGroupName": "existing-rg"}}`,
+			want: &OfflineCluster{Platform: infraType.CloudPlatformAzure, InfraID: "mycluster-x7k2p", Region: "eastus",
+				ResourceGroup: "existing-rg"},
+		},
+		{
+			name:     "GCP shared VPC",
+			metadata: `{"infraID": "mycluster-x7k2p", "gcp": {"region": "us-central1", "projectID": "p", "networkProjectID": "host"}}`,
+			want: &OfflineCluster{Platform: infraType.CloudPlatformGCP, InfraID: "mycluster-x7k2p", Region: "us-central1",
+				Project: "p", NetworkProject: "host"},
+		},
+		{
+			name:     "vSphere with several vCenters",
+			metadata: `{"infraID": "mycluster-x7k2p", "vsphere": {"vCenter": "old", "vCenters": [{"vCenter": "vc1"}, {"vCenter": "vc2"}]}}`,
+			want:     &OfflineCluster{Platform: infraType.CloudPlatformVSphere, InfraID: "mycluster-x7k2p", Server: "vc1"},
+		},
+		{
+			name:     "Nutanix",
+			metadata: `{"infraID": "mycluster-x7k2p", "nutanix": {"prismCentral": "pc.example.com", "port": "9440"}}`,
+			want: &OfflineCluster{Platform: infraType.CloudPlatformNutanix, InfraID: "mycluster-x7k2p",
+				Server: "pc.example.com", Port: 9440},
+		},
+		{
+			name:     "invalid Nutanix port",
+			metadata: `{"infraID": "mycluster-x7k2p", "nutanix": {"prismCentral": "pc", "port": "https"}}`,
+			wantErr:  true,
+		},
+		{name: "no platform", metadata: `{"infraID": "mycluster-x7k2p", "baremetal": {}}`, wantErr: true},
+		{name: "not JSON", metadata: `infraID: mycluster`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "metadata.json")
+			if err := os.WriteFile(path, []byte(tt.metadata), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			got, err := LoadMetadata(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadMetadata() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadMetadata() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOfflineInfrastructure(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster OfflineCluster
+		check   func(t *testing.T, infra *configv1.Infrastructure)
+		wantErr bool
+	}{
+		{
+			name:    "Azure resource group defaults to the infraID",
+			cluster: OfflineCluster{Platform: infraType.CloudPlatformAzure, InfraID: "mycluster-x7k2p"},
+			check: func(t *testing.T, infra *configv1.Infrastructure) {
+				if got := infra.Status.PlatformStatus.Azure.ResourceGroupName; got != "mycluster-x7k2p-rg" {
+					t.Errorf("resource group = %q, want mycluster-x7k2p-rg", got)
+				}
+			},
+		},
+		{
+			name:    "AWS region",
+			cluster: OfflineCluster{Platform: infraType.CloudPlatformAWS, InfraID: "mycluster-x7k2p", Region: "eu-west-1"},
+			check: func(t *testing.T, infra *configv1.Infrastructure) {
+				if got := infra.Status.PlatformStatus.AWS.Region; got != "eu-west-1" {
+					t.Errorf("region = %q, want eu-west-1", got)
+				}
+			},
+		},
+		{name: "no infraID", cluster: OfflineCluster{Platform: infraType.CloudPlatformAWS}, wantErr: true},
+		{name: "GCP without project", cluster: OfflineCluster{Platform: infraType.CloudPlatformGCP, InfraID: "x"}, wantErr: true},
+		{name: "IBM without resource group", cluster: OfflineCluster{Platform: infraType.CloudPlatformIBM, InfraID: "x", Region: "us-south"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infra, err := tt.cluster.Infrastructure()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Infrastructure() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got := Platform(infra); got != tt.cluster.Platform {
+				t.Errorf("Platform() = %q, want %q", got, tt.cluster.Platform)
+			}
+			if got := infra.Status.InfrastructureName; got != tt.cluster.InfraID {
+				t.Errorf("InfrastructureName = %q, want %q", got, tt.cluster.InfraID)
+			}
+			tt.check(t, infra)
+		})
+	}
+}
+
+func TestOfflineClientIsReadOnly(t *testing.T) {
+	k8sClient, err := NewOfflineClient(&OfflineCluster{Platform: infraType.CloudPlatformAWS, InfraID: "mycluster-x7k2p"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	infra := &configv1.Infrastructure{}
+	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "cluster"}, infra); err != nil {
+		t.Fatalf("Get(Infrastructure) error = %v", err)
+	}
+	if err := k8sClient.Update(ctx, infra); !errors.Is(err, errOffline) {
+		t.Errorf("Update() error = %v, want errOffline", err)
+	}
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: LedgerNamespace, Name: LedgerName}}
+	if err := k8sClient.Create(ctx, cm); !errors.Is(err, errOffline) {
+		t.Errorf("Create() error = %v, want errOffline", err)
+	}
+}
@@ -0,0 +1,78 @@
+package cluster
+
+import (
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// Platform returns the cloud platform recorded in the Infrastructure status.
+func Platform(infra *configv1.Infrastructure) infraType.CloudPlatform {
+	if infra.Status.PlatformStatus == nil {
+		return infraType.CloudPlatformUnknown
+	}
+
+	switch infra.Status.PlatformStatus.Type {
+	case configv1.AWSPlatformType:
+		return infraType.CloudPlatformAWS
+	case configv1.AzurePlatformType:
+		return infraType.CloudPlatformAzure
+	case configv1.GCPPlatformType:
+		return infraType.CloudPlatformGCP
+	case configv1.IBMCloudPlatformType:
+		return infraType.CloudPlatformIBM
+	case configv1.OpenStackPlatformType:
+		return infraType.CloudPlatformOpenStack
+	case configv1.VSpherePlatformType:
+		return infraType.CloudPlatformVSphere
+	case configv1.NutanixPlatformType:
+		return infraType.CloudPlatformNutanix
+	case configv1.PowerVSPlatformType:
+		return infraType.CloudPlatformPowerVS
+	default:
+		return infraType.CloudPlatformUnknown
+	}
+}
+
+// UserTags returns the user tags the installer recorded in the
+// Infrastructure status. With tagBindings set, GCP resourceTags are returned
+// keyed by <parentID>/<key> instead of the resourceLabels.
+func UserTags(infra *configv1.Infrastructure, cloudPlatform infraType.CloudPlatform,
+	tagBindings bool) (map[string]string, error) {
+
+	status := infra.Status.PlatformStatus
+	if status == nil {
+		return nil, fmt.Errorf("platform status not found")
+	}
+
+	tags := make(map[string]string)
+	switch cloudPlatform {
+	case infraType.CloudPlatformAWS:
+		if status.AWS != nil {
+			for _, tag := range status.AWS.ResourceTags {
+				tags[tag.Key] = tag.Value
+			}
+		}
+	case infraType.CloudPlatformAzure:
+		if status.Azure != nil {
+			for _, tag := range status.Azure.ResourceTags {
+				tags[tag.Key] = tag.Value
+			}
+		}
+	case infraType.CloudPlatformGCP:
+		if status.GCP != nil && tagBindings {
+			for _, tag := range status.GCP.ResourceTags {
+				tags[tag.ParentID+"/"+tag.Key] = tag.Value
+			}
+		} else if status.GCP != nil {
+			for _, label := range status.GCP.ResourceLabels {
+				tags[label.Key] = label.Value
+			}
+		}
+	default:
+		return nil, fmt.Errorf("the Infrastructure status does not record user tags for platform %s", cloudPlatform)
+	}
+	return tags, nil
+}
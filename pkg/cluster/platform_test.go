@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func TestPlatform(t *testing.T) {
+	tests := []struct {
+		status *configv1.PlatformStatus
+		want   infraType.CloudPlatform
+	}{
+		{status: nil, want: infraType.CloudPlatformUnknown},
+		{status: &configv1.PlatformStatus{Type: configv1.AWSPlatformType}, want: infraType.CloudPlatformAWS},
+		{status: &configv1.PlatformStatus{Type: configv1.AzurePlatformType}, want: infraType.CloudPlatformAzure},
+		{status: &configv1.PlatformStatus{Type: configv1.GCPPlatformType}, want: infraType.CloudPlatformGCP},
+		{status: &configv1.PlatformStatus{Type: configv1.IBMCloudPlatformType}, want: infraType.CloudPlatformIBM},
+		{status: &configv1.PlatformStatus{Type: configv1.PowerVSPlatformType}, want: infraType.CloudPlatformPowerVS},
+		{status: &configv1.PlatformStatus{Type: configv1.BareMetalPlatformType}, want: infraType.CloudPlatformUnknown},
+	}
+	for _, tt := range tests {
+		infra := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{PlatformStatus: tt.status}}
+		if got := Platform(infra); got != tt.want {
+			t.Errorf("Platform(%+v) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestUserTags(t *testing.T) {
+	status := &configv1.PlatformStatus{
+		AWS: &configv1.AWSPlatformStatus{ResourceTags: []configv1.AWSResourceTag{{Key: "Owner", Value: "aws"}}},
+		Azure: &configv1.AzurePlatformStatus{
+			ResourceTags: []configv1.AzureResourceTag{{Key: "Owner", Value: "azure"}},
+		},
+		GCP: &configv1.GCPPlatformStatus{
+			ResourceLabels: []configv1.GCPResourceLabel{{Key: "owner", Value: "gcp"}},
+			ResourceTags:   []configv1.GCPResourceTag{{ParentID: "123456789", Key: "env", Value: "prod"}},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		status      *configv1.PlatformStatus
+		platform    infraType.CloudPlatform
+		tagBindings bool
+		want        map[string]string
+		wantErr     bool
+	}{
+		{name: "AWS", status: status, platform: infraType.CloudPlatformAWS, want: map[string]string{"Owner": "aws"}},
+		{name: "Azure", status: status, platform: infraType.CloudPlatformAzure, want: map[string]string{"Owner": "azure"}},
+		{name: "GCP labels", status: status, platform: infraType.CloudPlatformGCP, want: map[string]string{"owner": "gcp"}},
+		{
+			name:        "GCP tag bindings",
+			status:      status,
+			platform:    infraType.CloudPlatformGCP,
+			tagBindings: true,
+			want:        map[string]string{"123456789/env": "prod"},
+		},
+		{name: "no tags recorded", status: &configv1.PlatformStatus{}, platform: infraType.CloudPlatformAWS, want: map[string]string{}},
+		{name: "unsupported platform", status: status, platform: infraType.CloudPlatformVSphere, wantErr: true},
+		{name: "no platform status", platform: infraType.CloudPlatformAWS, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infra := &configv1.Infrastructure{Status: configv1.InfrastructureStatus{PlatformStatus: tt.status}}
+			got, err := UserTags(infra, tt.platform, tt.tagBindings)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UserTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("UserTags() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package cluster
+
+import (
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// ResourceFromProviderID returns the instance a Node's spec.providerID
+// points at. Only the fields needed to describe it are set.
+func ResourceFromProviderID(providerID string) (infraType.CloudResource, bool) {
+	scheme, path, ok := strings.Cut(providerID, "://")
+	if !ok {
+		return infraType.CloudResource{}, false
+	}
+
+	switch scheme {
+	case "aws":
+		// aws:///<zone>/<instance-id>
+		id := path[strings.LastIndex(path, "/")+1:]
+		if !strings.HasPrefix(id, "i-") {
+			return infraType.CloudResource{}, false
+		}
+		return infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEC2Instance, ID: id}, true
+	case "azure":
+		// azure:///subscriptions/<sub>/resourceGroups/<rg>/providers/Microsoft.Compute/virtualMachines/<name>
+		// Scale set instances are not tagged individually.
+		if !strings.Contains(strings.ToLower(path), "/providers/microsoft.compute/virtualmachines/") {
+			return infraType.CloudResource{}, false
+		}
+		return infraType.CloudResource{Type: infraType.CloudResourceTypeAzureVM, ID: path}, true
+	case "gce":
+		// gce://<project>/<zone>/<name>
+		parts := strings.Split(path, "/")
+		if len(parts) != 3 {
+			return infraType.CloudResource{}, false
+		}
+		return infraType.CloudResource{
+			Type:     infraType.CloudResourceTypeGCPComputeInstance,
+			Project:  parts[0],
+			Location: parts[1],
+			Name:     parts[2],
+		}, true
+	}
+	return infraType.CloudResource{}, false
+}
+
+// ResourceFromVolume returns the disk behind a CSI PersistentVolume. Only the
+// fields needed to describe it are set.
+func ResourceFromVolume(pv *corev1.PersistentVolume) (infraType.CloudResource, bool) {
+	if pv.Spec.CSI == nil {
+		return infraType.CloudResource{}, false
+	}
+
+	handle := pv.Spec.CSI.VolumeHandle
+	switch pv.Spec.CSI.Driver {
+	case "ebs.csi.aws.com":
+		// vol-<id>
+		return infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEBSVolume, ID: handle}, true
+	case "disk.csi.azure.com":
+		// The handle is the ARM ID of the managed disk.
+		return infraType.CloudResource{Type: infraType.CloudResourceTypeAzureManagedDisk, ID: handle}, true
+	case "pd.csi.storage.gke.io":
+		// projects/<project>/zones/<zone>/disks/<name>; regional disks are
+		// not supported by the label update.
+		parts := strings.Split(handle, "/")
+		if len(parts) != 6 || parts[0] != "projects" || parts[2] != "zones" || parts[4] != "disks" {
+			return infraType.CloudResource{}, false
+		}
+		return infraType.CloudResource{
+			Type:     infraType.CloudResourceTypeGCPDisk,
+			Project:  parts[1],
+			Location: parts[3],
+			Name:     parts[5],
+		}, true
+	}
+	return infraType.CloudResource{}, false
+}
@@ -0,0 +1,96 @@
+package cluster
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func TestResourceFromProviderID(t *testing.T) {
+	azureVM := "/subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachines/master-0"
+
+	tests := []struct {
+		providerID string
+		want       infraType.CloudResource
+		wantOK     bool
+	}{
+		{
+			providerID: "aws:///us-east-1a/i-0123456789abcdef0",
+			want:       infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEC2Instance, ID: "i-0123456789abcdef0"},
+			wantOK:     true,
+		},
+		{providerID: "aws:///us-east-1a/fargate-ip-10-0-0-1"},
+		{
+			providerID: "azure://" + azureVM,
+			want:       infraType.CloudResource{Type: infraType.CloudResourceTypeAzureVM, ID: azureVM},
+			wantOK:     true,
+		},
+		{providerID: "azure:///subscriptions/sub/resourceGroups/rg/providers/Microsoft.Compute/virtualMachineScaleSets/workers/virtualMachines/0"},
+		{
+			providerID: "gce://my-project/us-central1-a/mycluster-master-0",
+			want: infraType.CloudResource{
+				Type:     infraType.CloudResourceTypeGCPComputeInstance,
+				Project:  "my-project",
+				Location: "us-central1-a",
+				Name:     "mycluster-master-0",
+			},
+			wantOK: true,
+		},
+		{providerID: "gce://my-project/mycluster-master-0"},
+		{providerID: "vsphere://4237c2a6-5c9a-4e41-bcd6-0e4b6d9c0a1e"},
+		{providerID: "i-0123456789abcdef0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.providerID, func(t *testing.T) {
+			got, ok := ResourceFromProviderID(tt.providerID)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResourceFromProviderID() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestResourceFromVolume(t *testing.T) {
+	tests := []struct {
+		name   string
+		csi    *corev1.CSIPersistentVolumeSource
+		want   infraType.CloudResource
+		wantOK bool
+	}{
+		{
+			name:   "EBS",
+			csi:    &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-0123"},
+			want:   infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEBSVolume, ID: "vol-0123"},
+			wantOK: true,
+		},
+		{
+			name: "GCP zonal disk",
+			csi: &corev1.CSIPersistentVolumeSource{Driver: "pd.csi.storage.gke.io",
+				VolumeHandle: "projects/my-project/zones/us-central1-a/disks/pvc-1"},
+			want: infraType.CloudResource{Type: infraType.CloudResourceTypeGCPDisk, Project: "my-project",
+				Location: "us-central1-a", Name: "pvc-1"},
+			wantOK: true,
+		},
+		{
+			name: "GCP regional disk",
+			csi: &corev1.CSIPersistentVolumeSource{Driver: "pd.csi.storage.gke.io",
+				VolumeHandle: "projects/my-project/regions/us-central1/disks/pvc-1"},
+		},
+		{name: "other driver", csi: &corev1.CSIPersistentVolumeSource{Driver: "nfs.csi.k8s.io", VolumeHandle: "x"}},
+		{name: "not CSI"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pv := &corev1.PersistentVolume{Spec: corev1.PersistentVolumeSpec{
+				PersistentVolumeSource: corev1.PersistentVolumeSource{CSI: tt.csi},
+			}}
+			got, ok := ResourceFromVolume(pv)
+			if ok != tt.wantOK || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ResourceFromVolume() = %+v, %v, want %+v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
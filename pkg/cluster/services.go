@@ -0,0 +1,69 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// loadBalancerTagAnnotations maps platforms to the Service annotation their
+// cloud provider reads extra load balancer tags from.
+var loadBalancerTagAnnotations = map[infraType.CloudPlatform]string{
+	infraType.CloudPlatformAWS:   "service.beta.kubernetes.io/aws-load-balancer-additional-resource-tags",
+	infraType.CloudPlatformAzure: "service.beta.kubernetes.io/azure-pip-tags",
+}
+
+// UpdateServiceAnnotations merges the tags into the load balancer tag
+// annotation of every LoadBalancer Service, including the ingress routers,
+// so the cloud provider tags the load balancers it manages for them.
+func UpdateServiceAnnotations(ctx context.Context, k8sClient client.Client, cloudPlatform infraType.CloudPlatform,
+	tags map[string]string, dryRun bool) ([]ObjectChange, error) {
+
+	annotation, ok := loadBalancerTagAnnotations[cloudPlatform]
+	if !ok {
+		return nil, fmt.Errorf("load balancer tag annotations are not supported for platform %s", cloudPlatform)
+	}
+
+	var list corev1.ServiceList
+	if err := k8sClient.List(ctx, &list); err != nil {
+		return nil, fmt.Errorf("failed to list Services: %w", err)
+	}
+
+	var changes []ObjectChange
+	for i := range list.Items {
+		svc := &list.Items[i]
+		if svc.Spec.Type != corev1.ServiceTypeLoadBalancer {
+			continue
+		}
+
+		current := parseKeyValueList(svc.Annotations[annotation])
+		merged := mergeMaps(current, tags)
+		if equalTags(current, merged) {
+			continue
+		}
+
+		changes = append(changes, ObjectChange{
+			Kind:      "Service",
+			Namespace: svc.Namespace,
+			Name:      svc.Name,
+			Old:       current,
+			New:       merged,
+		})
+		if dryRun {
+			continue
+		}
+
+		if svc.Annotations == nil {
+			svc.Annotations = make(map[string]string)
+		}
+		svc.Annotations[annotation] = formatKeyValueList(merged)
+		if err := k8sClient.Update(ctx, svc); err != nil {
+			return changes, fmt.Errorf("failed to update Service %s/%s: %w", svc.Namespace, svc.Name, err)
+		}
+	}
+	return changes, nil
+}
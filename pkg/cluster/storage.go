@@ -0,0 +1,184 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	storagev1 "k8s.io/api/storage/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const tagSpecificationPrefix = "tagSpecification_"
+
+// storageClassTagParameters maps CSI provisioners to the StorageClass
+// parameter they read extra tags or labels from. EBS uses numbered
+// tagSpecification_N parameters instead and is handled separately.
+var storageClassTagParameters = map[string]string{
+	"disk.csi.azure.com":           "tags",
+	"file.csi.azure.com":           "tags",
+	"pd.csi.storage.gke.io":        "labels",
+	"filestore.csi.storage.gke.io": "labels",
+}
+
+const ebsProvisioner = "ebs.csi.aws.com"
+
+// UpdateStorageClasses merges the tags into the parameters of every
+// StorageClass whose provisioner supports tags, so dynamically provisioned
+// volumes are created with them. StorageClass parameters are immutable, so
+// changed classes are deleted and recreated; existing volumes are not
+// affected. Classes are only recreated with recreate set, otherwise their
+// changes are reported as skipped: the cluster storage operator owns the
+// default classes and may recreate them without the tags.
+func UpdateStorageClasses(ctx context.Context, k8sClient client.Client, tags map[string]string,
+	recreate, dryRun bool) ([]ObjectChange, error) {
+
+	var list storagev1.StorageClassList
+	if err := k8sClient.List(ctx, &list); err != nil {
+		return nil, fmt.Errorf("failed to list StorageClasses: %w", err)
+	}
+
+	var changes []ObjectChange
+	for i := range list.Items {
+		sc := &list.Items[i]
+
+		var current, merged map[string]string
+		params := make(map[string]string)
+		for k, v := range sc.Parameters {
+			params[k] = v
+		}
+
+		if sc.Provisioner == ebsProvisioner {
+			current = parseTagSpecifications(sc.Parameters)
+			merged = mergeMaps(current, tags)
+			setTagSpecifications(params, tags)
+		} else if param, ok := storageClassTagParameters[sc.Provisioner]; ok {
+			current = parseKeyValueList(sc.Parameters[param])
+			merged = mergeMaps(current, tags)
+			params[param] = formatKeyValueList(merged)
+		} else {
+			continue
+		}
+
+		if equalTags(current, merged) {
+			continue
+		}
+
+		change := ObjectChange{
+			Kind:    "StorageClass",
+			Name:    sc.Name,
+			Old:     current,
+			New:     merged,
+			Warning: "StorageClass parameters are immutable, the class is recreated",
+		}
+		if !recreate {
+			change.Warning = ""
+			change.Skipped = "StorageClass parameters are immutable and recreating the class was not requested"
+		}
+		changes = append(changes, change)
+		if dryRun || !recreate {
+			continue
+		}
+
+		updated := recreatable(sc)
+		updated.Parameters = params
+		if err := k8sClient.Delete(ctx, sc); err != nil {
+			return changes, fmt.Errorf("failed to delete StorageClass %s: %w", sc.Name, err)
+		}
+		if err := k8sClient.Create(ctx, updated); err != nil {
+			// The class must not be left deleted.
+			if restoreErr := k8sClient.Create(ctx, recreatable(sc)); restoreErr != nil {
+				return changes, fmt.Errorf("failed to recreate StorageClass %s: %w, and failed to restore it "+
+					"with its previous parameters %v: %w", sc.Name, err, sc.Parameters, restoreErr)
+			}
+			return changes, fmt.Errorf("failed to recreate StorageClass %s, it was restored: %w", sc.Name, err)
+		}
+	}
+	return changes, nil
+}
+
+// recreatable returns a copy of the StorageClass that can be created again
+// after it was deleted.
+func recreatable(sc *storagev1.StorageClass) *storagev1.StorageClass {
+	c := sc.DeepCopy()
+	c.ResourceVersion = ""
+	c.UID = ""
+	c.CreationTimestamp.Reset()
+	c.ManagedFields = nil
+	return c
+}
+
+// parseTagSpecifications reads EBS tagSpecification_N=key=value parameters.
+func parseTagSpecifications(params map[string]string) map[string]string {
+	tags := make(map[string]string)
+	for k, v := range params {
+		if !strings.HasPrefix(k, tagSpecificationPrefix) {
+			continue
+		}
+		key, value, _ := strings.Cut(v, "=")
+		tags[key] = value
+	}
+	return tags
+}
+
+// setTagSpecifications updates existing tagSpecification_N entries in place
+// and appends new keys after the highest index.
+func setTagSpecifications(params map[string]string, tags map[string]string) {
+	next := 1
+	seen := make(map[string]bool)
+	for k, v := range params {
+		if !strings.HasPrefix(k, tagSpecificationPrefix) {
+			continue
+		}
+		if n, err := strconv.Atoi(strings.TrimPrefix(k, tagSpecificationPrefix)); err == nil && n >= next {
+			next = n + 1
+		}
+		key, _, _ := strings.Cut(v, "=")
+		if value, ok := tags[key]; ok {
+			params[k] = key + "=" + value
+		}
+		seen[key] = true
+	}
+	for _, key := range sortedKeys(tags) {
+		if seen[key] {
+			continue
+		}
+		params[fmt.Sprintf("%s%d", tagSpecificationPrefix, next)] = key + "=" + tags[key]
+		next++
+	}
+}
+
+// parseKeyValueList reads the "k1=v1,k2=v2" format used by StorageClass
+// parameters and Service annotations.
+func parseKeyValueList(s string) map[string]string {
+	tags := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(pair, "=")
+		tags[strings.TrimSpace(key)] = strings.TrimSpace(value)
+	}
+	return tags
+}
+
+func formatKeyValueList(tags map[string]string) string {
+	pairs := make([]string, 0, len(tags))
+	for _, k := range sortedKeys(tags) {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+	return strings.Join(pairs, ",")
+}
+
+func mergeMaps(existing, updates map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range updates {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,92 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestUpdateStorageClasses(t *testing.T) {
+	tags := map[string]string{"Owner": "DevOps"}
+	errCreate := errors.New("create failed")
+
+	tests := []struct {
+		name     string
+		recreate bool
+		dryRun   bool
+		// failCreates is how many creates fail.
+		failCreates int
+		wantErr     bool
+		wantSkipped bool
+		wantParams  map[string]string
+	}{
+		{
+			name:        "skipped without recreate",
+			wantSkipped: true,
+			wantParams:  map[string]string{"type": "gp3"},
+		},
+		{
+			name:       "dry run",
+			recreate:   true,
+			dryRun:     true,
+			wantParams: map[string]string{"type": "gp3"},
+		},
+		{
+			name:       "recreated",
+			recreate:   true,
+			wantParams: map[string]string{"type": "gp3", "tagSpecification_1": "Owner=DevOps"},
+		},
+		{
+			name:        "restored when the create fails",
+			recreate:    true,
+			failCreates: 1,
+			wantErr:     true,
+			wantParams:  map[string]string{"type": "gp3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := &storagev1.StorageClass{
+				ObjectMeta:  metav1.ObjectMeta{Name: "gp3-csi"},
+				Provisioner: ebsProvisioner,
+				Parameters:  map[string]string{"type": "gp3"},
+			}
+			failCreates := tt.failCreates
+			k8sClient := fake.NewClientBuilder().WithScheme(Scheme).WithObjects(sc).
+				WithInterceptorFuncs(interceptor.Funcs{
+					Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+						if failCreates > 0 {
+							failCreates--
+							return errCreate
+						}
+						return c.Create(ctx, obj, opts...)
+					},
+				}).Build()
+
+			changes, err := UpdateStorageClasses(context.Background(), k8sClient, tags, tt.recreate, tt.dryRun)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateStorageClasses() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(changes) != 1 {
+				t.Fatalf("got %d changes, want 1", len(changes))
+			}
+			if got := changes[0].Skipped != ""; got != tt.wantSkipped {
+				t.Errorf("skipped = %v, want %v", got, tt.wantSkipped)
+			}
+
+			got := &storagev1.StorageClass{}
+			if err := k8sClient.Get(context.Background(), client.ObjectKey{Name: "gp3-csi"}, got); err != nil {
+				t.Fatalf("StorageClass not found: %v", err)
+			}
+			if !equalTags(got.Parameters, tt.wantParams) {
+				t.Errorf("parameters = %v, want %v", got.Parameters, tt.wantParams)
+			}
+		})
+	}
+}
@@ -0,0 +1,436 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/api/v1alpha1"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// removeTagsFinalizer keeps a policy with RemovalPolicy Delete around until
+// the tags it applied are removed.
+const removeTagsFinalizer = "metadatamanager.openshift.io/remove-tags"
+
+const defaultInterval = 30 * time.Minute
+
+// Tagger discovers the cluster's cloud resources and writes their tags. The
+// CLI implements it on top of the provider packages. ApplyTags and
+// RemoveTags record each resource they write with infraType.RecordWrite, so
+// the resources written before a failure are still recorded in the ledger.
+type Tagger interface {
+	ListResources(ctx context.Context, cloudPlatform infraType.CloudPlatform) ([]infraType.CloudResource, error)
+	// ApplyTags merges the tags into the existing tags of the resources.
+	ApplyTags(ctx context.Context, cloudPlatform infraType.CloudPlatform, resources []infraType.CloudResource, tags map[string]string) error
+	// RemoveTags deletes the keys from the resources.
+	RemoveTags(ctx context.Context, cloudPlatform infraType.CloudPlatform, resources []infraType.CloudResource, keys []string) error
+}
+
+// PolicyReconciler syncs the cloud resources of the cluster with every
+// ClusterMetadataPolicy, on the policy's interval and whenever the policy or
+// the Infrastructure object changes.
+type PolicyReconciler struct {
+	client.Client
+	Tagger Tagger
+	// Resolver maps load balancers to Services for NamespaceTagKeys.
+	Resolver Resolver
+}
+
+// SetupWithManager registers the reconciler and its watches.
+func (r *PolicyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ClusterMetadataPolicy{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&configv1.Infrastructure{}, handler.EnqueueRequestsFromMapFunc(r.policiesForInfrastructure)).
+		Complete(r)
+}
+
+// policiesForInfrastructure requeues every policy when the Infrastructure
+// object changes, since it holds the platform and the cluster's user tags.
+func (r *PolicyReconciler) policiesForInfrastructure(ctx context.Context, _ client.Object) []reconcile.Request {
+	var policies v1alpha1.ClusterMetadataPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		log.FromContext(ctx).Error(err, "failed to list ClusterMetadataPolicies")
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(policies.Items))
+	for _, policy := range policies.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&policy)})
+	}
+	return requests
+}
+
+func (r *PolicyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	policy := &v1alpha1.ClusterMetadataPolicy{}
+	if err := r.Get(ctx, req.NamespacedName, policy); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if !policy.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, r.finalize(ctx, policy)
+	}
+
+	// The finalizer is only needed to remove tags on deletion.
+	wantFinalizer := policy.Spec.RemovalPolicy == v1alpha1.RemovalPolicyDelete
+	if wantFinalizer != controllerutil.ContainsFinalizer(policy, removeTagsFinalizer) {
+		if wantFinalizer {
+			controllerutil.AddFinalizer(policy, removeTagsFinalizer)
+		} else {
+			controllerutil.RemoveFinalizer(policy, removeTagsFinalizer)
+		}
+		if err := r.Update(ctx, policy); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	summary, desiredKeys, syncErr := r.sync(ctx, policy)
+
+	now := metav1.Now()
+	condition := metav1.Condition{
+		Type:               v1alpha1.ConditionSynced,
+		ObservedGeneration: policy.Generation,
+	}
+	if syncErr != nil {
+		logger.Error(syncErr, "sync failed", "policy", policy.Name)
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SyncFailed"
+		condition.Message = syncErr.Error()
+		// Keep the keys of the previous sync so they can still be removed
+		// once a later sync gets through.
+		policy.Status.ManagedKeys = union(policy.Status.ManagedKeys, desiredKeys)
+	} else {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "SyncSucceeded"
+		condition.Message = fmt.Sprintf("%d of %d resources updated", summary.Updated, summary.Resources)
+		policy.Status.ManagedKeys = desiredKeys
+	}
+	meta.SetStatusCondition(&policy.Status.Conditions, condition)
+	policy.Status.ObservedGeneration = policy.Generation
+	policy.Status.LastSyncTime = &now
+	policy.Status.LastSyncSummary = summary
+
+	if err := r.Status().Update(ctx, policy); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: interval(policy)}, nil
+}
+
+// sync applies the policy to the cluster's resources and returns the
+// summary and the sorted keys of the desired tags. The keys the policy adds
+// to a resource are recorded in the policy's ledger, see cluster.Ledger, and
+// only those are removed under RemovalPolicy Delete.
+func (r *PolicyReconciler) sync(ctx context.Context,
+	policy *v1alpha1.ClusterMetadataPolicy) (summary *v1alpha1.SyncSummary, desiredKeys []string, err error) {
+
+	summary = &v1alpha1.SyncSummary{}
+
+	cloudPlatform, resources, err := r.discover(ctx, policy)
+	if err != nil {
+		return summary, nil, err
+	}
+	summary.Resources = len(resources)
+
+	desired, err := DesiredTags(ctx, r.Client, policy, cloudPlatform)
+	if err != nil {
+		return summary, nil, err
+	}
+	desiredKeys = sortedKeys(desired)
+
+	ledgerName := cluster.PolicyLedgerName(policy.Name)
+	ledger, err := cluster.ReadLedger(ctx, r.Client, ledgerName)
+	if err != nil {
+		return summary, desiredKeys, err
+	}
+	// Changes are made to the ledger read above, which decides what is
+	// pruned, and replayed on the stored ledger when it is written, since
+	// ResourceReconciler records the resources it tags in it too.
+	var changes []func(*cluster.Ledger)
+	change := func(update func(*cluster.Ledger)) {
+		update(ledger)
+		changes = append(changes, update)
+	}
+	defer func() {
+		if len(changes) == 0 {
+			return
+		}
+		writeErr := cluster.UpdateLedger(ctx, r.Client, ledgerName, func(l *cluster.Ledger) {
+			for _, update := range changes {
+				update(l)
+			}
+		})
+		if writeErr != nil {
+			err = errors.Join(err, writeErr)
+		}
+	}()
+
+	groups := []cluster.ResourceGroup{{Resources: resources, Tags: desired}}
+	if len(policy.Spec.NamespaceTagKeys) > 0 {
+		groups, err = r.groupByNamespace(ctx, cloudPlatform, resources, desired, policy.Spec.NamespaceTagKeys)
+		if err != nil {
+			return summary, desiredKeys, err
+		}
+	}
+
+	for _, group := range groups {
+		var drifted []infraType.CloudResource
+		for _, res := range group.Resources {
+			if needsTags(res.Tags, group.Tags) {
+				drifted = append(drifted, res)
+			} else {
+				summary.InSync++
+			}
+		}
+		if len(drifted) == 0 {
+			continue
+		}
+
+		progress := &infraType.Progress{}
+		applyErr := r.Tagger.ApplyTags(infraType.WithProgress(ctx, progress), cloudPlatform, drifted, group.Tags)
+		tagged := written(drifted, progress, applyErr)
+		summary.Updated += len(tagged)
+		for _, res := range tagged {
+			change(func(l *cluster.Ledger) { l.Own(res, desired) })
+		}
+		if applyErr != nil {
+			summary.Failed += len(drifted) - len(tagged)
+			return summary, desiredKeys, fmt.Errorf("failed to apply tags: %w", applyErr)
+		}
+	}
+
+	if policy.Spec.RemovalPolicy == v1alpha1.RemovalPolicyDelete {
+		// Resources with the same stale keys are pruned together.
+		pruned := make(map[string][]infraType.CloudResource)
+		for _, res := range resources {
+			if stale := ledger.Stale(res, desired); len(stale) > 0 {
+				group := strings.Join(stale, ",")
+				pruned[group] = append(pruned[group], res)
+			}
+		}
+		for _, group := range sortedKeys(groupKeys(pruned)) {
+			stale := strings.Split(group, ",")
+			progress := &infraType.Progress{}
+			removeErr := r.Tagger.RemoveTags(infraType.WithProgress(ctx, progress), cloudPlatform, pruned[group], stale)
+			removed := written(pruned[group], progress, removeErr)
+			summary.Pruned += len(removed)
+			for _, res := range removed {
+				change(func(l *cluster.Ledger) { l.Remove(res, stale) })
+			}
+			if removeErr != nil {
+				summary.Failed += len(pruned[group]) - len(removed)
+				return summary, desiredKeys, fmt.Errorf("failed to remove tags %v: %w", stale, removeErr)
+			}
+		}
+	}
+
+	return summary, desiredKeys, nil
+}
+
+// groupByNamespace splits the resources by the tags of the namespace their
+// PersistentVolumeClaim or Service lives in.
+func (r *PolicyReconciler) groupByNamespace(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	resources []infraType.CloudResource, desired map[string]string, keys []string) ([]cluster.ResourceGroup, error) {
+
+	tagsByNamespace, err := cluster.ListNamespaceTags(ctx, r.Client, keys)
+	if err != nil {
+		return nil, err
+	}
+	namespaced, err := cluster.ListNamespacedResources(ctx, r.Client, func(hostname, ip string) (*infraType.CloudResource, error) {
+		return r.Resolver.FindLoadBalancer(ctx, cloudPlatform, hostname, ip)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return cluster.GroupByNamespaceTags(resources, namespaced, tagsByNamespace, desired), nil
+}
+
+// finalize removes the tags the policy added under RemovalPolicy Delete,
+// as recorded in its ledger, and releases the policy.
+func (r *PolicyReconciler) finalize(ctx context.Context, policy *v1alpha1.ClusterMetadataPolicy) error {
+	if !controllerutil.ContainsFinalizer(policy, removeTagsFinalizer) {
+		return nil
+	}
+
+	name := cluster.PolicyLedgerName(policy.Name)
+	ledger, err := cluster.ReadLedger(ctx, r.Client, name)
+	if err != nil {
+		return err
+	}
+	if len(ledger.Keys) > 0 {
+		cloudPlatform, resources, err := r.discover(ctx, policy)
+		if err != nil {
+			return err
+		}
+		// Resources with the same keys are cleaned up together.
+		owned := make(map[string][]infraType.CloudResource)
+		for _, res := range resources {
+			if keys := ledger.Stale(res, nil); len(keys) > 0 {
+				group := strings.Join(keys, ",")
+				owned[group] = append(owned[group], res)
+			}
+		}
+		for _, group := range sortedKeys(groupKeys(owned)) {
+			if err := r.Tagger.RemoveTags(ctx, cloudPlatform, owned[group], strings.Split(group, ",")); err != nil {
+				return fmt.Errorf("failed to remove tags: %w", err)
+			}
+		}
+	}
+	if err := cluster.DeleteLedger(ctx, r.Client, name); err != nil {
+		return err
+	}
+
+	controllerutil.RemoveFinalizer(policy, removeTagsFinalizer)
+	return r.Update(ctx, policy)
+}
+
+// discover detects the platform and lists the resources the policy selects.
+func (r *PolicyReconciler) discover(ctx context.Context,
+	policy *v1alpha1.ClusterMetadataPolicy) (infraType.CloudPlatform, []infraType.CloudResource, error) {
+
+	infra := &configv1.Infrastructure{}
+	if err := r.Get(ctx, client.ObjectKey{Name: "cluster"}, infra); err != nil {
+		return "", nil, fmt.Errorf("failed to get Infrastructure: %w", err)
+	}
+	cloudPlatform := cluster.Platform(infra)
+
+	resources, err := r.Tagger.ListResources(ctx, cloudPlatform)
+	if err != nil {
+		return cloudPlatform, nil, fmt.Errorf("failed to list %s resources: %w", cloudPlatform, err)
+	}
+	return cloudPlatform, SelectResources(resources, policy.Spec), nil
+}
+
+// DesiredTags returns the policy's tags on top of the cluster's user tags
+// when FromCluster is set.
+func DesiredTags(ctx context.Context, c client.Client, policy *v1alpha1.ClusterMetadataPolicy,
+	cloudPlatform infraType.CloudPlatform) (map[string]string, error) {
+
+	desired := make(map[string]string)
+	if policy.Spec.FromCluster {
+		infra := &configv1.Infrastructure{}
+		if err := c.Get(ctx, client.ObjectKey{Name: "cluster"}, infra); err != nil {
+			return nil, fmt.Errorf("failed to get Infrastructure: %w", err)
+		}
+		clusterTags, err := cluster.UserTags(infra, cloudPlatform, false)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range clusterTags {
+			desired[k] = v
+		}
+	}
+	for k, v := range policy.Spec.Tags {
+		desired[k] = v
+	}
+	return desired, nil
+}
+
+// selected reports whether the policy applies to the resource.
+func selected(res infraType.CloudResource, spec v1alpha1.ClusterMetadataPolicySpec) bool {
+	if res.NotTaggable {
+		return false
+	}
+	if len(spec.IncludeTypes) > 0 && !contains(spec.IncludeTypes, string(res.Type)) {
+		return false
+	}
+	return !contains(spec.ExcludeTypes, string(res.Type))
+}
+
+// SelectResources keeps the taggable resources whose type the policy
+// includes and does not exclude.
+func SelectResources(resources []infraType.CloudResource, spec v1alpha1.ClusterMetadataPolicySpec) []infraType.CloudResource {
+	var matched []infraType.CloudResource
+	for _, res := range resources {
+		if selected(res, spec) {
+			matched = append(matched, res)
+		}
+	}
+	return matched
+}
+
+func interval(policy *v1alpha1.ClusterMetadataPolicy) time.Duration {
+	if policy.Spec.Interval == nil || policy.Spec.Interval.Duration <= 0 {
+		return defaultInterval
+	}
+	return policy.Spec.Interval.Duration
+}
+
+// written returns the resources the Tagger wrote: all of them when it
+// succeeded, otherwise those it recorded in progress.
+func written(resources []infraType.CloudResource, progress *infraType.Progress, err error) []infraType.CloudResource {
+	if err == nil {
+		return resources
+	}
+	var ok []infraType.CloudResource
+	for _, res := range resources {
+		if progress.Written(res) {
+			ok = append(ok, res)
+		}
+	}
+	return ok
+}
+
+func needsTags(current, desired map[string]string) bool {
+	for k, v := range desired {
+		if currentVal, exists := current[k]; !exists || currentVal != v {
+			return true
+		}
+	}
+	return false
+}
+
+// groupKeys returns the groups' keys as a set for sortedKeys.
+func groupKeys(groups map[string][]infraType.CloudResource) map[string]string {
+	keys := make(map[string]string, len(groups))
+	for k := range groups {
+		keys[k] = ""
+	}
+	return keys
+}
+
+func union(a, b []string) []string {
+	set := make(map[string]string)
+	for _, k := range a {
+		set[k] = ""
+	}
+	for _, k := range b {
+		set[k] = ""
+	}
+	return sortedKeys(set)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
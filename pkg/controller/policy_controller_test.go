@@ -0,0 +1,352 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/api/v1alpha1"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// fakeTagger implements Tagger and Resolver on a fixed set of resources.
+type fakeTagger struct {
+	resources []infraType.CloudResource
+	// failing are the IDs of the resources whose writes fail.
+	failing map[string]bool
+	// described are the tags DescribeResource returns, by resource ID.
+	described map[string]map[string]string
+	// loadBalancers are the load balancers by Service ingress hostname.
+	loadBalancers map[string]infraType.CloudResource
+	// onWrite, if set, is called before the first write.
+	onWrite func()
+	// calls are the writes, as "apply <ID> k=v,..." and "remove <ID> k,...".
+	calls []string
+}
+
+func (f *fakeTagger) ListResources(ctx context.Context, cloudPlatform infraType.CloudPlatform) ([]infraType.CloudResource, error) {
+	return f.resources, nil
+}
+
+func (f *fakeTagger) ApplyTags(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	resources []infraType.CloudResource, tags map[string]string) error {
+
+	var pairs []string
+	for _, k := range sortedKeys(tags) {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+	return f.write(ctx, resources, "apply", strings.Join(pairs, ","))
+}
+
+func (f *fakeTagger) RemoveTags(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	resources []infraType.CloudResource, keys []string) error {
+
+	return f.write(ctx, resources, "remove", strings.Join(keys, ","))
+}
+
+func (f *fakeTagger) write(ctx context.Context, resources []infraType.CloudResource, action, args string) error {
+	if f.onWrite != nil {
+		f.onWrite()
+		f.onWrite = nil
+	}
+	return infraType.ForEachResource(ctx, resources, action, func(ctx context.Context, res infraType.CloudResource) error {
+		if f.failing[res.ID] {
+			return errors.New("boom")
+		}
+		f.calls = append(f.calls, action+" "+res.ID+" "+args)
+		return nil
+	})
+}
+
+func (f *fakeTagger) DescribeResource(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	resource infraType.CloudResource) (infraType.CloudResource, error) {
+
+	resource.Tags = f.described[resource.ID]
+	return resource, nil
+}
+
+func (f *fakeTagger) FindLoadBalancer(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	hostname, ip string) (*infraType.CloudResource, error) {
+
+	if lb, ok := f.loadBalancers[hostname]; ok {
+		return &lb, nil
+	}
+	return nil, nil
+}
+
+func instance(id string, tags map[string]string) infraType.CloudResource {
+	return infraType.CloudResource{
+		CloudProvider: infraType.CloudPlatformAWS,
+		Type:          infraType.CloudResourceTypeAWSEC2Instance,
+		ID:            id,
+		Tags:          tags,
+	}
+}
+
+// newTestClient returns a client for an AWS cluster with the objects.
+func newTestClient(objs ...client.Object) client.Client {
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{Type: configv1.AWSPlatformType},
+		},
+	}
+	return fake.NewClientBuilder().
+		WithScheme(cluster.Scheme).
+		WithObjects(append(objs, infra)...).
+		WithStatusSubresource(&v1alpha1.ClusterMetadataPolicy{}, infra).
+		Build()
+}
+
+func testPolicy(spec v1alpha1.ClusterMetadataPolicySpec) *v1alpha1.ClusterMetadataPolicy {
+	return &v1alpha1.ClusterMetadataPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Generation: 2},
+		Spec:       spec,
+	}
+}
+
+// writeLedger stores the policy's ledger with the keys by resource.
+func writeLedger(t *testing.T, c client.Client, keys map[*infraType.CloudResource][]string) {
+	t.Helper()
+	ctx := context.Background()
+	l, err := cluster.ReadLedger(ctx, c, cluster.PolicyLedgerName("default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for res, k := range keys {
+		l.Add(*res, k)
+	}
+	if err := cluster.WriteLedger(ctx, c, l); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readLedger returns the keys of the policy's ledger.
+func readLedger(t *testing.T, c client.Client) map[string][]string {
+	t.Helper()
+	l, err := cluster.ReadLedger(context.Background(), c, cluster.PolicyLedgerName("default"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l.Keys
+}
+
+func reconcilePolicy(t *testing.T, r *PolicyReconciler) (ctrl.Result, *v1alpha1.ClusterMetadataPolicy) {
+	t.Helper()
+	ctx := context.Background()
+	result, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: "default"}})
+	if err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+	policy := &v1alpha1.ClusterMetadataPolicy{}
+	if err := r.Get(ctx, client.ObjectKey{Name: "default"}, policy); err != nil {
+		t.Fatal(err)
+	}
+	return result, policy
+}
+
+func TestPolicyReconcileSync(t *testing.T) {
+	untagged := instance("i-1", nil)
+	inSync := instance("i-2", map[string]string{"Owner": "DevOps"})
+	otherValue := instance("i-3", map[string]string{"Owner": "Admin"})
+	notTaggable := instance("i-4", nil)
+	notTaggable.NotTaggable = true
+
+	tests := []struct {
+		name        string
+		failing     map[string]bool
+		wantCalls   []string
+		wantSummary v1alpha1.SyncSummary
+		wantStatus  metav1.ConditionStatus
+		wantLedger  map[string][]string
+	}{
+		{
+			name:        "all resources tagged",
+			wantCalls:   []string{"apply i-1 Owner=DevOps", "apply i-3 Owner=DevOps"},
+			wantSummary: v1alpha1.SyncSummary{Resources: 3, InSync: 1, Updated: 2},
+			wantStatus:  metav1.ConditionTrue,
+			// i-3 already had the key, so the policy does not own it.
+			wantLedger: map[string][]string{untagged.Key(): {"Owner"}},
+		},
+		{
+			name:        "partial failure",
+			failing:     map[string]bool{"i-3": true},
+			wantCalls:   []string{"apply i-1 Owner=DevOps"},
+			wantSummary: v1alpha1.SyncSummary{Resources: 3, InSync: 1, Updated: 1, Failed: 1},
+			wantStatus:  metav1.ConditionFalse,
+			wantLedger:  map[string][]string{untagged.Key(): {"Owner"}},
+		},
+		{
+			name:        "failure of the resource the policy owns",
+			failing:     map[string]bool{"i-1": true},
+			wantCalls:   []string{"apply i-3 Owner=DevOps"},
+			wantSummary: v1alpha1.SyncSummary{Resources: 3, InSync: 1, Updated: 1, Failed: 1},
+			wantStatus:  metav1.ConditionFalse,
+			wantLedger:  map[string][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tagger := &fakeTagger{
+				resources: []infraType.CloudResource{untagged, inSync, otherValue, notTaggable},
+				failing:   tt.failing,
+			}
+			c := newTestClient(testPolicy(v1alpha1.ClusterMetadataPolicySpec{Tags: map[string]string{"Owner": "DevOps"}}))
+			r := &PolicyReconciler{Client: c, Tagger: tagger, Resolver: tagger}
+
+			result, policy := reconcilePolicy(t, r)
+
+			if !reflect.DeepEqual(tagger.calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", tagger.calls, tt.wantCalls)
+			}
+			if result.RequeueAfter != defaultInterval {
+				t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, defaultInterval)
+			}
+			if got := policy.Status.LastSyncSummary; got == nil || *got != tt.wantSummary {
+				t.Errorf("LastSyncSummary = %+v, want %+v", got, tt.wantSummary)
+			}
+			condition := meta.FindStatusCondition(policy.Status.Conditions, v1alpha1.ConditionSynced)
+			if condition == nil || condition.Status != tt.wantStatus || condition.ObservedGeneration != 2 {
+				t.Errorf("Synced condition = %+v, want status %s for generation 2", condition, tt.wantStatus)
+			}
+			if policy.Status.ObservedGeneration != 2 || policy.Status.LastSyncTime == nil {
+				t.Errorf("status = %+v, want generation 2 and a sync time", policy.Status)
+			}
+			if want := []string{"Owner"}; !reflect.DeepEqual(policy.Status.ManagedKeys, want) {
+				t.Errorf("ManagedKeys = %v, want %v", policy.Status.ManagedKeys, want)
+			}
+			if len(policy.Finalizers) != 0 {
+				t.Errorf("finalizers = %v, want none under RemovalPolicy Retain", policy.Finalizers)
+			}
+			if got := readLedger(t, c); !reflect.DeepEqual(got, tt.wantLedger) {
+				t.Errorf("ledger = %v, want %v", got, tt.wantLedger)
+			}
+		})
+	}
+}
+
+func TestPolicyReconcileKeepsConcurrentLedgerRecords(t *testing.T) {
+	untagged := instance("i-1", nil)
+	node := instance("i-9", nil)
+
+	c := newTestClient(testPolicy(v1alpha1.ClusterMetadataPolicySpec{Tags: map[string]string{"Owner": "DevOps"}}))
+	writeLedger(t, c, map[*infraType.CloudResource][]string{&untagged: {"Team"}})
+	tagger := &fakeTagger{resources: []infraType.CloudResource{untagged}}
+	// ResourceReconciler tags a new node while the policy syncs.
+	tagger.onWrite = func() {
+		writeLedger(t, c, map[*infraType.CloudResource][]string{&node: {"Owner"}})
+	}
+	r := &PolicyReconciler{Client: c, Tagger: tagger, Resolver: tagger}
+
+	reconcilePolicy(t, r)
+
+	want := map[string][]string{untagged.Key(): {"Owner", "Team"}, node.Key(): {"Owner"}}
+	if got := readLedger(t, c); !reflect.DeepEqual(got, want) {
+		t.Errorf("ledger = %v, want %v", got, want)
+	}
+}
+
+func TestPolicyReconcilePrune(t *testing.T) {
+	pruned := instance("i-1", map[string]string{"Owner": "DevOps", "Old": "x"})
+	failing := instance("i-2", map[string]string{"Owner": "DevOps", "Old": "y"})
+	foreign := instance("i-3", map[string]string{"Owner": "DevOps", "Old": "z"})
+
+	c := newTestClient(testPolicy(v1alpha1.ClusterMetadataPolicySpec{
+		Tags:          map[string]string{"Owner": "DevOps"},
+		RemovalPolicy: v1alpha1.RemovalPolicyDelete,
+	}))
+	writeLedger(t, c, map[*infraType.CloudResource][]string{
+		&pruned:  {"Old", "Owner"},
+		&failing: {"Old"},
+	})
+	tagger := &fakeTagger{
+		resources: []infraType.CloudResource{pruned, failing, foreign},
+		failing:   map[string]bool{"i-2": true},
+	}
+	r := &PolicyReconciler{Client: c, Tagger: tagger, Resolver: tagger}
+
+	_, policy := reconcilePolicy(t, r)
+
+	// Old was never added to i-3, so it is left alone.
+	if want := []string{"remove i-1 Old"}; !reflect.DeepEqual(tagger.calls, want) {
+		t.Errorf("calls = %q, want %q", tagger.calls, want)
+	}
+	want := v1alpha1.SyncSummary{Resources: 3, InSync: 3, Pruned: 1, Failed: 1}
+	if got := policy.Status.LastSyncSummary; got == nil || *got != want {
+		t.Errorf("LastSyncSummary = %+v, want %+v", got, want)
+	}
+	wantLedger := map[string][]string{pruned.Key(): {"Owner"}, failing.Key(): {"Old"}}
+	if got := readLedger(t, c); !reflect.DeepEqual(got, wantLedger) {
+		t.Errorf("ledger = %v, want %v", got, wantLedger)
+	}
+	if want := []string{removeTagsFinalizer}; !reflect.DeepEqual(policy.Finalizers, want) {
+		t.Errorf("finalizers = %v, want %v", policy.Finalizers, want)
+	}
+}
+
+func TestPolicyReconcileFinalize(t *testing.T) {
+	owned := instance("i-1", map[string]string{"Owner": "DevOps", "Team": "infra"})
+	foreign := instance("i-2", map[string]string{"Owner": "DevOps"})
+
+	policy := testPolicy(v1alpha1.ClusterMetadataPolicySpec{
+		Tags:          map[string]string{"Owner": "DevOps", "Team": "infra"},
+		RemovalPolicy: v1alpha1.RemovalPolicyDelete,
+	})
+	now := metav1.Now()
+	policy.DeletionTimestamp = &now
+	policy.Finalizers = []string{removeTagsFinalizer}
+	c := newTestClient(policy)
+	writeLedger(t, c, map[*infraType.CloudResource][]string{&owned: {"Owner", "Team"}})
+	tagger := &fakeTagger{resources: []infraType.CloudResource{owned, foreign}}
+	r := &PolicyReconciler{Client: c, Tagger: tagger, Resolver: tagger}
+
+	ctx := context.Background()
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Name: "default"}}); err != nil {
+		t.Fatalf("Reconcile() error = %v", err)
+	}
+
+	if want := []string{"remove i-1 Owner,Team"}; !reflect.DeepEqual(tagger.calls, want) {
+		t.Errorf("calls = %q, want %q", tagger.calls, want)
+	}
+	err := c.Get(ctx, client.ObjectKey{Namespace: cluster.LedgerNamespace, Name: cluster.PolicyLedgerName("default")}, &corev1.ConfigMap{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("ledger ConfigMap still exists, err = %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKey{Name: "default"}, &v1alpha1.ClusterMetadataPolicy{}); !apierrors.IsNotFound(err) {
+		t.Errorf("policy still exists, err = %v", err)
+	}
+}
+
+func TestWritten(t *testing.T) {
+	resources := []infraType.CloudResource{instance("i-1", nil), instance("i-2", nil)}
+	progress := &infraType.Progress{}
+	infraType.RecordWrite(infraType.WithProgress(context.Background(), progress), resources[1], nil)
+
+	ids := func(resources []infraType.CloudResource) []string {
+		var ids []string
+		for _, res := range resources {
+			ids = append(ids, res.ID)
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	if got := ids(written(resources, progress, nil)); !reflect.DeepEqual(got, []string{"i-1", "i-2"}) {
+		t.Errorf("written() without error = %v, want every resource", got)
+	}
+	if got := ids(written(resources, progress, errors.New("boom"))); !reflect.DeepEqual(got, []string{"i-2"}) {
+		t.Errorf("written() with error = %v, want the recorded resource", got)
+	}
+}
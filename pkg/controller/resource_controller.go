@@ -0,0 +1,258 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	configv1 "github.com/openshift/api/config/v1"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/api/v1alpha1"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// Resolver looks up single cloud resources, so objects can be tagged without
+// a discovery pass.
+type Resolver interface {
+	// DescribeResource returns the resource with its current tags.
+	DescribeResource(ctx context.Context, cloudPlatform infraType.CloudPlatform, resource infraType.CloudResource) (infraType.CloudResource, error)
+	// FindLoadBalancer returns the load balancer behind a Service ingress
+	// hostname or IP, or nil when there is none.
+	FindLoadBalancer(ctx context.Context, cloudPlatform infraType.CloudPlatform, hostname, ip string) (*infraType.CloudResource, error)
+}
+
+// ResourceReconciler applies the policies to the cloud resource behind a
+// Node, PersistentVolume or LoadBalancer Service as soon as it appears.
+type ResourceReconciler struct {
+	client.Client
+	Tagger   Tagger
+	Resolver Resolver
+}
+
+// SetupWithManager registers one controller per watched kind. Every object
+// is looked at once when the controller starts.
+func (r *ResourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := ctrl.NewControllerManagedBy(mgr).
+		Named("node-tagger").
+		For(&corev1.Node{}, builder.WithPredicates(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return e.Object.(*corev1.Node).Spec.ProviderID != ""
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				return e.ObjectOld.(*corev1.Node).Spec.ProviderID != e.ObjectNew.(*corev1.Node).Spec.ProviderID
+			},
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		})).
+		Complete(reconcile.Func(r.reconcileNode))
+	if err != nil {
+		return err
+	}
+
+	err = ctrl.NewControllerManagedBy(mgr).
+		Named("volume-tagger").
+		For(&corev1.PersistentVolume{}, builder.WithPredicates(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return e.Object.(*corev1.PersistentVolume).Spec.CSI != nil
+			},
+			UpdateFunc:  func(event.UpdateEvent) bool { return false },
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		})).
+		Complete(reconcile.Func(r.reconcileVolume))
+	if err != nil {
+		return err
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named("service-tagger").
+		For(&corev1.Service{}, builder.WithPredicates(predicate.Funcs{
+			CreateFunc: func(e event.CreateEvent) bool {
+				return hasLoadBalancerIngress(e.Object.(*corev1.Service))
+			},
+			UpdateFunc: func(e event.UpdateEvent) bool {
+				oldSvc, newSvc := e.ObjectOld.(*corev1.Service), e.ObjectNew.(*corev1.Service)
+				return hasLoadBalancerIngress(newSvc) &&
+					!reflect.DeepEqual(oldSvc.Status.LoadBalancer.Ingress, newSvc.Status.LoadBalancer.Ingress)
+			},
+			DeleteFunc:  func(event.DeleteEvent) bool { return false },
+			GenericFunc: func(event.GenericEvent) bool { return false },
+		})).
+		Complete(reconcile.Func(r.reconcileService))
+}
+
+func (r *ResourceReconciler) reconcileNode(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	node := &corev1.Node{}
+	if err := r.Get(ctx, req.NamespacedName, node); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	res, ok := cluster.ResourceFromProviderID(node.Spec.ProviderID)
+	if !ok {
+		log.FromContext(ctx).V(1).Info("unsupported providerID", "node", node.Name, "providerID", node.Spec.ProviderID)
+		return reconcile.Result{}, nil
+	}
+	return reconcile.Result{}, r.apply(ctx, res, "")
+}
+
+func (r *ResourceReconciler) reconcileVolume(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	pv := &corev1.PersistentVolume{}
+	if err := r.Get(ctx, req.NamespacedName, pv); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	res, ok := cluster.ResourceFromVolume(pv)
+	if !ok {
+		return reconcile.Result{}, nil
+	}
+
+	namespace := ""
+	if pv.Spec.ClaimRef != nil {
+		namespace = pv.Spec.ClaimRef.Namespace
+	}
+	return reconcile.Result{}, r.apply(ctx, res, namespace)
+}
+
+func (r *ResourceReconciler) reconcileService(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	svc := &corev1.Service{}
+	if err := r.Get(ctx, req.NamespacedName, svc); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+	if !hasLoadBalancerIngress(svc) {
+		return reconcile.Result{}, nil
+	}
+
+	cloudPlatform, err := r.platform(ctx)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		res, err := r.Resolver.FindLoadBalancer(ctx, cloudPlatform, ingress.Hostname, ingress.IP)
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("failed to find the load balancer of Service %s: %w", req, err)
+		}
+		if res == nil {
+			log.FromContext(ctx).Info("no load balancer found", "service", req, "hostname", ingress.Hostname, "ip", ingress.IP)
+			continue
+		}
+		if err := r.tag(ctx, cloudPlatform, *res, svc.Namespace); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	return reconcile.Result{}, nil
+}
+
+// apply describes the resource and tags it with every policy that selects
+// it. namespace is set for resources created for a namespaced object.
+func (r *ResourceReconciler) apply(ctx context.Context, res infraType.CloudResource, namespace string) error {
+	cloudPlatform, err := r.platform(ctx)
+	if err != nil {
+		return err
+	}
+
+	described, err := r.Resolver.DescribeResource(ctx, cloudPlatform, res)
+	if err != nil {
+		return fmt.Errorf("failed to describe %s %s: %w", res.Type, resourceName(res), err)
+	}
+	return r.tag(ctx, cloudPlatform, described, namespace)
+}
+
+// tag applies the desired tags of every policy that selects the resource
+// and is missing from it, including the namespace's tags for policies with
+// NamespaceTagKeys.
+func (r *ResourceReconciler) tag(ctx context.Context, cloudPlatform infraType.CloudPlatform,
+	res infraType.CloudResource, namespace string) error {
+	logger := log.FromContext(ctx)
+
+	var policies v1alpha1.ClusterMetadataPolicyList
+	if err := r.List(ctx, &policies); err != nil {
+		return fmt.Errorf("failed to list ClusterMetadataPolicies: %w", err)
+	}
+
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		if !policy.DeletionTimestamp.IsZero() || !selected(res, policy.Spec) {
+			continue
+		}
+
+		desired, err := DesiredTags(ctx, r.Client, policy, cloudPlatform)
+		if err != nil {
+			return err
+		}
+		// The namespace's tags are never removed, so the policy does not
+		// own them.
+		owned := make(map[string]string, len(desired))
+		for k, v := range desired {
+			owned[k] = v
+		}
+		if namespace != "" && len(policy.Spec.NamespaceTagKeys) > 0 {
+			ns := &corev1.Namespace{}
+			if err := r.Get(ctx, client.ObjectKey{Name: namespace}, ns); err != nil {
+				return fmt.Errorf("failed to get Namespace %s: %w", namespace, err)
+			}
+			for k, v := range cluster.NamespaceTags(ns, policy.Spec.NamespaceTagKeys) {
+				desired[k] = v
+			}
+		}
+		if !needsTags(res.Tags, desired) {
+			continue
+		}
+
+		if err := r.Tagger.ApplyTags(ctx, cloudPlatform, []infraType.CloudResource{res}, desired); err != nil {
+			return fmt.Errorf("failed to tag %s %s: %w", res.Type, resourceName(res), err)
+		}
+		logger.Info("tagged resource", "policy", policy.Name, "type", res.Type, "resource", resourceName(res))
+		if err := r.own(ctx, policy, res, owned); err != nil {
+			return err
+		}
+
+		merged := make(map[string]string)
+		for k, v := range res.Tags {
+			merged[k] = v
+		}
+		for k, v := range desired {
+			merged[k] = v
+		}
+		res.Tags = merged
+	}
+	return nil
+}
+
+// own records the keys of the tags the resource did not carry in the
+// policy's ledger.
+func (r *ResourceReconciler) own(ctx context.Context, policy *v1alpha1.ClusterMetadataPolicy,
+	res infraType.CloudResource, tags map[string]string) error {
+
+	return cluster.UpdateLedger(ctx, r.Client, cluster.PolicyLedgerName(policy.Name), func(l *cluster.Ledger) {
+		l.Own(res, tags)
+	})
+}
+
+func (r *ResourceReconciler) platform(ctx context.Context) (infraType.CloudPlatform, error) {
+	infra := &configv1.Infrastructure{}
+	if err := r.Get(ctx, client.ObjectKey{Name: "cluster"}, infra); err != nil {
+		return "", fmt.Errorf("failed to get Infrastructure: %w", err)
+	}
+	return cluster.Platform(infra), nil
+}
+
+func hasLoadBalancerIngress(svc *corev1.Service) bool {
+	return svc.Spec.Type == corev1.ServiceTypeLoadBalancer && len(svc.Status.LoadBalancer.Ingress) > 0
+}
+
+func resourceName(res infraType.CloudResource) string {
+	if res.ID != "" {
+		return res.ID
+	}
+	return res.Name
+}
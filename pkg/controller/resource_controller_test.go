@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/api/v1alpha1"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func TestResourceReconcile(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name:   "payments",
+		Labels: map[string]string{"team": "checkout"},
+	}}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-0"},
+		Spec:       corev1.NodeSpec{ProviderID: "aws:///us-east-1a/i-9"},
+	}
+	volume := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"},
+		Spec: corev1.PersistentVolumeSpec{
+			PersistentVolumeSource: corev1.PersistentVolumeSource{
+				CSI: &corev1.CSIPersistentVolumeSource{Driver: "ebs.csi.aws.com", VolumeHandle: "vol-9"},
+			},
+			ClaimRef: &corev1.ObjectReference{Namespace: "payments", Name: "data"},
+		},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "payments", Name: "frontend"},
+		Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeLoadBalancer},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+			Ingress: []corev1.LoadBalancerIngress{{Hostname: "frontend.elb.amazonaws.com"}},
+		}},
+	}
+	loadBalancer := infraType.CloudResource{
+		CloudProvider: infraType.CloudPlatformAWS,
+		Type:          infraType.CloudResourceTypeAWSLoadBalancer,
+		ID:            "lb-9",
+		Tags:          map[string]string{"kubernetes.io/service-name": "payments/frontend"},
+	}
+
+	tests := []struct {
+		name       string
+		reconcile  func(r *ResourceReconciler, ctx context.Context, req reconcile.Request) (reconcile.Result, error)
+		req        client.Object
+		described  map[string]map[string]string
+		wantCalls  []string
+		wantLedger map[string][]string
+	}{
+		{
+			name:       "node",
+			reconcile:  (*ResourceReconciler).reconcileNode,
+			req:        node,
+			described:  map[string]map[string]string{"i-9": {"Env": "prod"}},
+			wantCalls:  []string{"apply i-9 Owner=DevOps"},
+			wantLedger: map[string][]string{instance("i-9", nil).Key(): {"Owner"}},
+		},
+		{
+			name:      "tagged node",
+			reconcile: (*ResourceReconciler).reconcileNode,
+			req:       node,
+			described: map[string]map[string]string{"i-9": {"Owner": "DevOps"}},
+		},
+		{
+			// The namespace's tags are applied but not owned.
+			name:      "volume",
+			reconcile: (*ResourceReconciler).reconcileVolume,
+			req:       volume,
+			wantCalls: []string{"apply vol-9 Owner=DevOps,team=checkout"},
+			wantLedger: map[string][]string{
+				infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEBSVolume, ID: "vol-9"}.Key(): {"Owner"},
+			},
+		},
+		{
+			name:       "service",
+			reconcile:  (*ResourceReconciler).reconcileService,
+			req:        service,
+			wantCalls:  []string{"apply lb-9 Owner=DevOps,team=checkout"},
+			wantLedger: map[string][]string{loadBalancer.Key(): {"Owner"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := testPolicy(v1alpha1.ClusterMetadataPolicySpec{
+				Tags:             map[string]string{"Owner": "DevOps"},
+				NamespaceTagKeys: []string{"team"},
+			})
+			// A policy for other resource types does not apply.
+			other := testPolicy(v1alpha1.ClusterMetadataPolicySpec{
+				Tags:         map[string]string{"Backup": "daily"},
+				IncludeTypes: []string{string(infraType.CloudResourceTypeAWSS3Bucket)},
+			})
+			other.Name = "buckets"
+			c := newTestClient(policy, other, namespace, tt.req)
+			tagger := &fakeTagger{
+				described:     tt.described,
+				loadBalancers: map[string]infraType.CloudResource{"frontend.elb.amazonaws.com": loadBalancer},
+			}
+			r := &ResourceReconciler{Client: c, Tagger: tagger, Resolver: tagger}
+
+			req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(tt.req)}
+			if _, err := tt.reconcile(r, context.Background(), req); err != nil {
+				t.Fatalf("reconcile error = %v", err)
+			}
+
+			if !reflect.DeepEqual(tagger.calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", tagger.calls, tt.wantCalls)
+			}
+			wantLedger := tt.wantLedger
+			if wantLedger == nil {
+				wantLedger = map[string][]string{}
+			}
+			if got := readLedger(t, c); !reflect.DeepEqual(got, wantLedger) {
+				t.Errorf("ledger = %v, want %v", got, wantLedger)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/compute/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// DescribeResource returns the resource with its current labels. It covers
+// the types that back Kubernetes objects: instances and zonal disks, which
+// are addressed by Project, Location and Name.
+func DescribeResource(ctx context.Context, resource infraType.CloudResource) (infraType.CloudResource, error) {
+
+	creds, err := getGCPCredentials(ctx)
+	if err != nil {
+		return resource, fmt.Errorf("GCP authentication error: %w", err)
+	}
+
+	svc, err := compute.NewService(ctx, clientOptions(ctx, creds)...)
+	if err != nil {
+		return resource, fmt.Errorf("compute service error: %w", err)
+	}
+
+	resource.CloudProvider = infraType.CloudPlatformGCP
+	switch resource.Type {
+	case infraType.CloudResourceTypeGCPComputeInstance:
+		instance, err := svc.Instances.Get(resource.Project, resource.Location, resource.Name).Context(ctx).Do()
+		if err != nil {
+			return resource, fmt.Errorf("failed to get instance: %w", err)
+		}
+		resource.ID = fmt.Sprintf("%d", instance.Id)
+		resource.Tags = instance.Labels
+		resource.SelfLink = instance.SelfLink
+	case infraType.CloudResourceTypeGCPDisk:
+		disk, err := svc.Disks.Get(resource.Project, resource.Location, resource.Name).Context(ctx).Do()
+		if err != nil {
+			return resource, fmt.Errorf("failed to get disk: %w", err)
+		}
+		resource.ID = fmt.Sprintf("%d", disk.Id)
+		resource.Tags = disk.Labels
+		resource.SelfLink = disk.SelfLink
+	default:
+		return resource, fmt.Errorf("unsupported resource type: %s", resource.Type)
+	}
+
+	if resource.Tags == nil {
+		resource.Tags = make(map[string]string)
+	}
+	return resource, nil
+}
+
+// FindLoadBalancer returns the forwarding rule with the given IP address in
+// the cluster project, as reported in a Service's status, or nil when there
+// is none.
+func FindLoadBalancer(ctx context.Context, k8sClient client.Client, ipAddress string) (*infraType.CloudResource, error) {
+	_, projectID, err := getClusterMetadata(ctx, k8sClient)
+	if err != nil {
+		return nil, err
+	}
+
+	creds, err := getGCPCredentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GCP authentication error: %w", err)
+	}
+
+	svc, err := compute.NewService(ctx, clientOptions(ctx, creds)...)
+	if err != nil {
+		return nil, fmt.Errorf("compute service error: %w", err)
+	}
+
+	var found *infraType.CloudResource
+	req := svc.ForwardingRules.AggregatedList(projectID).Filter(fmt.Sprintf("IPAddress = %q", ipAddress))
+	err = req.Pages(ctx, func(page *compute.ForwardingRuleAggregatedList) error {
+		for _, rules := range page.Items {
+			for _, lb := range rules.ForwardingRules {
+				if found != nil {
+					return nil
+				}
+				found = &infraType.CloudResource{
+					CloudProvider: infraType.CloudPlatformGCP,
+					Type:          infraType.CloudResourceTypeGCPLoadBalancer,
+					ID:            fmt.Sprintf("%d", lb.Id),
+					Name:          lb.Name,
+					Tags:          lb.Labels,
+					Location:      regionOrGlobal(lb.Region),
+					SelfLink:      lb.SelfLink,
+					Project:       projectID,
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing forwarding rules: %w", err)
+	}
+	if found != nil && found.Tags == nil {
+		found.Tags = make(map[string]string)
+	}
+	return found, nil
+}
@@ -4,43 +4,55 @@ import (
 	"cloud.google.com/go/storage"
 	"context"
 	"fmt"
+	"path"
+	"strings"
 	//"log"
 
 	configv1 "github.com/openshift/api/config/v1"
 	"google.golang.org/api/compute/v1"
 	"google.golang.org/api/dns/v1"
+	"google.golang.org/api/file/v1"
+	"google.golang.org/api/iam/v1"
 	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
-	"k8s.io/client-go/kubernetes/scheme"
-	ctrl "sigs.k8s.io/controller-runtime"
+	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
+	"github.com/go-logr/logr"
 	"golang.org/x/oauth2/google"
 
 	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
 )
 
-var (
-	clusterLabelKey   string
-	clusterLabelValue = "owned"
-)
+const clusterLabelValue = "owned"
+
+// clusterLabelKey returns the label the installer puts on the cluster's
+// resources.
+func clusterLabelKey(infraID string) string {
+	return fmt.Sprintf("kubernetes-io-cluster-%s", infraID)
+}
 
-func ListGCPResources() ([]infraType.CloudResource, error) {
-	ctx := context.Background()
+// ListGCPResources discovers the cluster's resources in its own project and,
+// for shared-VPC installs, in the network host project. networkProjectID
+// overrides the host project read from the install-config.
+func ListGCPResources(ctx context.Context, k8sClient client.Client, networkProjectID string) ([]infraType.CloudResource, error) {
+	log := logr.FromContextOrDiscard(ctx)
 	var resources []infraType.CloudResource
 
-	// Initialize Kubernetes client
-	k8sClient, err := getK8sClient()
+	// Get cluster metadata
+	clusterName, projectID, err := getClusterMetadata(ctx, k8sClient)
 	if err != nil {
-		return nil, fmt.Errorf("kubernetes client error: %w", err)
+		return nil, fmt.Errorf("cluster metadata error: %w", err)
 	}
 
-	// Get cluster metadata
-	clusterName, projectID, err := getClusterMetadata(k8sClient)
+	netCfg, err := getNetworkConfig(ctx, k8sClient)
 	if err != nil {
-		return nil, fmt.Errorf("cluster metadata error: %w", err)
+		log.Error(err, "cannot read install-config, shared VPC settings unknown")
+	}
+	if networkProjectID != "" {
+		netCfg.ProjectID = networkProjectID
 	}
-	clusterLabelKey = fmt.Sprintf("kubernetes-io-cluster-%s", clusterName)
 
 	// Configure GCP credentials
 	creds, err := getGCPCredentials(ctx)
@@ -49,89 +61,144 @@ func ListGCPResources() ([]infraType.CloudResource, error) {
 	}
 
 	// Initialize GCP services
-	computeSvc, err := compute.NewService(ctx, option.WithCredentials(creds))
+	computeSvc, err := compute.NewService(ctx, clientOptions(ctx, creds)...)
 	if err != nil {
 		return nil, fmt.Errorf("compute service error: %w", err)
 	}
 
-	storageClient, err := storage.NewClient(ctx, option.WithCredentials(creds))
+	storageClient, err := storage.NewClient(ctx, clientOptions(ctx, creds)...)
 	if err != nil {
 		return nil, fmt.Errorf("storage client error: %w", err)
 	}
 	defer storageClient.Close()
 
-	dnsSvc, err := dns.NewService(ctx, option.WithCredentials(creds))
+	dnsSvc, err := dns.NewService(ctx, clientOptions(ctx, creds)...)
 	if err != nil {
 		return nil, fmt.Errorf("dns service error: %w", err)
 	}
 
+	iamSvc, err := iam.NewService(ctx, clientOptions(ctx, creds)...)
+	if err != nil {
+		return nil, fmt.Errorf("iam service error: %w", err)
+	}
+
+	fileSvc, err := file.NewService(ctx, clientOptions(ctx, creds)...)
+	if err != nil {
+		return nil, fmt.Errorf("filestore service error: %w", err)
+	}
+
 	// List resources
-	if computeRes, err := listComputeResources(ctx, computeSvc, projectID); err == nil {
+	if computeRes, err := listComputeResources(ctx, computeSvc, projectID, clusterName); err == nil {
 		resources = append(resources, computeRes...)
 	} else {
-		fmt.Println("cannot list compute resources:", err)
+		log.Error(err, "cannot list compute resources")
 	}
-	if storageRes, err := listStorageResources(ctx, storageClient, projectID); err == nil {
+	if storageRes, err := listStorageResources(ctx, storageClient, projectID, clusterName); err == nil {
 		resources = append(resources, storageRes...)
 	} else {
-		fmt.Println("cannot list storage resources:", err)
+		log.Error(err, "cannot list storage resources")
 	}
-	if dnsRes, err := listDNSResources(ctx, dnsSvc, projectID); err == nil {
+	if dnsRes, err := listDNSResources(ctx, dnsSvc, projectID, clusterName); err == nil {
 		resources = append(resources, dnsRes...)
 	} else {
-		fmt.Println("cannot list DNS resources:", err)
+		log.Error(err, "cannot list DNS resources")
+	}
+	if saRes, err := listServiceAccounts(ctx, iamSvc, projectID, clusterName); err == nil {
+		resources = append(resources, saRes...)
+	} else {
+		log.Error(err, "cannot list service accounts")
+	}
+	if fsRes, err := listFilestoreInstances(ctx, fileSvc, projectID, clusterName); err == nil {
+		resources = append(resources, fsRes...)
+	} else {
+		log.Error(err, "cannot list Filestore instances")
+	}
+	setProject(resources, projectID)
+
+	if netCfg.ProjectID != "" && netCfg.ProjectID != projectID {
+		netRes, err := listNetworkProjectResources(ctx, computeSvc, dnsSvc, netCfg, clusterName)
+		if err != nil {
+			log.Error(err, "cannot list network project resources")
+		}
+		setProject(netRes, netCfg.ProjectID)
+		resources = append(resources, netRes...)
 	}
 
 	return resources, nil
 }
 
-func listComputeResources(ctx context.Context, svc *compute.Service, projectID string) ([]infraType.CloudResource, error) {
+func listComputeResources(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	log := logr.FromContextOrDiscard(ctx)
 	var resources []infraType.CloudResource
 
 	// List instances
-	instances, err := listLabeledInstances(ctx, svc, projectID)
+	instances, err := listLabeledInstances(ctx, svc, projectID, infraID)
 	if err != nil {
 		return nil, err
 	}
 	resources = append(resources, instances...)
 
 	// List disks
-	disks, err := listLabeledDisks(ctx, svc, projectID)
+	disks, err := listLabeledDisks(ctx, svc, projectID, infraID)
 	if err != nil {
 		return nil, err
 	}
 	resources = append(resources, disks...)
 
-	// List networks
-	networks, err := listLabeledNetworks(ctx, svc, projectID)
+	// List load balancers
+	lbs, err := listLabeledLoadBalancers(ctx, svc, projectID, infraID)
 	if err == nil {
-		resources = append(resources, networks...)
+		resources = append(resources, lbs...)
 	}
-	//resources = append(resources, networks...)
 
-	// List subnetworks
-	subnets, err := listLabeledSubnetworks(ctx, svc, projectID)
+	// List static addresses
+	addresses, err := listClusterAddresses(ctx, svc, projectID, infraID)
 	if err == nil {
-		resources = append(resources, subnets...)
+		resources = append(resources, addresses...)
 	}
-	//resources = append(resources, subnets...)
 
-	// List load balancers
-	lbs, err := listLabeledLoadBalancers(ctx, svc, projectID)
+	// List images
+	images, err := listLabeledImages(ctx, svc, projectID, infraID)
 	if err == nil {
-		//return nil, err
-		resources = append(resources, lbs...)
+		resources = append(resources, images...)
+	}
+
+	// List snapshots taken of cluster disks, e.g. by the PD CSI driver
+	snapshots, err := listClusterSnapshots(ctx, svc, projectID, infraID, disks)
+	if err == nil {
+		resources = append(resources, snapshots...)
+	}
+
+	// Instance groups, networks, subnetworks, firewall rules, routes, target
+	// pools, backend services and health checks carry no labels, so they are
+	// matched by the installer's <infraID>- name prefix instead.
+	unlabeled := []func(context.Context, *compute.Service, string, string) ([]infraType.CloudResource, error){
+		listClusterInstanceGroups,
+		listClusterNetworks,
+		listClusterSubnetworks,
+		listClusterFirewalls,
+		listClusterRoutes,
+		listClusterTargetPools,
+		listClusterBackendServices,
+		listClusterHealthChecks,
+	}
+	for _, list := range unlabeled {
+		res, err := list(ctx, svc, projectID, infraID)
+		if err != nil {
+			log.Error(err, "cannot list unlabeled compute resources")
+			continue
+		}
+		resources = append(resources, res...)
 	}
-	//resources = append(resources, lbs...)
 
 	return resources, nil
 }
 
-func listLabeledInstances(ctx context.Context, svc *compute.Service, projectID string) ([]infraType.CloudResource, error) {
+func listLabeledInstances(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 
 	req := svc.Instances.AggregatedList(projectID).
-		Filter(fmt.Sprintf("labels.%s = %s", clusterLabelKey, clusterLabelValue))
+		Filter(fmt.Sprintf("labels.%s = %s", clusterLabelKey(infraID), clusterLabelValue))
 
 	err := req.Pages(ctx, func(page *compute.InstanceAggregatedList) error {
 		for _, instances := range page.Items {
@@ -142,6 +209,8 @@ func listLabeledInstances(ctx context.Context, svc *compute.Service, projectID s
 					ID:            fmt.Sprintf("%d", instance.Id),
 					Name:          instance.Name,
 					Tags:          instance.Labels,
+					Location:      lastSegment(instance.Zone),
+					SelfLink:      instance.SelfLink,
 				})
 			}
 		}
@@ -151,11 +220,11 @@ func listLabeledInstances(ctx context.Context, svc *compute.Service, projectID s
 	return resources, err
 }
 
-func listLabeledDisks(ctx context.Context, svc *compute.Service, projectID string) ([]infraType.CloudResource, error) {
+func listLabeledDisks(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 
 	req := svc.Disks.AggregatedList(projectID).
-		Filter(fmt.Sprintf("labels.%s = %s", clusterLabelKey, clusterLabelValue))
+		Filter(fmt.Sprintf("labels.%s = %s", clusterLabelKey(infraID), clusterLabelValue))
 
 	err := req.Pages(ctx, func(page *compute.DiskAggregatedList) error {
 		for _, disks := range page.Items {
@@ -166,6 +235,8 @@ func listLabeledDisks(ctx context.Context, svc *compute.Service, projectID strin
 					ID:            fmt.Sprintf("%d", disk.Id),
 					Name:          disk.Name,
 					Tags:          disk.Labels,
+					Location:      lastSegment(disk.Zone),
+					SelfLink:      disk.SelfLink,
 				})
 			}
 		}
@@ -175,11 +246,42 @@ func listLabeledDisks(ctx context.Context, svc *compute.Service, projectID strin
 	return resources, err
 }
 
-func listLabeledNetworks(ctx context.Context, svc *compute.Service, projectID string) ([]infraType.CloudResource, error) {
+func listLabeledLoadBalancers(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 
-	req := svc.Networks.List(projectID).
-		Filter(fmt.Sprintf("labels.%s = %s", clusterLabelKey, clusterLabelValue))
+	req := svc.ForwardingRules.AggregatedList(projectID).
+		Filter(fmt.Sprintf("labels.%s = %s", clusterLabelKey(infraID), clusterLabelValue))
+
+	err := req.Pages(ctx, func(page *compute.ForwardingRuleAggregatedList) error {
+		for _, rules := range page.Items {
+			for _, lb := range rules.ForwardingRules {
+				resources = append(resources, infraType.CloudResource{
+					CloudProvider: infraType.CloudPlatformGCP,
+					Type:          infraType.CloudResourceTypeGCPLoadBalancer,
+					ID:            fmt.Sprintf("%d", lb.Id),
+					Name:          lb.Name,
+					Tags:          lb.Labels,
+					Location:      regionOrGlobal(lb.Region),
+					SelfLink:      lb.SelfLink,
+				})
+			}
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+// infraIDFilter matches resources named with the installer's <infraID>-
+// prefix.
+func infraIDFilter(infraID string) string {
+	return fmt.Sprintf("name eq \"%s-.*\"", infraID)
+}
+
+func listClusterNetworks(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	req := svc.Networks.List(projectID).Filter(infraIDFilter(infraID))
 
 	err := req.Pages(ctx, func(page *compute.NetworkList) error {
 		for _, network := range page.Items {
@@ -188,7 +290,9 @@ func listLabeledNetworks(ctx context.Context, svc *compute.Service, projectID st
 				Type:          infraType.CloudResourceTypeGCPNetwork,
 				ID:            fmt.Sprintf("%d", network.Id),
 				Name:          network.Name,
-				//Tags:          network.Labels,
+				Location:      "global",
+				SelfLink:      network.SelfLink,
+				NotTaggable:   true,
 			})
 		}
 		return nil
@@ -197,11 +301,10 @@ func listLabeledNetworks(ctx context.Context, svc *compute.Service, projectID st
 	return resources, err
 }
 
-func listLabeledSubnetworks(ctx context.Context, svc *compute.Service, projectID string) ([]infraType.CloudResource, error) {
+func listClusterSubnetworks(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 
-	req := svc.Subnetworks.AggregatedList(projectID).
-		Filter(fmt.Sprintf("labels.%s = %s", clusterLabelKey, clusterLabelValue))
+	req := svc.Subnetworks.AggregatedList(projectID).Filter(infraIDFilter(infraID))
 
 	err := req.Pages(ctx, func(page *compute.SubnetworkAggregatedList) error {
 		for _, subnets := range page.Items {
@@ -211,7 +314,9 @@ func listLabeledSubnetworks(ctx context.Context, svc *compute.Service, projectID
 					Type:          infraType.CloudResourceTypeGCPSubnet,
 					ID:            fmt.Sprintf("%d", subnet.Id),
 					Name:          subnet.Name,
-					//Tags:          subnet.Labels,
+					Location:      lastSegment(subnet.Region),
+					SelfLink:      subnet.SelfLink,
+					NotTaggable:   true,
 				})
 			}
 		}
@@ -221,21 +326,247 @@ func listLabeledSubnetworks(ctx context.Context, svc *compute.Service, projectID
 	return resources, err
 }
 
-func listLabeledLoadBalancers(ctx context.Context, svc *compute.Service, projectID string) ([]infraType.CloudResource, error) {
+func listClusterFirewalls(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 
-	req := svc.ForwardingRules.AggregatedList(projectID).
-		Filter(fmt.Sprintf("labels.%s = %s", clusterLabelKey, clusterLabelValue))
+	req := svc.Firewalls.List(projectID).Filter(infraIDFilter(infraID))
 
-	err := req.Pages(ctx, func(page *compute.ForwardingRuleAggregatedList) error {
-		for _, rules := range page.Items {
-			for _, lb := range rules.ForwardingRules {
+	err := req.Pages(ctx, func(page *compute.FirewallList) error {
+		for _, fw := range page.Items {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformGCP,
+				Type:          infraType.CloudResourceTypeGCPFirewallRule,
+				ID:            fmt.Sprintf("%d", fw.Id),
+				Name:          fw.Name,
+				Location:      "global",
+				SelfLink:      fw.SelfLink,
+				NotTaggable:   true,
+			})
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+func listClusterRoutes(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	req := svc.Routes.List(projectID).Filter(infraIDFilter(infraID))
+
+	err := req.Pages(ctx, func(page *compute.RouteList) error {
+		for _, route := range page.Items {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformGCP,
+				Type:          infraType.CloudResourceTypeGCPRoute,
+				ID:            fmt.Sprintf("%d", route.Id),
+				Name:          route.Name,
+				Location:      "global",
+				SelfLink:      route.SelfLink,
+				NotTaggable:   true,
+			})
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+func listClusterTargetPools(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	req := svc.TargetPools.AggregatedList(projectID).Filter(infraIDFilter(infraID))
+
+	err := req.Pages(ctx, func(page *compute.TargetPoolAggregatedList) error {
+		for _, pools := range page.Items {
+			for _, pool := range pools.TargetPools {
 				resources = append(resources, infraType.CloudResource{
 					CloudProvider: infraType.CloudPlatformGCP,
-					Type:          infraType.CloudResourceTypeGCPLoadBalancer,
-					ID:            fmt.Sprintf("%d", lb.Id),
-					Name:          lb.Name,
-					Tags:          lb.Labels,
+					Type:          infraType.CloudResourceTypeGCPTargetPool,
+					ID:            fmt.Sprintf("%d", pool.Id),
+					Name:          pool.Name,
+					Location:      lastSegment(pool.Region),
+					SelfLink:      pool.SelfLink,
+					NotTaggable:   true,
+				})
+			}
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+func listClusterBackendServices(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	req := svc.BackendServices.AggregatedList(projectID).Filter(infraIDFilter(infraID))
+
+	err := req.Pages(ctx, func(page *compute.BackendServiceAggregatedList) error {
+		for _, services := range page.Items {
+			for _, bs := range services.BackendServices {
+				resources = append(resources, infraType.CloudResource{
+					CloudProvider: infraType.CloudPlatformGCP,
+					Type:          infraType.CloudResourceTypeGCPBackendService,
+					ID:            fmt.Sprintf("%d", bs.Id),
+					Name:          bs.Name,
+					Location:      regionOrGlobal(bs.Region),
+					SelfLink:      bs.SelfLink,
+					NotTaggable:   true,
+				})
+			}
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+func listClusterHealthChecks(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	req := svc.HealthChecks.AggregatedList(projectID).Filter(infraIDFilter(infraID))
+
+	err := req.Pages(ctx, func(page *compute.HealthChecksAggregatedList) error {
+		for _, checks := range page.Items {
+			for _, hc := range checks.HealthChecks {
+				resources = append(resources, infraType.CloudResource{
+					CloudProvider: infraType.CloudPlatformGCP,
+					Type:          infraType.CloudResourceTypeGCPHealthCheck,
+					ID:            fmt.Sprintf("%d", hc.Id),
+					Name:          hc.Name,
+					Location:      regionOrGlobal(hc.Region),
+					SelfLink:      hc.SelfLink,
+					NotTaggable:   true,
+				})
+			}
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+func listClusterAddresses(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	req := svc.Addresses.AggregatedList(projectID).Filter(infraIDFilter(infraID))
+
+	err := req.Pages(ctx, func(page *compute.AddressAggregatedList) error {
+		for _, addresses := range page.Items {
+			for _, addr := range addresses.Addresses {
+				resources = append(resources, infraType.CloudResource{
+					CloudProvider: infraType.CloudPlatformGCP,
+					Type:          infraType.CloudResourceTypeGCPAddress,
+					ID:            fmt.Sprintf("%d", addr.Id),
+					Name:          addr.Name,
+					Tags:          addr.Labels,
+					Location:      lastSegment(addr.Region),
+					SelfLink:      addr.SelfLink,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	globalReq := svc.GlobalAddresses.List(projectID).Filter(infraIDFilter(infraID))
+
+	err = globalReq.Pages(ctx, func(page *compute.AddressList) error {
+		for _, addr := range page.Items {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformGCP,
+				Type:          infraType.CloudResourceTypeGCPAddress,
+				ID:            fmt.Sprintf("%d", addr.Id),
+				Name:          addr.Name,
+				Tags:          addr.Labels,
+				Location:      "global",
+				SelfLink:      addr.SelfLink,
+			})
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+func listLabeledImages(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	req := svc.Images.List(projectID).
+		Filter(fmt.Sprintf("labels.%s = %s", clusterLabelKey(infraID), clusterLabelValue))
+
+	err := req.Pages(ctx, func(page *compute.ImageList) error {
+		for _, image := range page.Items {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformGCP,
+				Type:          infraType.CloudResourceTypeGCPImage,
+				ID:            fmt.Sprintf("%d", image.Id),
+				Name:          image.Name,
+				Tags:          image.Labels,
+				Location:      "global",
+				SelfLink:      image.SelfLink,
+			})
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+// listClusterSnapshots returns snapshots that either carry the cluster label
+// or were taken from one of the cluster's disks. Snapshots created by the PD
+// CSI driver are only recognisable by their source disk.
+func listClusterSnapshots(ctx context.Context, svc *compute.Service, projectID, infraID string,
+	disks []infraType.CloudResource) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	clusterDisks := make(map[string]bool)
+	for _, disk := range disks {
+		clusterDisks[disk.SelfLink] = true
+	}
+
+	req := svc.Snapshots.List(projectID)
+
+	err := req.Pages(ctx, func(page *compute.SnapshotList) error {
+		for _, snapshot := range page.Items {
+			if !hasClusterLabel(snapshot.Labels, infraID) && !clusterDisks[snapshot.SourceDisk] {
+				continue
+			}
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformGCP,
+				Type:          infraType.CloudResourceTypeGCPSnapshot,
+				ID:            fmt.Sprintf("%d", snapshot.Id),
+				Name:          snapshot.Name,
+				Tags:          snapshot.Labels,
+				Location:      "global",
+				SelfLink:      snapshot.SelfLink,
+			})
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+func listClusterInstanceGroups(ctx context.Context, svc *compute.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	req := svc.InstanceGroups.AggregatedList(projectID).Filter(infraIDFilter(infraID))
+
+	err := req.Pages(ctx, func(page *compute.InstanceGroupAggregatedList) error {
+		for _, groups := range page.Items {
+			for _, ig := range groups.InstanceGroups {
+				resources = append(resources, infraType.CloudResource{
+					CloudProvider: infraType.CloudPlatformGCP,
+					Type:          infraType.CloudResourceTypeGCPInstanceGroup,
+					ID:            fmt.Sprintf("%d", ig.Id),
+					Name:          ig.Name,
+					Location:      lastSegment(ig.Zone),
+					SelfLink:      ig.SelfLink,
+					NotTaggable:   true,
 				})
 			}
 		}
@@ -245,7 +576,67 @@ func listLabeledLoadBalancers(ctx context.Context, svc *compute.Service, project
 	return resources, err
 }
 
-func listStorageResources(ctx context.Context, client *storage.Client, projectID string) ([]infraType.CloudResource, error) {
+// listServiceAccounts returns the installer-created service accounts, whose
+// account IDs start with the infraID. Service accounts cannot be labeled.
+func listServiceAccounts(ctx context.Context, svc *iam.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	req := svc.Projects.ServiceAccounts.List("projects/" + projectID)
+
+	err := req.Pages(ctx, func(page *iam.ListServiceAccountsResponse) error {
+		for _, sa := range page.Accounts {
+			if !strings.HasPrefix(sa.Email, infraID+"-") {
+				continue
+			}
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformGCP,
+				Type:          infraType.CloudResourceTypeGCPServiceAccount,
+				ID:            sa.UniqueId,
+				Name:          sa.Email,
+				Location:      "global",
+				SelfLink:      "https://iam.googleapis.com/v1/" + sa.Name,
+				NotTaggable:   true,
+			})
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+func listFilestoreInstances(ctx context.Context, svc *file.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	req := svc.Projects.Locations.Instances.List(fmt.Sprintf("projects/%s/locations/-", projectID))
+
+	err := req.Pages(ctx, func(page *file.ListInstancesResponse) error {
+		for _, instance := range page.Instances {
+			if !hasClusterLabel(instance.Labels, infraID) {
+				continue
+			}
+			// Filestore names have the form projects/P/locations/L/instances/N
+			parts := strings.Split(instance.Name, "/")
+			if len(parts) != 6 || parts[2] != "locations" || parts[4] != "instances" {
+				logr.FromContextOrDiscard(ctx).Info("skipping Filestore instance with an unexpected name", "name", instance.Name)
+				continue
+			}
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformGCP,
+				Type:          infraType.CloudResourceTypeGCPFilestore,
+				ID:            instance.Name,
+				Name:          parts[5],
+				Tags:          instance.Labels,
+				Location:      parts[3],
+				SelfLink:      "https://file.googleapis.com/v1/" + instance.Name,
+			})
+		}
+		return nil
+	})
+
+	return resources, err
+}
+
+func listStorageResources(ctx context.Context, client *storage.Client, projectID, infraID string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 
 	it := client.Buckets(ctx, projectID)
@@ -258,13 +649,15 @@ func listStorageResources(ctx context.Context, client *storage.Client, projectID
 			return nil, fmt.Errorf("bucket iteration error: %w", err)
 		}
 
-		if hasClusterLabel(bucket.Labels) {
+		if hasClusterLabel(bucket.Labels, infraID) {
 			resources = append(resources, infraType.CloudResource{
 				CloudProvider: infraType.CloudPlatformGCP,
 				Type:          infraType.CloudResourceTypeGCPStorageBucket,
 				ID:            bucket.Name,
 				Name:          bucket.Name,
 				Tags:          bucket.Labels,
+				Location:      bucket.Location,
+				SelfLink:      fmt.Sprintf("https://www.googleapis.com/storage/v1/b/%s", bucket.Name),
 			})
 		}
 	}
@@ -272,19 +665,21 @@ func listStorageResources(ctx context.Context, client *storage.Client, projectID
 	return resources, nil
 }
 
-func listDNSResources(ctx context.Context, svc *dns.Service, projectID string) ([]infraType.CloudResource, error) {
+func listDNSResources(ctx context.Context, svc *dns.Service, projectID, infraID string) ([]infraType.CloudResource, error) {
 	var resources []infraType.CloudResource
 
 	req := svc.ManagedZones.List(projectID)
 	err := req.Pages(ctx, func(page *dns.ManagedZonesListResponse) error {
 		for _, zone := range page.ManagedZones {
-			if hasClusterLabel(zone.Labels) {
+			if hasClusterLabel(zone.Labels, infraID) {
 				resources = append(resources, infraType.CloudResource{
 					CloudProvider: infraType.CloudPlatformGCP,
 					Type:          infraType.CloudResourceTypeGCPDNSZone,
 					ID:            fmt.Sprintf("%d", zone.Id),
 					Name:          zone.Name,
 					Tags:          zone.Labels,
+					Location:      "global",
+					SelfLink:      fmt.Sprintf("https://dns.googleapis.com/dns/v1/projects/%s/managedZones/%s", projectID, zone.Name),
 				})
 			}
 		}
@@ -294,26 +689,140 @@ func listDNSResources(ctx context.Context, svc *dns.Service, projectID string) (
 	return resources, err
 }
 
-func hasClusterLabel(labels map[string]string) bool {
-	return labels != nil && labels[clusterLabelKey] == clusterLabelValue
+func hasClusterLabel(labels map[string]string, infraID string) bool {
+	return labels != nil && labels[clusterLabelKey(infraID)] == clusterLabelValue
 }
 
-func getK8sClient() (client.Client, error) {
-	cfg, err := ctrl.GetConfig()
-	if err != nil {
-		return nil, fmt.Errorf("config error: %w", err)
+// lastSegment returns the final path element of a GCP resource URL, e.g. the
+// zone name from a zone selfLink.
+func lastSegment(url string) string {
+	return path.Base(url)
+}
+
+func regionOrGlobal(regionURL string) string {
+	if regionURL == "" {
+		return "global"
 	}
+	return lastSegment(regionURL)
+}
 
-	configv1.AddToScheme(scheme.Scheme)
-	k8sClient, err := client.New(cfg, client.Options{Scheme: scheme.Scheme})
-	if err != nil {
-		return nil, fmt.Errorf("client creation error: %w", err)
+// networkConfig describes the shared VPC a cluster was installed into, as
+// recorded in platform.gcp of the install-config.
+type networkConfig struct {
+	ProjectID          string `json:"networkProjectID"`
+	Network            string `json:"network"`
+	ControlPlaneSubnet string `json:"controlPlaneSubnet"`
+	ComputeSubnet      string `json:"computeSubnet"`
+}
+
+// getNetworkConfig reads the install-config the installer stores in
+// kube-system/cluster-config-v1. Clusters not installed into a shared VPC
+// return an empty ProjectID.
+func getNetworkConfig(ctx context.Context, k8sClient client.Client) (networkConfig, error) {
+	cm := &corev1.ConfigMap{}
+
+	if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "kube-system", Name: "cluster-config-v1"}, cm); err != nil {
+		return networkConfig{}, fmt.Errorf("install-config fetch error: %w", err)
+	}
+
+	var installConfig struct {
+		Platform struct {
+			GCP *networkConfig `json:"gcp"`
+		} `json:"platform"`
+	}
+	if err := yaml.Unmarshal([]byte(cm.Data["install-config"]), &installConfig); err != nil {
+		return networkConfig{}, fmt.Errorf("install-config parse error: %w", err)
+	}
+	if installConfig.Platform.GCP == nil {
+		return networkConfig{}, nil
 	}
-	return k8sClient, nil
+	return *installConfig.Platform.GCP, nil
 }
 
-func getClusterMetadata(k8sClient client.Client) (string, string, error) {
-	ctx := context.Background()
+// listNetworkProjectResources discovers what a shared-VPC cluster uses in the
+// host project: the VPC and subnets named in the install-config, the
+// installer's <infraID>- firewall rules and routes, and labeled DNS zones.
+func listNetworkProjectResources(ctx context.Context, computeSvc *compute.Service, dnsSvc *dns.Service,
+	netCfg networkConfig, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+	var errs []error
+
+	if netCfg.Network != "" {
+		network, err := computeSvc.Networks.Get(netCfg.ProjectID, netCfg.Network).Context(ctx).Do()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("network %s: %w", netCfg.Network, err))
+		} else {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformGCP,
+				Type:          infraType.CloudResourceTypeGCPNetwork,
+				ID:            fmt.Sprintf("%d", network.Id),
+				Name:          network.Name,
+				Location:      "global",
+				SelfLink:      network.SelfLink,
+				NotTaggable:   true,
+			})
+		}
+	}
+
+	for _, subnetName := range []string{netCfg.ControlPlaneSubnet, netCfg.ComputeSubnet} {
+		if subnetName == "" {
+			continue
+		}
+		req := computeSvc.Subnetworks.AggregatedList(netCfg.ProjectID).
+			Filter(fmt.Sprintf("name eq \"%s\"", subnetName))
+		err := req.Pages(ctx, func(page *compute.SubnetworkAggregatedList) error {
+			for _, subnets := range page.Items {
+				for _, subnet := range subnets.Subnetworks {
+					resources = append(resources, infraType.CloudResource{
+						CloudProvider: infraType.CloudPlatformGCP,
+						Type:          infraType.CloudResourceTypeGCPSubnet,
+						ID:            fmt.Sprintf("%d", subnet.Id),
+						Name:          subnet.Name,
+						Location:      lastSegment(subnet.Region),
+						SelfLink:      subnet.SelfLink,
+						NotTaggable:   true,
+					})
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("subnet %s: %w", subnetName, err))
+		}
+	}
+
+	if fwRes, err := listClusterFirewalls(ctx, computeSvc, netCfg.ProjectID, infraID); err == nil {
+		resources = append(resources, fwRes...)
+	} else {
+		errs = append(errs, err)
+	}
+	if routeRes, err := listClusterRoutes(ctx, computeSvc, netCfg.ProjectID, infraID); err == nil {
+		resources = append(resources, routeRes...)
+	} else {
+		errs = append(errs, err)
+	}
+	if dnsRes, err := listDNSResources(ctx, dnsSvc, netCfg.ProjectID, infraID); err == nil {
+		resources = append(resources, dnsRes...)
+	} else {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return resources, infraType.MultiError(errs)
+	}
+	return resources, nil
+}
+
+// setProject records the owning project on resources that don't have one yet.
+func setProject(resources []infraType.CloudResource, projectID string) {
+	for i := range resources {
+		if resources[i].Project == "" {
+			resources[i].Project = projectID
+		}
+	}
+}
+
+func getClusterMetadata(ctx context.Context, k8sClient client.Client) (string, string, error) {
 	infra := &configv1.Infrastructure{}
 
 	if err := k8sClient.Get(ctx, client.ObjectKey{Name: "cluster"}, infra); err != nil {
@@ -327,15 +836,37 @@ func getClusterMetadata(k8sClient client.Client) (string, string, error) {
 	return infra.Status.InfrastructureName, infra.Status.PlatformStatus.GCP.ProjectID, nil
 }
 
+type credentialsKey struct{}
+
+// WithCredentials returns a context that makes the package use creds instead
+// of the Application Default Credentials.
+func WithCredentials(ctx context.Context, creds *google.Credentials) context.Context {
+	return context.WithValue(ctx, credentialsKey{}, creds)
+}
+
 func getGCPCredentials(ctx context.Context) (*google.Credentials, error) {
+	if creds, ok := ctx.Value(credentialsKey{}).(*google.Credentials); ok {
+		return creds, nil
+	}
 	creds, err := google.FindDefaultCredentials(ctx, compute.CloudPlatformScope)
 	if err != nil {
-		return nil, fmt.Errorf("credentials error: %w", err)
+		return nil, fmt.Errorf("%w: %w", infraType.ErrCredentials, err)
 	}
 	return creds, nil
 }
 
-func UpdateResourceTags(resources infraType.CloudResource, tags map[string]string) error {
-	fmt.Println("Tags Update for GCP is not implemented yet")
-	return nil
+type clientOptionsKey struct{}
+
+// WithClientOptions returns a context that makes the package pass opts to
+// the Google API clients it creates, e.g. to talk to a test server.
+func WithClientOptions(ctx context.Context, opts ...option.ClientOption) context.Context {
+	return context.WithValue(ctx, clientOptionsKey{}, opts)
+}
+
+// clientOptions returns the options of a Google API client authenticating
+// with creds: opts, then those set with WithClientOptions.
+func clientOptions(ctx context.Context, creds *google.Credentials, opts ...option.ClientOption) []option.ClientOption {
+	all := append([]option.ClientOption{option.WithCredentials(creds)}, opts...)
+	extra, _ := ctx.Value(clientOptionsKey{}).([]option.ClientOption)
+	return append(all, extra...)
 }
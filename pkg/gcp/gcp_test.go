@@ -0,0 +1,49 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	file "google.golang.org/api/file/v1"
+	"google.golang.org/api/option"
+)
+
+func TestListFilestoreInstances(t *testing.T) {
+	const infraID = "mycluster-x7k2p"
+	labels := map[string]string{clusterLabelKey(infraID): "owned"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/projects/my-project/locations/-/instances" {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(file.ListInstancesResponse{Instances: []*file.Instance{
+			{Name: "projects/my-project/locations/us-central1-a/instances/registry", Labels: labels},
+			{Name: "projects/my-project/locations/us-central1-a/instances/other"},
+			{Name: "registry-without-path", Labels: labels},
+			{Name: "projects/my-project/instances/short", Labels: labels},
+		}})
+	}))
+	defer server.Close()
+
+	svc, err := file.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+	resources, err := listFilestoreInstances(context.Background(), svc, "my-project", infraID)
+	if err != nil {
+		t.Fatalf("listFilestoreInstances() error = %v", err)
+	}
+
+	var got []string
+	for _, res := range resources {
+		got = append(got, res.Location+" "+res.Name)
+	}
+	if want := []string{"us-central1-a registry"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("listFilestoreInstances() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,265 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	crm "google.golang.org/api/cloudresourcemanager/v3"
+	"google.golang.org/api/option"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// Resource Manager tags (tagKeys/tagValues) are bound to resources through
+// TagBindings instead of being stored on the resource like labels. Tags are
+// passed around keyed by namespaced tag key ("<org-id|project-id>/<key>")
+// with the tag value short name as value.
+
+// tagBindingResourceTypes lists the discovered resource types that accept
+// TagBindings.
+var tagBindingResourceTypes = map[infraType.CloudResourceType]bool{
+	infraType.CloudResourceTypeGCPComputeInstance: true,
+	infraType.CloudResourceTypeGCPDisk:            true,
+	infraType.CloudResourceTypeGCPImage:           true,
+	infraType.CloudResourceTypeGCPSnapshot:        true,
+	infraType.CloudResourceTypeGCPNetwork:         true,
+	infraType.CloudResourceTypeGCPSubnet:          true,
+	infraType.CloudResourceTypeGCPStorageBucket:   true,
+}
+
+// SupportsTagBindings reports whether TagBindings can be attached to the
+// resource.
+func SupportsTagBindings(resource infraType.CloudResource) bool {
+	return tagBindingResourceTypes[resource.Type]
+}
+
+// ResolveTagValues looks up the tagValues/<id> name of every namespaced tag
+// value. It fails if any key or value does not exist.
+func ResolveTagValues(ctx context.Context, tags map[string]string) (map[string]string, error) {
+
+	svc, err := newTagService(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := make(map[string]string)
+	for key, value := range tags {
+		namespaced := fmt.Sprintf("%s/%s", key, value)
+		tagValue, err := svc.TagValues.GetNamespaced().Name(namespaced).Context(ctx).Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve tag value %s: %w", namespaced, err)
+		}
+		resolved[key] = tagValue.Name
+	}
+	return resolved, nil
+}
+
+// GetResourceTagBindings returns the tags bound directly to the resource.
+// Tags inherited from the project, folder or organization are left out.
+func GetResourceTagBindings(ctx context.Context, resource infraType.CloudResource) (map[string]string, error) {
+
+	parent, err := fullResourceName(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := newTagService(ctx, resource.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string)
+	err = svc.EffectiveTags.List().Parent(parent).Pages(ctx, func(page *crm.ListEffectiveTagsResponse) error {
+		for _, tag := range page.EffectiveTags {
+			if tag.Inherited {
+				continue
+			}
+			tags[tag.NamespacedTagKey] = strings.TrimPrefix(tag.NamespacedTagValue, tag.NamespacedTagKey+"/")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tag bindings: %w", err)
+	}
+	return tags, nil
+}
+
+// UpdateResourceTagBindings binds the tag values to the resource and deletes
+// the bindings of the remove keys. The values are keyed by namespaced tag
+// key, as returned by ResolveTagValues, so that syncing many resources
+// resolves them once. A key can only carry one value per resource, so a
+// binding to a different value of the same key is deleted before the new one
+// is created. Every change waits for its long-running operation, so a binding
+// is only reported as changed once it is.
+func UpdateResourceTagBindings(ctx context.Context, resource infraType.CloudResource, resolved map[string]string,
+	remove []string) error {
+
+	if !SupportsTagBindings(resource) {
+		return fmt.Errorf("%s does not support tag bindings", resource.Type)
+	}
+
+	parent, err := fullResourceName(resource)
+	if err != nil {
+		return err
+	}
+
+	svc, err := newTagService(ctx, resource.Location)
+	if err != nil {
+		return err
+	}
+
+	// Effective tags tell us which key each direct binding belongs to, the
+	// bindings list gives us the binding names needed to delete them.
+	boundValues := make(map[string]string)
+	err = svc.EffectiveTags.List().Parent(parent).Pages(ctx, func(page *crm.ListEffectiveTagsResponse) error {
+		for _, tag := range page.EffectiveTags {
+			if !tag.Inherited {
+				boundValues[tag.NamespacedTagKey] = tag.TagValue
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list tag bindings: %w", err)
+	}
+
+	bindingNames := make(map[string]string)
+	err = svc.TagBindings.List().Parent(parent).Pages(ctx, func(page *crm.ListTagBindingsResponse) error {
+		for _, binding := range page.TagBindings {
+			bindingNames[binding.TagValue] = binding.Name
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list tag bindings: %w", err)
+	}
+
+	unbind := func(key string) error {
+		name, ok := bindingNames[boundValues[key]]
+		if !ok {
+			return fmt.Errorf("no tag binding found for %s", key)
+		}
+		op, err := svc.TagBindings.Delete(name).Context(ctx).Do()
+		if err == nil {
+			err = waitForOperation(ctx, svc, op)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to delete tag binding %s: %w", name, err)
+		}
+		return nil
+	}
+
+	for _, key := range remove {
+		if _, ok := boundValues[key]; !ok {
+			continue
+		}
+		if err := unbind(key); err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(resolved))
+	for key := range resolved {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		tagValue := resolved[key]
+		if current, ok := boundValues[key]; ok {
+			if current == tagValue {
+				continue
+			}
+			if err := unbind(key); err != nil {
+				return err
+			}
+		}
+
+		op, err := svc.TagBindings.Create(&crm.TagBinding{
+			Parent:   parent,
+			TagValue: tagValue,
+		}).Context(ctx).Do()
+		if err == nil {
+			err = waitForOperation(ctx, svc, op)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to bind %s to %s: %w", tagValue, key, err)
+		}
+	}
+	return nil
+}
+
+// operationPollInterval is how often waitForOperation polls.
+var operationPollInterval = 2 * time.Second
+
+// waitForOperation polls the long-running operation until it is done and
+// returns its error, if any.
+func waitForOperation(ctx context.Context, svc *crm.Service, op *crm.Operation) error {
+	for !op.Done {
+		select {
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		case <-time.After(operationPollInterval):
+		}
+
+		var err error
+		if op, err = svc.Operations.Get(op.Name).Context(ctx).Do(); err != nil {
+			return fmt.Errorf("failed to get operation: %w", err)
+		}
+	}
+	if op.Error != nil {
+		return fmt.Errorf("operation %s failed: %s", op.Name, op.Error.Message)
+	}
+	return nil
+}
+
+// newTagService returns a Resource Manager client. Bindings on zonal and
+// regional resources must go through the matching location endpoint.
+func newTagService(ctx context.Context, location string) (*crm.Service, error) {
+	creds, err := getGCPCredentials(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("GCP authentication error: %w", err)
+	}
+
+	var opts []option.ClientOption
+	if endpoint := tagEndpoint(location); endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+	}
+
+	svc, err := crm.NewService(ctx, clientOptions(ctx, creds, opts...)...)
+	if err != nil {
+		return nil, fmt.Errorf("resource manager service error: %w", err)
+	}
+	return svc, nil
+}
+
+// tagEndpoint returns the location endpoint of the Resource Manager for a
+// zone or region such as us-central1-a or us-central1, and "" for the global
+// endpoint. Multi-region and dual-region locations such as US, EU or NAM4,
+// which only buckets have, have no endpoint of their own.
+func tagEndpoint(location string) string {
+	if !strings.Contains(location, "-") {
+		return ""
+	}
+	return fmt.Sprintf("https://%s-cloudresourcemanager.googleapis.com/", strings.ToLower(location))
+}
+
+// fullResourceName converts a discovered resource into the
+// //service.googleapis.com/... form TagBindings expect.
+func fullResourceName(resource infraType.CloudResource) (string, error) {
+	if resource.Type == infraType.CloudResourceTypeGCPStorageBucket {
+		return "//storage.googleapis.com/projects/_/buckets/" + resource.Name, nil
+	}
+
+	const computePrefix = "https://www.googleapis.com/compute/v1/"
+	if !strings.HasPrefix(resource.SelfLink, computePrefix) {
+		return "", fmt.Errorf("cannot derive full resource name for %s (%s)", resource.Name, resource.Type)
+	}
+
+	// Compute resources are addressed by numeric ID rather than name.
+	path := strings.TrimPrefix(resource.SelfLink, computePrefix)
+	path = path[:strings.LastIndex(path, "/")+1] + resource.ID
+	return "//compute.googleapis.com/" + path, nil
+}
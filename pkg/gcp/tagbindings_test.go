@@ -0,0 +1,330 @@
+package gcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+	crm "google.golang.org/api/cloudresourcemanager/v3"
+	"google.golang.org/api/option"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+type tagValue struct {
+	key, value string
+}
+
+// fakeResourceManager is an HTTP stand-in for the tag values, effective
+// tags, tag bindings and operations of the Resource Manager v3 API, for a
+// single resource.
+type fakeResourceManager struct {
+	mu sync.Mutex
+	// values are the tag values by tagValues/<id> name.
+	values map[string]tagValue
+	// bound are the tag values bound to the resource by binding name.
+	bound map[string]string
+	// failing is the tag value whose binding operation fails.
+	failing string
+	// calls are the bindings created and deleted, as "create <tag value>"
+	// and "delete <tag value>".
+	calls []string
+	// operations are the pending operations and whether they fail.
+	operations map[string]bool
+}
+
+func (f *fakeResourceManager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/v3/")
+
+	switch {
+	case path == "tagValues/namespaced":
+		for name, v := range f.values {
+			if v.key+"/"+v.value == r.URL.Query().Get("name") {
+				writeJSON(w, crm.TagValue{Name: name, NamespacedName: v.key + "/" + v.value})
+				return
+			}
+		}
+		writeError(w, http.StatusForbidden, "PERMISSION_DENIED")
+	case path == "effectiveTags":
+		tags := []*crm.EffectiveTag{{
+			NamespacedTagKey:   "123/inherited",
+			NamespacedTagValue: "123/inherited/yes",
+			TagValue:           "tagValues/99",
+			Inherited:          true,
+		}}
+		for _, name := range sortedValues(f.bound) {
+			v := f.values[name]
+			tags = append(tags, &crm.EffectiveTag{
+				NamespacedTagKey:   v.key,
+				NamespacedTagValue: v.key + "/" + v.value,
+				TagValue:           name,
+			})
+		}
+		writeJSON(w, crm.ListEffectiveTagsResponse{EffectiveTags: tags})
+	case path == "tagBindings" && r.Method == http.MethodGet:
+		var bindings []*crm.TagBinding
+		for binding, value := range f.bound {
+			bindings = append(bindings, &crm.TagBinding{Name: binding, TagValue: value})
+		}
+		writeJSON(w, crm.ListTagBindingsResponse{TagBindings: bindings})
+	case path == "tagBindings" && r.Method == http.MethodPost:
+		var binding crm.TagBinding
+		if err := json.NewDecoder(r.Body).Decode(&binding); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		f.calls = append(f.calls, "create "+binding.TagValue)
+		if binding.TagValue != f.failing {
+			f.bound[fmt.Sprintf("tagBindings/%d", len(f.calls))] = binding.TagValue
+		}
+		writeJSON(w, f.operation(binding.TagValue == f.failing))
+	case strings.HasPrefix(path, "tagBindings/") && r.Method == http.MethodDelete:
+		value, ok := f.bound[path]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NOT_FOUND")
+			return
+		}
+		f.calls = append(f.calls, "delete "+value)
+		delete(f.bound, path)
+		writeJSON(w, f.operation(false))
+	case strings.HasPrefix(path, "operations/"):
+		op := &crm.Operation{Name: path, Done: true}
+		if f.operations[path] {
+			op.Error = &crm.Status{Code: 9, Message: "binding failed"}
+		}
+		writeJSON(w, op)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// operation returns a pending operation that waitForOperation has to poll.
+func (f *fakeResourceManager) operation(failing bool) *crm.Operation {
+	name := fmt.Sprintf("operations/%d", len(f.operations)+1)
+	f.operations[name] = failing
+	return &crm.Operation{Name: name}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, status string) {
+	w.WriteHeader(code)
+	writeJSON(w, map[string]interface{}{"error": map[string]interface{}{"code": code, "message": status, "status": status}})
+}
+
+func sortedValues(m map[string]string) []string {
+	values := make([]string, 0, len(m))
+	for _, v := range m {
+		values = append(values, v)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// startFakeResourceManager returns a context that points the package at a
+// fakeResourceManager.
+func startFakeResourceManager(t *testing.T, f *fakeResourceManager) context.Context {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	interval := operationPollInterval
+	operationPollInterval = time.Millisecond
+	t.Cleanup(func() { operationPollInterval = interval })
+
+	if f.bound == nil {
+		f.bound = make(map[string]string)
+	}
+	f.operations = make(map[string]bool)
+	ctx := WithCredentials(context.Background(), &google.Credentials{
+		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}),
+	})
+	return WithClientOptions(ctx, option.WithEndpoint(server.URL+"/"))
+}
+
+func testTagValues() map[string]tagValue {
+	return map[string]tagValue{
+		"tagValues/1": {key: "123/env", value: "prod"},
+		"tagValues/2": {key: "123/env", value: "dev"},
+		"tagValues/3": {key: "123/team", value: "infra"},
+	}
+}
+
+func TestTagEndpoint(t *testing.T) {
+	tests := []struct {
+		location string
+		want     string
+	}{
+		{location: "", want: ""},
+		{location: "global", want: ""},
+		{location: "US", want: ""},
+		{location: "EU", want: ""},
+		{location: "NAM4", want: ""},
+		{location: "us-central1", want: "https://us-central1-cloudresourcemanager.googleapis.com/"},
+		{location: "us-central1-a", want: "https://us-central1-a-cloudresourcemanager.googleapis.com/"},
+		{location: "EUROPE-WEST4", want: "https://europe-west4-cloudresourcemanager.googleapis.com/"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location, func(t *testing.T) {
+			if got := tagEndpoint(tt.location); got != tt.want {
+				t.Errorf("tagEndpoint(%q) = %q, want %q", tt.location, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFullResourceName(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource infraType.CloudResource
+		want     string
+		wantErr  bool
+	}{
+		{
+			name:     "bucket",
+			resource: infraType.CloudResource{Type: infraType.CloudResourceTypeGCPStorageBucket, Name: "mycluster-image-registry"},
+			want:     "//storage.googleapis.com/projects/_/buckets/mycluster-image-registry",
+		},
+		{
+			name: "instance by ID",
+			resource: infraType.CloudResource{
+				Type:     infraType.CloudResourceTypeGCPComputeInstance,
+				ID:       "1234567890",
+				Name:     "master-0",
+				SelfLink: "https://www.googleapis.com/compute/v1/projects/my-project/zones/us-central1-a/instances/master-0",
+			},
+			want: "//compute.googleapis.com/projects/my-project/zones/us-central1-a/instances/1234567890",
+		},
+		{
+			name:     "no self link",
+			resource: infraType.CloudResource{Type: infraType.CloudResourceTypeGCPDisk, Name: "disk"},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fullResourceName(tt.resource)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("fullResourceName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("fullResourceName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveTagValues(t *testing.T) {
+	ctx := startFakeResourceManager(t, &fakeResourceManager{values: testTagValues()})
+
+	resolved, err := ResolveTagValues(ctx, map[string]string{"123/env": "prod", "123/team": "infra"})
+	if err != nil {
+		t.Fatalf("ResolveTagValues() error = %v", err)
+	}
+	if want := map[string]string{"123/env": "tagValues/1", "123/team": "tagValues/3"}; !reflect.DeepEqual(resolved, want) {
+		t.Errorf("ResolveTagValues() = %v, want %v", resolved, want)
+	}
+
+	if _, err := ResolveTagValues(ctx, map[string]string{"123/env": "staging"}); err == nil {
+		t.Error("ResolveTagValues() of a missing value succeeded")
+	}
+}
+
+func TestUpdateResourceTagBindings(t *testing.T) {
+	tests := []struct {
+		name      string
+		bound     map[string]string
+		resolved  map[string]string
+		remove    []string
+		failing   string
+		wantCalls []string
+		wantBound []string
+		wantErr   bool
+	}{
+		{
+			name:      "new binding is created",
+			resolved:  map[string]string{"123/env": "tagValues/1"},
+			wantCalls: []string{"create tagValues/1"},
+			wantBound: []string{"tagValues/1"},
+		},
+		{
+			name:      "bound value is kept",
+			bound:     map[string]string{"tagBindings/b": "tagValues/1"},
+			resolved:  map[string]string{"123/env": "tagValues/1"},
+			wantBound: []string{"tagValues/1"},
+		},
+		{
+			name:      "other value is replaced",
+			bound:     map[string]string{"tagBindings/b": "tagValues/2"},
+			resolved:  map[string]string{"123/env": "tagValues/1"},
+			wantCalls: []string{"delete tagValues/2", "create tagValues/1"},
+			wantBound: []string{"tagValues/1"},
+		},
+		{
+			name:      "removed key is unbound",
+			bound:     map[string]string{"tagBindings/b": "tagValues/2", "tagBindings/c": "tagValues/3"},
+			remove:    []string{"123/team", "123/unbound"},
+			wantCalls: []string{"delete tagValues/3"},
+			wantBound: []string{"tagValues/2"},
+		},
+		{
+			name:      "failed operation is an error",
+			resolved:  map[string]string{"123/env": "tagValues/1", "123/team": "tagValues/3"},
+			failing:   "tagValues/1",
+			wantCalls: []string{"create tagValues/1"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeResourceManager{values: testTagValues(), bound: tt.bound, failing: tt.failing}
+			ctx := startFakeResourceManager(t, f)
+
+			resource := infraType.CloudResource{Type: infraType.CloudResourceTypeGCPStorageBucket, Name: "bucket"}
+			err := UpdateResourceTagBindings(ctx, resource, tt.resolved, tt.remove)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateResourceTagBindings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(f.calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", f.calls, tt.wantCalls)
+			}
+			if got := sortedValues(f.bound); !tt.wantErr && !reflect.DeepEqual(got, tt.wantBound) {
+				t.Errorf("bound = %q, want %q", got, tt.wantBound)
+			}
+		})
+	}
+}
+
+func TestRemoveResourceTagsUnbinds(t *testing.T) {
+	f := &fakeResourceManager{values: testTagValues(), bound: map[string]string{"tagBindings/b": "tagValues/3"}}
+	ctx := startFakeResourceManager(t, f)
+
+	// Only the binding is removed: without label keys, the labels are not
+	// written.
+	resource := infraType.CloudResource{Type: infraType.CloudResourceTypeGCPStorageBucket, Name: "bucket"}
+	if err := RemoveResourceTags(ctx, resource, []string{"123/team"}); err != nil {
+		t.Fatalf("RemoveResourceTags() error = %v", err)
+	}
+	if want := []string{"delete tagValues/3"}; !reflect.DeepEqual(f.calls, want) {
+		t.Errorf("calls = %q, want %q", f.calls, want)
+	}
+}
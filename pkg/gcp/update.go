@@ -0,0 +1,283 @@
+package gcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"golang.org/x/oauth2/google"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/dns/v1"
+	"google.golang.org/api/file/v1"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// UpdateResourceTags replaces the labels of a single GCP resource with the
+// given set. Callers are expected to pass the already merged labels.
+func UpdateResourceTags(ctx context.Context, resource infraType.CloudResource, labels map[string]string) error {
+
+	if resource.NotTaggable {
+		return fmt.Errorf("%s does not support labels", resource.Type)
+	}
+
+	creds, err := getGCPCredentials(ctx)
+	if err != nil {
+		return fmt.Errorf("GCP authentication error: %w", err)
+	}
+
+	projectID := resource.Project
+	if projectID == "" {
+		projectID = projectFromSelfLink(resource.SelfLink)
+	}
+
+	switch resource.Type {
+	case infraType.CloudResourceTypeGCPStorageBucket:
+		return updateBucketLabels(ctx, creds, resource, labels)
+	case infraType.CloudResourceTypeGCPDNSZone:
+		return updateDNSZoneLabels(ctx, creds, projectID, resource, labels)
+	case infraType.CloudResourceTypeGCPFilestore:
+		return updateFilestoreLabels(ctx, creds, resource, labels)
+	}
+
+	svc, err := compute.NewService(ctx, clientOptions(ctx, creds)...)
+	if err != nil {
+		return fmt.Errorf("compute service error: %w", err)
+	}
+
+	switch resource.Type {
+	case infraType.CloudResourceTypeGCPComputeInstance:
+		return updateInstanceLabels(ctx, svc, projectID, resource, labels)
+	case infraType.CloudResourceTypeGCPDisk:
+		return updateDiskLabels(ctx, svc, projectID, resource, labels)
+	case infraType.CloudResourceTypeGCPLoadBalancer:
+		return updateForwardingRuleLabels(ctx, svc, projectID, resource, labels)
+	case infraType.CloudResourceTypeGCPAddress:
+		return updateAddressLabels(ctx, svc, projectID, resource, labels)
+	case infraType.CloudResourceTypeGCPImage:
+		return updateImageLabels(ctx, svc, projectID, resource, labels)
+	case infraType.CloudResourceTypeGCPSnapshot:
+		return updateSnapshotLabels(ctx, svc, projectID, resource, labels)
+	default:
+		return fmt.Errorf("unsupported resource type: %s", resource.Type)
+	}
+}
+
+// Compute label updates are optimistic-locked on the current label
+// fingerprint, so every update re-reads the resource first.
+
+func updateInstanceLabels(ctx context.Context, svc *compute.Service, projectID string,
+	resource infraType.CloudResource, labels map[string]string) error {
+
+	instance, err := svc.Instances.Get(projectID, resource.Location, resource.Name).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get instance: %w", err)
+	}
+
+	_, err = svc.Instances.SetLabels(projectID, resource.Location, resource.Name, &compute.InstancesSetLabelsRequest{
+		Labels:           labels,
+		LabelFingerprint: instance.LabelFingerprint,
+	}).Context(ctx).Do()
+	return err
+}
+
+func updateDiskLabels(ctx context.Context, svc *compute.Service, projectID string,
+	resource infraType.CloudResource, labels map[string]string) error {
+
+	disk, err := svc.Disks.Get(projectID, resource.Location, resource.Name).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get disk: %w", err)
+	}
+
+	_, err = svc.Disks.SetLabels(projectID, resource.Location, resource.Name, &compute.ZoneSetLabelsRequest{
+		Labels:           labels,
+		LabelFingerprint: disk.LabelFingerprint,
+	}).Context(ctx).Do()
+	return err
+}
+
+func updateForwardingRuleLabels(ctx context.Context, svc *compute.Service, projectID string,
+	resource infraType.CloudResource, labels map[string]string) error {
+
+	if resource.Location == "global" {
+		rule, err := svc.GlobalForwardingRules.Get(projectID, resource.Name).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to get forwarding rule: %w", err)
+		}
+
+		_, err = svc.GlobalForwardingRules.SetLabels(projectID, resource.Name, &compute.GlobalSetLabelsRequest{
+			Labels:           labels,
+			LabelFingerprint: rule.LabelFingerprint,
+		}).Context(ctx).Do()
+		return err
+	}
+
+	rule, err := svc.ForwardingRules.Get(projectID, resource.Location, resource.Name).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get forwarding rule: %w", err)
+	}
+
+	_, err = svc.ForwardingRules.SetLabels(projectID, resource.Location, resource.Name, &compute.RegionSetLabelsRequest{
+		Labels:           labels,
+		LabelFingerprint: rule.LabelFingerprint,
+	}).Context(ctx).Do()
+	return err
+}
+
+func updateAddressLabels(ctx context.Context, svc *compute.Service, projectID string,
+	resource infraType.CloudResource, labels map[string]string) error {
+
+	if resource.Location == "global" {
+		addr, err := svc.GlobalAddresses.Get(projectID, resource.Name).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("failed to get address: %w", err)
+		}
+
+		_, err = svc.GlobalAddresses.SetLabels(projectID, resource.Name, &compute.GlobalSetLabelsRequest{
+			Labels:           labels,
+			LabelFingerprint: addr.LabelFingerprint,
+		}).Context(ctx).Do()
+		return err
+	}
+
+	addr, err := svc.Addresses.Get(projectID, resource.Location, resource.Name).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get address: %w", err)
+	}
+
+	_, err = svc.Addresses.SetLabels(projectID, resource.Location, resource.Name, &compute.RegionSetLabelsRequest{
+		Labels:           labels,
+		LabelFingerprint: addr.LabelFingerprint,
+	}).Context(ctx).Do()
+	return err
+}
+
+func updateImageLabels(ctx context.Context, svc *compute.Service, projectID string,
+	resource infraType.CloudResource, labels map[string]string) error {
+
+	image, err := svc.Images.Get(projectID, resource.Name).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get image: %w", err)
+	}
+
+	_, err = svc.Images.SetLabels(projectID, resource.Name, &compute.GlobalSetLabelsRequest{
+		Labels:           labels,
+		LabelFingerprint: image.LabelFingerprint,
+	}).Context(ctx).Do()
+	return err
+}
+
+func updateSnapshotLabels(ctx context.Context, svc *compute.Service, projectID string,
+	resource infraType.CloudResource, labels map[string]string) error {
+
+	snapshot, err := svc.Snapshots.Get(projectID, resource.Name).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot: %w", err)
+	}
+
+	_, err = svc.Snapshots.SetLabels(projectID, resource.Name, &compute.GlobalSetLabelsRequest{
+		Labels:           labels,
+		LabelFingerprint: snapshot.LabelFingerprint,
+	}).Context(ctx).Do()
+	return err
+}
+
+func updateBucketLabels(ctx context.Context, creds *google.Credentials,
+	resource infraType.CloudResource, labels map[string]string) error {
+
+	client, err := storage.NewClient(ctx, clientOptions(ctx, creds)...)
+	if err != nil {
+		return fmt.Errorf("storage client error: %w", err)
+	}
+	defer client.Close()
+
+	var update storage.BucketAttrsToUpdate
+	for k, v := range labels {
+		update.SetLabel(k, v)
+	}
+	for k := range resource.Tags {
+		if _, ok := labels[k]; !ok {
+			update.DeleteLabel(k)
+		}
+	}
+
+	_, err = client.Bucket(resource.Name).Update(ctx, update)
+	return err
+}
+
+func updateDNSZoneLabels(ctx context.Context, creds *google.Credentials, projectID string,
+	resource infraType.CloudResource, labels map[string]string) error {
+
+	svc, err := dns.NewService(ctx, clientOptions(ctx, creds)...)
+	if err != nil {
+		return fmt.Errorf("dns service error: %w", err)
+	}
+
+	// Patch skips empty fields unless forced, which would keep the last
+	// label of a zone from being removed.
+	_, err = svc.ManagedZones.Patch(projectID, resource.Name, &dns.ManagedZone{
+		Labels:          labels,
+		ForceSendFields: []string{"Labels"},
+	}).Context(ctx).Do()
+	return err
+}
+
+func updateFilestoreLabels(ctx context.Context, creds *google.Credentials,
+	resource infraType.CloudResource, labels map[string]string) error {
+
+	svc, err := file.NewService(ctx, clientOptions(ctx, creds)...)
+	if err != nil {
+		return fmt.Errorf("filestore service error: %w", err)
+	}
+
+	_, err = svc.Projects.Locations.Instances.Patch(resource.ID, &file.Instance{
+		Labels: labels,
+	}).UpdateMask("labels").Context(ctx).Do()
+	return err
+}
+
+// projectFromSelfLink extracts the project ID from a resource URL of the form
+// .../projects/<project>/...
+func projectFromSelfLink(selfLink string) string {
+	parts := strings.Split(selfLink, "/")
+	for i := 0; i < len(parts)-1; i++ {
+		if parts[i] == "projects" {
+			return parts[i+1]
+		}
+	}
+	return ""
+}
+
+// RemoveResourceTags deletes the given label keys from a single GCP
+// resource, keeping its other labels. Namespaced tag keys
+// ("<org-id|project-id>/<key>") delete the resource's tag binding of the key
+// instead.
+func RemoveResourceTags(ctx context.Context, resource infraType.CloudResource, keys []string) error {
+	// Labels can not contain "/", namespaced tag keys always do.
+	var labelKeys, bindingKeys []string
+	for _, k := range keys {
+		if strings.Contains(k, "/") {
+			bindingKeys = append(bindingKeys, k)
+		} else {
+			labelKeys = append(labelKeys, k)
+		}
+	}
+	if len(bindingKeys) > 0 && SupportsTagBindings(resource) {
+		if err := UpdateResourceTagBindings(ctx, resource, nil, bindingKeys); err != nil {
+			return err
+		}
+	}
+	if len(labelKeys) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string)
+	for k, v := range resource.Tags {
+		labels[k] = v
+	}
+	for _, k := range labelKeys {
+		delete(labels, k)
+	}
+	return UpdateResourceTags(ctx, resource, labels)
+}
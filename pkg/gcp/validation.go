@@ -1,9 +1,11 @@
 package gcp
 
 import (
-	"errors"
 	"fmt"
 	"regexp"
+	"strings"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
 )
 
 const (
@@ -16,7 +18,7 @@ const (
 // isValidGCPTag validates GCP tags
 func IsValidGCPTag(tags map[string]string) error {
 	if len(tags) > gcpMaxTags {
-		return errors.New("GCP allows a maximum of 64 tags per resource")
+		return fmt.Errorf("%w: GCP allows a maximum of %d labels per resource", infraType.ErrTagLimitExceeded, gcpMaxTags)
 	}
 	re := regexp.MustCompile(gcpKeyPattern)
 	for key, value := range tags {
@@ -29,3 +31,31 @@ func IsValidGCPTag(tags map[string]string) error {
 	}
 	return nil
 }
+
+const (
+	gcpMaxTagBindings      = 50
+	gcpTagShortNamePattern = "^[a-zA-Z0-9]([a-zA-Z0-9._-]{0,61}[a-zA-Z0-9])?$"
+)
+
+// IsValidGCPResourceTag validates Resource Manager tags, given as namespaced
+// tag key ("<org-id|project-id>/<short-name>") mapped to a tag value short
+// name.
+func IsValidGCPResourceTag(tags map[string]string) error {
+	if len(tags) > gcpMaxTagBindings {
+		return fmt.Errorf("%w: GCP allows a maximum of %d tags bound per resource", infraType.ErrTagLimitExceeded, gcpMaxTagBindings)
+	}
+	re := regexp.MustCompile(gcpTagShortNamePattern)
+	for key, value := range tags {
+		parent, shortName, found := strings.Cut(key, "/")
+		if !found || parent == "" {
+			return fmt.Errorf("GCP tag key %q must be namespaced as <org-id|project-id>/<short-name>", key)
+		}
+		if !re.MatchString(shortName) {
+			return fmt.Errorf("GCP tag key short name must match pattern %s", gcpTagShortNamePattern)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("GCP tag value short name must match pattern %s", gcpTagShortNamePattern)
+		}
+	}
+	return nil
+}
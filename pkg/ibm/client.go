@@ -0,0 +1,190 @@
+package ibm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// Endpoint overrides use the same environment variables as the IBM Cloud
+// terraform provider and CLI, which also makes it possible to point the
+// provider at a local HTTP stand-in.
+const (
+	iamEndpointEnv                = "IBMCLOUD_IAM_API_ENDPOINT"
+	vpcEndpointEnv                = "IBMCLOUD_IS_NG_API_ENDPOINT"
+	resourceControllerEndpointEnv = "IBMCLOUD_RESOURCE_CONTROLLER_API_ENDPOINT"
+	resourceManagerEndpointEnv    = "IBMCLOUD_RESOURCE_MANAGEMENT_API_ENDPOINT"
+	taggingEndpointEnv            = "IBMCLOUD_GT_API_ENDPOINT"
+	cosEndpointEnv                = "IBMCLOUD_COS_ENDPOINT"
+	powerVSEndpointEnv            = "IBMCLOUD_PI_API_ENDPOINT"
+)
+
+// vpcAPIVersion pins the dated version the VPC API requires on every call.
+const vpcAPIVersion = "2024-04-30"
+
+type endpoints struct {
+	iam                string
+	vpc                string
+	resourceController string
+	resourceManager    string
+	tagging            string
+	cos                string
+	powerVS            string
+}
+
+// apiClient is a minimal IBM Cloud REST client authenticated with an IAM
+// access token obtained from an API key.
+type apiClient struct {
+	httpClient *http.Client
+	apiKey     string
+	token      string
+	endpoints  endpoints
+}
+
+func newClient(region string) (*apiClient, error) {
+	apiKey := os.Getenv("IC_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("IBMCLOUD_API_KEY")
+	}
+	if apiKey == "" {
+		return nil, fmt.Errorf("IC_API_KEY or IBMCLOUD_API_KEY environment variable not set")
+	}
+
+	return &apiClient{
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		apiKey:     apiKey,
+		endpoints: endpoints{
+			iam:                endpointFromEnv(iamEndpointEnv, "https://iam.cloud.ibm.com"),
+			vpc:                endpointFromEnv(vpcEndpointEnv, fmt.Sprintf("https://%s.iaas.cloud.ibm.com/v1", region)),
+			resourceController: endpointFromEnv(resourceControllerEndpointEnv, "https://resource-controller.cloud.ibm.com"),
+			resourceManager:    endpointFromEnv(resourceManagerEndpointEnv, "https://resource-controller.cloud.ibm.com"),
+			tagging:            endpointFromEnv(taggingEndpointEnv, "https://tags.global-search-tagging.cloud.ibm.com"),
+			cos:                endpointFromEnv(cosEndpointEnv, fmt.Sprintf("https://s3.%s.cloud-object-storage.appdomain.cloud", region)),
+			powerVS:            endpointFromEnv(powerVSEndpointEnv, fmt.Sprintf("https://%s.power-iaas.cloud.ibm.com", region)),
+		},
+	}, nil
+}
+
+func endpointFromEnv(env, fallback string) string {
+	if v := os.Getenv(env); v != "" {
+		return strings.TrimSuffix(v, "/")
+	}
+	return fallback
+}
+
+// authenticate exchanges the API key for an IAM access token.
+func (c *apiClient) authenticate(ctx context.Context) error {
+	if c.token != "" {
+		return nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ibm:params:oauth:grant-type:apikey")
+	form.Set("apikey", c.apiKey)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		c.endpoints.iam+"/identity/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("IAM token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: IAM token request failed: %s: %s", infraType.ErrCredentials, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("failed to decode IAM token: %w", err)
+	}
+	c.token = token.AccessToken
+	return nil
+}
+
+// do sends an authenticated request and returns the response body. Non-2xx
+// responses are returned as errors.
+func (c *apiClient) do(ctx context.Context, method, rawURL string, body interface{}, header http.Header) ([]byte, error) {
+	if err := c.authenticate(ctx); err != nil {
+		return nil, err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &infraType.StatusError{
+			Method:     method,
+			Path:       req.URL.Path,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(data)),
+		}
+	}
+	return data, nil
+}
+
+func (c *apiClient) getJSON(ctx context.Context, rawURL string, out interface{}) error {
+	data, err := c.do(ctx, http.MethodGet, rawURL, nil, nil)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+func (c *apiClient) postJSON(ctx context.Context, rawURL string, body, out interface{}) error {
+	data, err := c.do(ctx, http.MethodPost, rawURL, body, nil)
+	if err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
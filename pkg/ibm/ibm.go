@@ -0,0 +1,223 @@
+package ibm
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// cosServiceID is the resource controller ID of Cloud Object Storage.
+const cosServiceID = "dff97f5c-bc5e-4455-b470-411c3edbe49c"
+
+// vpcCollections maps the VPC API collections we discover to resource types.
+var vpcCollections = []struct {
+	path      string
+	cloudType infraType.CloudResourceType
+}{
+	{"instances", infraType.CloudResourceTypeIBMVPCInstance},
+	{"volumes", infraType.CloudResourceTypeIBMVolume},
+	{"load_balancers", infraType.CloudResourceTypeIBMLoadBalancer},
+	{"security_groups", infraType.CloudResourceTypeIBMSecurityGroup},
+}
+
+// ListIBMResources lists the VPC and COS resources in the cluster's
+// resource group. Resources are identified by CRN.
+func ListIBMResources(ctx context.Context, k8sClient client.Client) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	region, resourceGroupName, err := getClusterResourceGroup(ctx, k8sClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster info: %w", err)
+	}
+
+	c, err := newClient(region)
+	if err != nil {
+		return nil, fmt.Errorf("IBM Cloud authentication error: %w", err)
+	}
+
+	resourceGroupID, err := getResourceGroupID(ctx, c, resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, collection := range vpcCollections {
+		if vpcRes, err := listVPCResources(ctx, c, collection.path, collection.cloudType, resourceGroupID); err == nil {
+			resources = append(resources, vpcRes...)
+		}
+	}
+	if bucketRes, err := listCOSBuckets(ctx, c, resourceGroupID); err == nil {
+		resources = append(resources, bucketRes...)
+	}
+
+	for i := range resources {
+		tags, err := getUserTags(ctx, c, resources[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		resources[i].Tags = tags
+	}
+
+	return resources, nil
+}
+
+func getResourceGroupID(ctx context.Context, c *apiClient, name string) (string, error) {
+	var page struct {
+		Resources []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"resources"`
+	}
+
+	u := fmt.Sprintf("%s/v2/resource_groups?name=%s", c.endpoints.resourceManager, url.QueryEscape(name))
+	if err := c.getJSON(ctx, u, &page); err != nil {
+		return "", fmt.Errorf("failed to look up resource group %s: %w", name, err)
+	}
+	for _, rg := range page.Resources {
+		if rg.Name == name {
+			return rg.ID, nil
+		}
+	}
+	return "", fmt.Errorf("resource group %s not found", name)
+}
+
+// listVPCResources pages through a VPC API collection. Not every collection
+// accepts a resource_group.id filter, so the group is also checked on each
+// item.
+func listVPCResources(ctx context.Context, c *apiClient, path string, cloudType infraType.CloudResourceType,
+	resourceGroupID string) ([]infraType.CloudResource, error) {
+
+	var resources []infraType.CloudResource
+
+	next := fmt.Sprintf("%s/%s?version=%s&generation=2&limit=100&resource_group.id=%s",
+		c.endpoints.vpc, path, vpcAPIVersion, url.QueryEscape(resourceGroupID))
+	for next != "" {
+		var page map[string]json.RawMessage
+		if err := c.getJSON(ctx, next, &page); err != nil {
+			return nil, fmt.Errorf("error listing %s: %w", path, err)
+		}
+
+		var items []vpcItem
+		if err := json.Unmarshal(page[path], &items); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", path, err)
+		}
+		for _, item := range items {
+			if item.ResourceGroup.ID != resourceGroupID {
+				continue
+			}
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformIBM,
+				Type:          cloudType,
+				ID:            item.CRN,
+				Name:          item.Name,
+				SelfLink:      item.Href,
+			})
+		}
+
+		next = ""
+		if raw, ok := page["next"]; ok {
+			var pagination struct {
+				Href string `json:"href"`
+			}
+			if err := json.Unmarshal(raw, &pagination); err != nil {
+				return nil, fmt.Errorf("error decoding %s pagination: %w", path, err)
+			}
+			next = pagination.Href
+		}
+	}
+	return resources, nil
+}
+
+type vpcItem struct {
+	ID            string `json:"id"`
+	CRN           string `json:"crn"`
+	Name          string `json:"name"`
+	Href          string `json:"href"`
+	ResourceGroup struct {
+		ID string `json:"id"`
+	} `json:"resource_group"`
+}
+
+// listCOSBuckets lists the buckets of every COS instance in the resource
+// group. Bucket CRNs are derived from the owning instance CRN.
+func listCOSBuckets(ctx context.Context, c *apiClient, resourceGroupID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	type cosInstance struct {
+		GUID string `json:"guid"`
+		CRN  string `json:"crn"`
+	}
+	var instances []cosInstance
+
+	next := fmt.Sprintf("/v2/resource_instances?resource_group_id=%s&resource_id=%s",
+		url.QueryEscape(resourceGroupID), cosServiceID)
+	for next != "" {
+		var page struct {
+			Resources []cosInstance `json:"resources"`
+			NextURL   string        `json:"next_url"`
+		}
+		if err := c.getJSON(ctx, c.endpoints.resourceController+next, &page); err != nil {
+			return nil, fmt.Errorf("error listing COS instances: %w", err)
+		}
+		instances = append(instances, page.Resources...)
+		next = page.NextURL
+	}
+
+	for _, instance := range instances {
+		data, err := c.do(ctx, http.MethodGet, c.endpoints.cos+"/", nil,
+			http.Header{"Ibm-Service-Instance-Id": []string{instance.GUID}})
+		if err != nil {
+			return nil, fmt.Errorf("error listing buckets of %s: %w", instance.GUID, err)
+		}
+
+		var result struct {
+			Buckets []struct {
+				Name string `xml:"Name"`
+			} `xml:"Buckets>Bucket"`
+		}
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("error decoding buckets of %s: %w", instance.GUID, err)
+		}
+
+		for _, bucket := range result.Buckets {
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformIBM,
+				Type:          infraType.CloudResourceTypeIBMCOSBucket,
+				ID:            bucketCRN(instance.CRN, bucket.Name),
+				Name:          bucket.Name,
+			})
+		}
+	}
+	return resources, nil
+}
+
+// bucketCRN turns crn:v1:...:cloud-object-storage:global:a/<account>:<guid>::
+// into the CRN of one of its buckets.
+func bucketCRN(instanceCRN, bucket string) string {
+	return strings.TrimSuffix(instanceCRN, "::") + ":bucket:" + bucket
+}
+
+// getClusterResourceGroup returns the region and resource group name of the
+// cluster.
+func getClusterResourceGroup(ctx context.Context, k8sClient client.Client) (string, string, error) {
+	infra := &configv1.Infrastructure{}
+	if err := k8sClient.Get(ctx,
+		client.ObjectKey{Name: "cluster"}, infra); err != nil {
+		return "", "", fmt.Errorf("failed to get Infrastructure: %w", err)
+	}
+
+	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.IBMCloud == nil {
+		return "", "", fmt.Errorf("IBM Cloud platform status not found")
+	}
+
+	status := infra.Status.PlatformStatus.IBMCloud
+	return status.Location, status.ResourceGroupName, nil
+}
@@ -0,0 +1,229 @@
+package ibm
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// fakeIBMCloud is an HTTP stand-in for the IAM, resource manager, resource
+// controller, VPC and global tagging APIs.
+type fakeIBMCloud struct {
+	mu sync.Mutex
+	// instances are returned by the VPC instances collection.
+	instances []vpcItem
+	// workspaces are the PowerVS service instances and pvmInstances the
+	// PVM instances of each, by GUID.
+	workspaces   []map[string]string
+	pvmInstances map[string][]map[string]string
+	// tags are the user tags attached to each CRN.
+	tags map[string][]string
+	// failing makes attach or detach report is_error.
+	failing string
+	// calls are the tag changes made, as "<action> <tag>,<tag>".
+	calls []string
+	// tagged are the resources tags were changed on, as
+	// "<tag_type> <crn>".
+	tagged []string
+	// vpcQueries are the query strings of the VPC list calls.
+	vpcQueries []string
+}
+
+func (f *fakeIBMCloud) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/identity/token":
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "token"})
+	case r.URL.Path == "/v2/resource_groups":
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"resources": []map[string]string{{"id": "rg-other", "name": "other"}, {"id": "rg-1", "name": r.URL.Query().Get("name")}},
+		})
+	case r.URL.Path == "/v2/resource_instances":
+		resources := []map[string]string{}
+		if r.URL.Query().Get("resource_id") == powerVSServiceID {
+			resources = f.workspaces
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"resources": resources})
+	case strings.HasPrefix(r.URL.Path, "/pcloud/v1/cloud-instances/"):
+		guid := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/pcloud/v1/cloud-instances/"), "/pvm-instances")
+		if r.Header.Get("Crn") == "" {
+			http.Error(w, "missing CRN header", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"pvmInstances": f.pvmInstances[guid]})
+	case strings.HasPrefix(r.URL.Path, "/v1/"):
+		collection := strings.TrimPrefix(r.URL.Path, "/v1/")
+		f.vpcQueries = append(f.vpcQueries, r.URL.RawQuery)
+		items := []vpcItem{}
+		if collection == "instances" {
+			items = f.instances
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{collection: items})
+	case r.URL.Path == "/v3/tags" && r.Method == http.MethodGet:
+		var items []map[string]string
+		for _, tag := range f.tags[r.URL.Query().Get("attached_to")] {
+			items = append(items, map[string]string{"name": tag})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"items": items})
+	case r.URL.Path == "/v3/tags/attach" || r.URL.Path == "/v3/tags/detach":
+		action := strings.TrimPrefix(r.URL.Path, "/v3/tags/")
+		var req tagRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.calls = append(f.calls, action+" "+strings.Join(req.TagNames, ","))
+		var results tagResults
+		for _, res := range req.Resources {
+			f.tagged = append(f.tagged, r.URL.Query().Get("tag_type")+" "+res.ResourceID)
+			results.Results = append(results.Results, struct {
+				ResourceID string `json:"resource_id"`
+				IsError    bool   `json:"is_error"`
+			}{ResourceID: res.ResourceID, IsError: f.failing == action})
+		}
+		_ = json.NewEncoder(w).Encode(results)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// startFakeIBMCloud points the endpoint overrides at a fakeIBMCloud.
+func startFakeIBMCloud(t *testing.T, f *fakeIBMCloud) {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	t.Setenv("IC_API_KEY", "key")
+	for _, env := range []string{iamEndpointEnv, resourceControllerEndpointEnv, resourceManagerEndpointEnv,
+		taggingEndpointEnv, cosEndpointEnv, powerVSEndpointEnv} {
+		t.Setenv(env, server.URL)
+	}
+	t.Setenv(vpcEndpointEnv, server.URL+"/v1")
+}
+
+func TestListIBMResourcesResourceGroup(t *testing.T) {
+	f := &fakeIBMCloud{
+		instances: []vpcItem{
+			{CRN: "crn:instance-1", Name: "master-0"},
+			{CRN: "crn:instance-2", Name: "elsewhere"},
+		},
+		tags: map[string][]string{"crn:instance-1": {"Owner:DevOps", "kubernetes"}},
+	}
+	f.instances[0].ResourceGroup.ID = "rg-1"
+	f.instances[1].ResourceGroup.ID = "rg-other"
+	startFakeIBMCloud(t, f)
+
+	scheme := runtime.NewScheme()
+	if err := configv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{
+				Type:     configv1.IBMCloudPlatformType,
+				IBMCloud: &configv1.IBMCloudPlatformStatus{Location: "us-south", ResourceGroupName: "mycluster-rg"},
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).WithStatusSubresource(infra).Build()
+
+	resources, err := ListIBMResources(context.Background(), k8sClient)
+	if err != nil {
+		t.Fatalf("ListIBMResources() error = %v", err)
+	}
+
+	want := []infraType.CloudResource{{
+		CloudProvider: infraType.CloudPlatformIBM,
+		Type:          infraType.CloudResourceTypeIBMVPCInstance,
+		ID:            "crn:instance-1",
+		Name:          "master-0",
+		Tags:          map[string]string{"Owner": "DevOps", "kubernetes": ""},
+	}}
+	if !reflect.DeepEqual(resources, want) {
+		t.Errorf("ListIBMResources() = %+v, want %+v", resources, want)
+	}
+	for _, query := range f.vpcQueries {
+		if !strings.Contains(query, "resource_group.id=rg-1") {
+			t.Errorf("VPC list %q is not scoped to the resource group", query)
+		}
+	}
+}
+
+func TestUpdateResourceTags(t *testing.T) {
+	tests := []struct {
+		name      string
+		current   map[string]string
+		tags      map[string]string
+		failing   string
+		wantCalls []string
+		wantErr   bool
+	}{
+		{
+			name:      "new tags are attached",
+			current:   map[string]string{"kubernetes": ""},
+			tags:      map[string]string{"Owner": "DevOps", "Team": "infra"},
+			wantCalls: []string{"attach Owner:DevOps,Team:infra"},
+		},
+		{
+			name:      "changed value is detached then attached",
+			current:   map[string]string{"Owner": "Old", "Team": "infra"},
+			tags:      map[string]string{"Owner": "DevOps", "Team": "infra"},
+			wantCalls: []string{"detach Owner:Old", "attach Owner:DevOps"},
+		},
+		{
+			name:    "up to date resource is not written",
+			current: map[string]string{"Owner": "DevOps"},
+			tags:    map[string]string{"Owner": "DevOps"},
+		},
+		{
+			name:      "attach is_error fails",
+			tags:      map[string]string{"Owner": "DevOps"},
+			failing:   "attach",
+			wantCalls: []string{"attach Owner:DevOps"},
+			wantErr:   true,
+		},
+		{
+			name:      "detach is_error fails before attaching",
+			current:   map[string]string{"Owner": "Old"},
+			tags:      map[string]string{"Owner": "DevOps"},
+			failing:   "detach",
+			wantCalls: []string{"detach Owner:Old"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeIBMCloud{failing: tt.failing}
+			startFakeIBMCloud(t, f)
+
+			resources := []infraType.CloudResource{{
+				CloudProvider: infraType.CloudPlatformIBM,
+				Type:          infraType.CloudResourceTypeIBMVPCInstance,
+				ID:            "crn:instance-1",
+				Tags:          tt.current,
+			}}
+			err := UpdateResourceTags(context.Background(), resources, tt.tags)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateResourceTags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(f.calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", f.calls, tt.wantCalls)
+			}
+		})
+	}
+}
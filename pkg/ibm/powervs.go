@@ -0,0 +1,158 @@
+package ibm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// powerVSServiceID is the resource controller ID of Power Virtual Server.
+const powerVSServiceID = "abd259f0-9990-11e8-acc8-b9f54a8f1661"
+
+// ListPowerVSResources lists the cluster's PowerVS workspaces (service
+// instances named after the infraID) and the PVM instances in them. Both
+// are CRN resources, so UpdateResourceTags applies to them unchanged.
+func ListPowerVSResources(ctx context.Context, k8sClient client.Client) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	infraID, region, resourceGroupName, err := getPowerVSClusterInfo(ctx, k8sClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster info: %w", err)
+	}
+
+	c, err := newClient(region)
+	if err != nil {
+		return nil, fmt.Errorf("IBM Cloud authentication error: %w", err)
+	}
+
+	resourceGroupID, err := getResourceGroupID(ctx, c, resourceGroupName)
+	if err != nil {
+		return nil, err
+	}
+
+	workspaces, err := listWorkspaces(ctx, c, resourceGroupID, infraID)
+	if err != nil {
+		return nil, err
+	}
+	resources = append(resources, workspaces...)
+
+	for _, workspace := range workspaces {
+		if instanceRes, err := listPVMInstances(ctx, c, workspace, infraID); err == nil {
+			resources = append(resources, instanceRes...)
+		}
+	}
+
+	for i := range resources {
+		tags, err := getUserTags(ctx, c, resources[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		resources[i].Tags = tags
+	}
+
+	return resources, nil
+}
+
+func listWorkspaces(ctx context.Context, c *apiClient, resourceGroupID, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	next := fmt.Sprintf("/v2/resource_instances?resource_group_id=%s&resource_id=%s",
+		url.QueryEscape(resourceGroupID), powerVSServiceID)
+	for next != "" {
+		var page struct {
+			Resources []struct {
+				GUID string `json:"guid"`
+				CRN  string `json:"crn"`
+				Name string `json:"name"`
+			} `json:"resources"`
+			NextURL string `json:"next_url"`
+		}
+		if err := c.getJSON(ctx, c.endpoints.resourceController+next, &page); err != nil {
+			return nil, fmt.Errorf("error listing PowerVS workspaces: %w", err)
+		}
+
+		for _, workspace := range page.Resources {
+			if !strings.HasPrefix(workspace.Name, infraID) {
+				continue
+			}
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformPowerVS,
+				Type:          infraType.CloudResourceTypePowerVSWorkspace,
+				ID:            workspace.CRN,
+				Name:          workspace.Name,
+				SelfLink:      fmt.Sprintf("%s/pcloud/v1/cloud-instances/%s", c.endpoints.powerVS, workspace.GUID),
+			})
+		}
+		next = page.NextURL
+	}
+	return resources, nil
+}
+
+// listPVMInstances lists the instances of a workspace whose server name
+// carries the infraID prefix. The PowerVS API needs the workspace CRN in a
+// header on every call.
+func listPVMInstances(ctx context.Context, c *apiClient, workspace infraType.CloudResource,
+	infraID string) ([]infraType.CloudResource, error) {
+
+	var resources []infraType.CloudResource
+
+	data, err := c.do(ctx, http.MethodGet, workspace.SelfLink+"/pvm-instances", nil,
+		http.Header{"Crn": []string{workspace.ID}})
+	if err != nil {
+		return nil, fmt.Errorf("error listing PVM instances of %s: %w", workspace.Name, err)
+	}
+
+	var page struct {
+		PVMInstances []struct {
+			PVMInstanceID string `json:"pvmInstanceID"`
+			ServerName    string `json:"serverName"`
+			CRN           string `json:"crn"`
+		} `json:"pvmInstances"`
+	}
+	if err := json.Unmarshal(data, &page); err != nil {
+		return nil, fmt.Errorf("error decoding PVM instances of %s: %w", workspace.Name, err)
+	}
+
+	for _, instance := range page.PVMInstances {
+		if !strings.HasPrefix(instance.ServerName, infraID+"-") {
+			continue
+		}
+		crn := instance.CRN
+		if crn == "" {
+			// Older API versions omit the CRN; it follows the workspace's.
+			crn = strings.TrimSuffix(workspace.ID, "::") + ":pvm-instance:" + instance.PVMInstanceID
+		}
+		resources = append(resources, infraType.CloudResource{
+			CloudProvider: infraType.CloudPlatformPowerVS,
+			Type:          infraType.CloudResourceTypePowerVSInstance,
+			ID:            crn,
+			Name:          instance.ServerName,
+		})
+	}
+	return resources, nil
+}
+
+// getPowerVSClusterInfo returns the infraID, region and resource group name
+// of a PowerVS cluster.
+func getPowerVSClusterInfo(ctx context.Context, k8sClient client.Client) (string, string, string, error) {
+	infra := &configv1.Infrastructure{}
+	if err := k8sClient.Get(ctx,
+		client.ObjectKey{Name: "cluster"}, infra); err != nil {
+		return "", "", "", fmt.Errorf("failed to get Infrastructure: %w", err)
+	}
+
+	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.PowerVS == nil {
+		return "", "", "", fmt.Errorf("PowerVS platform status not found")
+	}
+
+	status := infra.Status.PlatformStatus.PowerVS
+	return infra.Status.InfrastructureName, status.Region, status.ResourceGroup, nil
+}
@@ -0,0 +1,78 @@
+package ibm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func TestPowerVSUserTags(t *testing.T) {
+	const workspaceCRN = "crn:v1:bluemix:public:power-iaas:dal10:a/account:guid-1::"
+	f := &fakeIBMCloud{
+		workspaces: []map[string]string{
+			{"guid": "guid-1", "crn": workspaceCRN, "name": "mycluster-x7k2p-power-iaas"},
+			{"guid": "guid-2", "crn": "crn:other", "name": "other-cluster"},
+		},
+		pvmInstances: map[string][]map[string]string{
+			"guid-1": {
+				{"pvmInstanceID": "pvm-1", "serverName": "mycluster-x7k2p-master-0"},
+				{"pvmInstanceID": "pvm-2", "serverName": "bastion"},
+			},
+		},
+		tags: map[string][]string{workspaceCRN: {"Owner:Old"}},
+	}
+	startFakeIBMCloud(t, f)
+
+	scheme := runtime.NewScheme()
+	if err := configv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			InfrastructureName: "mycluster-x7k2p",
+			PlatformStatus: &configv1.PlatformStatus{
+				Type:    configv1.PowerVSPlatformType,
+				PowerVS: &configv1.PowerVSPlatformStatus{Region: "dal", ResourceGroup: "mycluster-rg"},
+			},
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).WithStatusSubresource(infra).Build()
+
+	ctx := context.Background()
+	resources, err := ListPowerVSResources(ctx, k8sClient)
+	if err != nil {
+		t.Fatalf("ListPowerVSResources() error = %v", err)
+	}
+	instanceCRN := "crn:v1:bluemix:public:power-iaas:dal10:a/account:guid-1:pvm-instance:pvm-1"
+	var ids []string
+	for _, res := range resources {
+		ids = append(ids, string(res.Type)+" "+res.ID)
+	}
+	want := []string{
+		string(infraType.CloudResourceTypePowerVSWorkspace) + " " + workspaceCRN,
+		string(infraType.CloudResourceTypePowerVSInstance) + " " + instanceCRN,
+	}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("discovered %v, want %v", ids, want)
+	}
+
+	if err := UpdateResourceTags(ctx, resources, map[string]string{"Owner": "DevOps"}); err != nil {
+		t.Fatalf("UpdateResourceTags() error = %v", err)
+	}
+	wantCalls := []string{"detach Owner:Old", "attach Owner:DevOps", "attach Owner:DevOps"}
+	if !reflect.DeepEqual(f.calls, wantCalls) {
+		t.Errorf("calls = %q, want %q", f.calls, wantCalls)
+	}
+	wantTagged := []string{"user " + workspaceCRN, "user " + workspaceCRN, "user " + instanceCRN}
+	if !reflect.DeepEqual(f.tagged, wantTagged) {
+		t.Errorf("tagged = %q, want %q", f.tagged, wantTagged)
+	}
+}
@@ -0,0 +1,131 @@
+package ibm
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// IBM Cloud user tags are plain strings. Tags are mapped to "key:value"
+// strings, and a tag without a colon is read back as a key with an empty
+// value.
+
+type tagResource struct {
+	ResourceID string `json:"resource_id"`
+}
+
+type tagRequest struct {
+	Resources []tagResource `json:"resources"`
+	TagNames  []string      `json:"tag_names"`
+}
+
+type tagResults struct {
+	Results []struct {
+		ResourceID string `json:"resource_id"`
+		IsError    bool   `json:"is_error"`
+	} `json:"results"`
+}
+
+// UpdateResourceTags attaches the given tags to every resource. A key can
+// only carry one value, so a tag with the same key and a different value is
+// detached first. Tags a resource already carries are not attached again,
+// and a resource carrying all of them is not written.
+func UpdateResourceTags(ctx context.Context, resources []infraType.CloudResource, tags map[string]string) error {
+
+	// Tagging is global, the region only matters for discovery.
+	c, err := newClient("")
+	if err != nil {
+		return fmt.Errorf("IBM Cloud authentication error: %w", err)
+	}
+
+	return infraType.ForEachResource(ctx, resources, "update", func(ctx context.Context, resource infraType.CloudResource) error {
+		var attach, detach []string
+		for _, k := range sortedKeys(tags) {
+			current, ok := resource.Tags[k]
+			if ok && current == tags[k] {
+				continue
+			}
+			if ok {
+				detach = append(detach, formatTag(k, current))
+			}
+			attach = append(attach, formatTag(k, tags[k]))
+		}
+		if len(attach) == 0 {
+			return nil
+		}
+
+		if len(detach) > 0 {
+			if err := changeTags(ctx, c, "detach", resource.ID, detach); err != nil {
+				return err
+			}
+		}
+		return changeTags(ctx, c, "attach", resource.ID, attach)
+	})
+}
+
+// changeTags calls /v3/tags/attach or /v3/tags/detach for a single resource.
+func changeTags(ctx context.Context, c *apiClient, action, crn string, tagNames []string) error {
+	var results tagResults
+	u := fmt.Sprintf("%s/v3/tags/%s?tag_type=user", c.endpoints.tagging, action)
+	err := c.postJSON(ctx, u, tagRequest{
+		Resources: []tagResource{{ResourceID: crn}},
+		TagNames:  tagNames,
+	}, &results)
+	if err != nil {
+		return fmt.Errorf("failed to %s tags: %w", action, err)
+	}
+
+	for _, result := range results.Results {
+		if result.IsError {
+			return fmt.Errorf("failed to %s tags on %s", action, result.ResourceID)
+		}
+	}
+	return nil
+}
+
+// getUserTags returns the user tags attached to a resource.
+func getUserTags(ctx context.Context, c *apiClient, crn string) (map[string]string, error) {
+	var page struct {
+		Items []struct {
+			Name string `json:"name"`
+		} `json:"items"`
+	}
+
+	u := fmt.Sprintf("%s/v3/tags?tag_type=user&limit=1000&attached_to=%s",
+		c.endpoints.tagging, url.QueryEscape(crn))
+	if err := c.getJSON(ctx, u, &page); err != nil {
+		return nil, fmt.Errorf("failed to get tags of %s: %w", crn, err)
+	}
+
+	tags := make(map[string]string)
+	for _, item := range page.Items {
+		k, v := parseTag(item.Name)
+		tags[k] = v
+	}
+	return tags, nil
+}
+
+func sortedKeys(tags map[string]string) []string {
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatTag(key, value string) string {
+	if value == "" {
+		return key
+	}
+	return key + ":" + value
+}
+
+func parseTag(tag string) (string, string) {
+	key, value, _ := strings.Cut(tag, ":")
+	return key, value
+}
@@ -0,0 +1,43 @@
+package ibm
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+const (
+	ibmTagMaxLength = 128
+	ibmMaxTags      = 1000
+)
+
+// ibmTagPattern matches the characters IBM Cloud allows in a user tag.
+var ibmTagPattern = regexp.MustCompile(`^[A-Za-z0-9 _.:-]+$`)
+
+// IsValidIBMTag validates IBM Cloud user tags. Each tag is stored as
+// "key:value", so the limits apply to the combined string.
+func IsValidIBMTag(tags map[string]string) error {
+	if len(tags) > ibmMaxTags {
+		return fmt.Errorf("%w: IBM Cloud allows a maximum of %d user tags per resource", infraType.ErrTagLimitExceeded, ibmMaxTags)
+	}
+	for key, value := range tags {
+		if len(key) == 0 {
+			return errors.New("IBM Cloud tag key cannot be empty")
+		}
+		if strings.Contains(key, ":") {
+			return fmt.Errorf("IBM Cloud tag key %q must not contain ':'", key)
+		}
+
+		tag := formatTag(key, value)
+		if len(tag) > ibmTagMaxLength {
+			return fmt.Errorf("IBM Cloud tag %q exceeds %d characters", tag, ibmTagMaxLength)
+		}
+		if !ibmTagPattern.MatchString(tag) {
+			return fmt.Errorf("IBM Cloud tag %q contains invalid characters", tag)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+// Package inventory reads and writes the tags of a cluster's resources as a
+// JSON or YAML file that can be edited and imported again.
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+	"sigs.k8s.io/yaml"
+)
+
+// Version is the format version of the inventories Write writes.
+const Version = "v1"
+
+// Format is the encoding of an inventory file.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+)
+
+// Inventory is the tags of a cluster's resources.
+type Inventory struct {
+	Version   string                  `json:"version"`
+	Time      time.Time               `json:"time"`
+	Platform  infraType.CloudPlatform `json:"platform"`
+	Resources []Resource              `json:"resources"`
+}
+
+// Resource is a resource and its tags. Type and Name are informational when
+// importing; resources are matched by ID.
+type Resource struct {
+	Type     infraType.CloudResourceType `json:"type,omitempty"`
+	ID       string                      `json:"id"`
+	Name     string                      `json:"name,omitempty"`
+	Location string                      `json:"location,omitempty"`
+	// NotTaggable resources are exported for completeness and skipped when
+	// importing.
+	NotTaggable bool              `json:"notTaggable,omitempty"`
+	Tags        map[string]string `json:"tags"`
+}
+
+// New returns the inventory of the platform's resources.
+func New(cloudPlatform infraType.CloudPlatform, resources []infraType.CloudResource) *Inventory {
+	inv := &Inventory{Version: Version, Time: time.Now().UTC(), Platform: cloudPlatform}
+	for _, res := range resources {
+		tags := res.Tags
+		if tags == nil {
+			tags = map[string]string{}
+		}
+		inv.Resources = append(inv.Resources, Resource{
+			Type:        res.Type,
+			ID:          res.ID,
+			Name:        res.Name,
+			Location:    res.Location,
+			NotTaggable: res.NotTaggable,
+			Tags:        tags,
+		})
+	}
+	return inv
+}
+
+// ParseFormat returns the format named by format, or if it is empty the one
+// the extension of path implies. Files that are not .yaml or .yml are JSON.
+func ParseFormat(format, path string) (Format, error) {
+	switch strings.ToLower(format) {
+	case "":
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml":
+			return FormatYAML, nil
+		}
+		return FormatJSON, nil
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	}
+	return "", fmt.Errorf("unsupported format %q, expected json or yaml", format)
+}
+
+// Write writes the inventory to path in the format.
+func (inv *Inventory) Write(path string, format Format) error {
+	data, err := json.MarshalIndent(inv, "", "  ")
+	if err != nil {
+		return err
+	}
+	if format == FormatYAML {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return err
+		}
+	} else {
+		data = append(data, '\n')
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write inventory: %w", err)
+	}
+	return nil
+}
+
+// Read reads the inventory at path. JSON and YAML are both accepted.
+func Read(path string) (*Inventory, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+	var inv Inventory
+	if err := yaml.UnmarshalStrict(data, &inv); err != nil {
+		return nil, fmt.Errorf("failed to parse inventory %s: %w", path, err)
+	}
+	if inv.Version != Version {
+		return nil, fmt.Errorf("inventory %s has version %q, expected %q", path, inv.Version, Version)
+	}
+	for i, r := range inv.Resources {
+		if r.ID == "" {
+			return nil, fmt.Errorf("inventory %s: resource %d has no id", path, i)
+		}
+	}
+	return &inv, nil
+}
@@ -0,0 +1,90 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := []struct {
+		format  string
+		path    string
+		want    Format
+		wantErr bool
+	}{
+		{path: "tags.json", want: FormatJSON},
+		{path: "tags.yaml", want: FormatYAML},
+		{path: "tags.YML", want: FormatYAML},
+		{path: "tags.txt", want: FormatJSON},
+		{format: "yaml", path: "tags.json", want: FormatYAML},
+		{format: "JSON", path: "tags.yaml", want: FormatJSON},
+		{format: "yml", want: FormatYAML},
+		{format: "csv", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format+" "+tt.path, func(t *testing.T) {
+			got, err := ParseFormat(tt.format, tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	inv := New(infraType.CloudPlatformAWS, []infraType.CloudResource{
+		{
+			Type:     infraType.CloudResourceTypeAWSEC2Instance,
+			ID:       "i-1",
+			Name:     "master-0",
+			Location: "us-east-1",
+			Tags:     map[string]string{"Owner": "DevOps"},
+		},
+		{Type: infraType.CloudResourceTypeAWSIAMRole, ID: "role", NotTaggable: true},
+	})
+
+	for _, format := range []Format{FormatJSON, FormatYAML} {
+		t.Run(string(format), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tags."+string(format))
+			if err := inv.Write(path, format); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			got, err := Read(path)
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if !got.Time.Equal(inv.Time) || got.Platform != inv.Platform || !reflect.DeepEqual(got.Resources, inv.Resources) {
+				t.Errorf("Read() = %+v, want %+v", got, inv)
+			}
+		})
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "other version", data: "version: v0\nresources: []\n"},
+		{name: "unknown field", data: "version: v1\nresources:\n- id: i-1\n  tag: {}\n"},
+		{name: "missing id", data: "version: v1\nresources:\n- name: master-0\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "tags.yaml")
+			if err := os.WriteFile(path, []byte(tt.data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := Read(path); err == nil {
+				t.Error("Read() succeeded, want an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+// Package journal records the progress of a sync in an append-only JSON
+// Lines file, one line per resource and state, so that a sync that died
+// halfway can be resumed with the resources it did not apply.
+package journal
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// State is the state of a resource in a sync.
+type State string
+
+const (
+	// StatePlanned is recorded before the resource is written.
+	StatePlanned State = "planned"
+	// StateApplied is recorded once the resource carries the new tags.
+	StateApplied State = "applied"
+	// StateFailed is recorded when the write failed.
+	StateFailed State = "failed"
+)
+
+// Entry is a line of the journal.
+type Entry struct {
+	Time     time.Time                   `json:"time"`
+	State    State                       `json:"state"`
+	Platform infraType.CloudPlatform     `json:"platform"`
+	Type     infraType.CloudResourceType `json:"type"`
+	ID       string                      `json:"id"`
+	Name     string                      `json:"name,omitempty"`
+	// Before are the tags of the resource when the sync was planned, After
+	// the tags it is meant to have.
+	Before map[string]string `json:"before"`
+	After  map[string]string `json:"after"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// Key identifies the entry's resource, see CloudResource.Key.
+func (e Entry) Key() string {
+	return infraType.CloudResource{Type: e.Type, ID: e.ID, Name: e.Name}.Key()
+}
+
+// Journal appends entries to a journal file. It is safe for concurrent use.
+type Journal struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// Open opens the journal at path for appending, creating it if needed. A
+// last line cut short by a crash is terminated, so that the next entry
+// starts on a line of its own.
+func Open(path string) (*Journal, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	if info, err := file.Stat(); err == nil && info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := file.ReadAt(last, info.Size()-1); err == nil && last[0] != '\n' {
+			if _, err := file.Write([]byte{'\n'}); err != nil {
+				file.Close()
+				return nil, fmt.Errorf("failed to write journal %s: %w", path, err)
+			}
+		}
+	}
+	return &Journal{path: path, file: file}, nil
+}
+
+// Path returns the path of the journal file.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Record appends the entry and flushes it to disk, so that it survives the
+// process.
+func (j *Journal) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal %s: %w", j.path, err)
+	}
+	return j.file.Sync()
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// Read returns the entries of the journal at path. Lines cut short by a
+// crash are ignored.
+func Read(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read journal %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Latest returns the last entry of each resource by Entry.Key, and the keys
+// in the order the resources first appear.
+func Latest(entries []Entry) (map[string]Entry, []string) {
+	latest := make(map[string]Entry)
+	var keys []string
+	for _, e := range entries {
+		key := e.Key()
+		if _, ok := latest[key]; !ok {
+			keys = append(keys, key)
+		}
+		latest[key] = e
+	}
+	return latest, keys
+}
@@ -0,0 +1,106 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func entry(id string, state State) Entry {
+	return Entry{
+		State:    state,
+		Platform: infraType.CloudPlatformAWS,
+		Type:     infraType.CloudResourceTypeAWSEC2Instance,
+		ID:       id,
+		Before:   map[string]string{"Owner": "Old"},
+		After:    map[string]string{"Owner": "DevOps"},
+	}
+}
+
+func TestLatest(t *testing.T) {
+	tests := []struct {
+		name       string
+		entries    []Entry
+		wantStates map[string]State
+		wantIDs    []string
+	}{
+		{
+			name:       "empty journal",
+			wantStates: map[string]State{},
+		},
+		{
+			name:       "applied after planned",
+			entries:    []Entry{entry("i-1", StatePlanned), entry("i-1", StateApplied)},
+			wantStates: map[string]State{"i-1": StateApplied},
+			wantIDs:    []string{"i-1"},
+		},
+		{
+			name: "resources keep their first position",
+			entries: []Entry{
+				entry("i-2", StatePlanned), entry("i-1", StatePlanned),
+				entry("i-1", StateFailed), entry("i-2", StateApplied),
+			},
+			wantStates: map[string]State{"i-1": StateFailed, "i-2": StateApplied},
+			wantIDs:    []string{"i-2", "i-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			latest, keys := Latest(tt.entries)
+
+			states := make(map[string]State, len(latest))
+			for _, e := range latest {
+				states[e.ID] = e.State
+			}
+			if !reflect.DeepEqual(states, tt.wantStates) {
+				t.Errorf("states = %v, want %v", states, tt.wantStates)
+			}
+			var ids []string
+			for _, key := range keys {
+				ids = append(ids, latest[key].ID)
+			}
+			if !reflect.DeepEqual(ids, tt.wantIDs) {
+				t.Errorf("order = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestRecordRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sync.jsonl")
+
+	// A crash cut the last line of a previous run short.
+	if err := os.WriteFile(path, []byte(`{"state":"planned","id":"i-0"}`+"\n"+`{"state":"app`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	j, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	for _, e := range []Entry{entry("i-1", StatePlanned), entry("i-1", StateApplied)} {
+		if err := j.Record(e); err != nil {
+			t.Fatalf("Record() error = %v", err)
+		}
+	}
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := Read(path)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.ID+" "+string(e.State))
+	}
+	if want := []string{"i-0 planned", "i-1 planned", "i-1 applied"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Read() = %v, want %v", got, want)
+	}
+	if e := entries[2]; e.Time.IsZero() || e.After["Owner"] != "DevOps" {
+		t.Errorf("Read() = %+v, want the recorded time and tags", e)
+	}
+}
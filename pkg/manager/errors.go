@@ -0,0 +1,277 @@
+package manager
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/aws/smithy-go"
+	"github.com/vmware/govmomi/fault"
+	"github.com/vmware/govmomi/vapi/rest"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// Error categories. The errors returned by the Manager match them with
+// errors.Is when their cause is known, see Category.
+var (
+	ErrUnsupportedPlatform = infraType.ErrUnsupportedPlatform
+	ErrCredentials         = infraType.ErrCredentials
+	ErrPermissionDenied    = infraType.ErrPermissionDenied
+	ErrThrottled           = infraType.ErrThrottled
+	ErrNotFound            = infraType.ErrNotFound
+	ErrTagLimitExceeded    = infraType.ErrTagLimitExceeded
+)
+
+// categories are the error categories in the order Category checks them.
+var categories = []error{
+	ErrUnsupportedPlatform,
+	ErrCredentials,
+	ErrPermissionDenied,
+	ErrTagLimitExceeded,
+	ErrThrottled,
+	ErrNotFound,
+}
+
+// Error is returned by the Manager methods. It records the operation and
+// platform and wraps the cause, which errors.Is and errors.As see through.
+type Error struct {
+	Op       string
+	Platform infraType.CloudPlatform
+	Err      error
+}
+
+func (e *Error) Error() string {
+	if e.Platform == "" {
+		return fmt.Sprintf("%s: %v", e.Op, e.Err)
+	}
+	return fmt.Sprintf("%s %s resources: %v", e.Op, e.Platform, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func unsupported(cloudPlatform infraType.CloudPlatform) error {
+	return fmt.Errorf("%w: %s", ErrUnsupportedPlatform, cloudPlatform)
+}
+
+// Category returns the category of err, one of the Err* variables, or nil
+// if it has none. When err combines several errors, the first category in
+// the order unsupported platform, credentials, permissions, tag limit,
+// throttling, not found wins.
+func Category(err error) error {
+	for _, category := range categories {
+		if errors.Is(err, category) {
+			return category
+		}
+	}
+	return nil
+}
+
+// Classify returns err with the category of the AWS, Azure, GCP, vSphere or
+// REST API error it wraps, so that errors.Is(err, ErrThrottled) and the like
+// match. Errors of no known category are returned unchanged. The provider
+// packages return unclassified errors; the Manager classifies everything it
+// returns.
+func Classify(err error) error {
+	if err == nil || Category(err) != nil {
+		return err
+	}
+	if cloudErr := classify(err); cloudErr != nil {
+		return cloudErr
+	}
+	return err
+}
+
+func classify(err error) *infraType.CloudError {
+	var awsErr smithy.APIError
+	if errors.As(err, &awsErr) {
+		cloudErr := &infraType.CloudError{
+			Category: awsCategory(awsErr.ErrorCode()),
+			Code:     awsErr.ErrorCode(),
+			Err:      err,
+		}
+		var httpErr interface{ HTTPStatusCode() int }
+		if errors.As(err, &httpErr) {
+			cloudErr.StatusCode = httpErr.HTTPStatusCode()
+			if cloudErr.Category == nil {
+				cloudErr.Category = statusCategory(cloudErr.StatusCode)
+			}
+		}
+		return valid(cloudErr)
+	}
+
+	var azureErr *azcore.ResponseError
+	if errors.As(err, &azureErr) {
+		category := azureCategory(azureErr.ErrorCode)
+		if category == nil {
+			category = statusCategory(azureErr.StatusCode)
+		}
+		return valid(&infraType.CloudError{
+			Category:   category,
+			Code:       azureErr.ErrorCode,
+			StatusCode: azureErr.StatusCode,
+			Err:        err,
+		})
+	}
+	var azureAuthErr *azidentity.AuthenticationFailedError
+	if errors.As(err, &azureAuthErr) {
+		return &infraType.CloudError{Category: ErrCredentials, Err: err}
+	}
+
+	var googleErr *googleapi.Error
+	if errors.As(err, &googleErr) {
+		cloudErr := &infraType.CloudError{
+			Category:   statusCategory(googleErr.Code),
+			StatusCode: googleErr.Code,
+			Err:        err,
+		}
+		for _, item := range googleErr.Errors {
+			cloudErr.Code = item.Reason
+			// GCP reports rate limits as 403 with a reason.
+			if item.Reason == "rateLimitExceeded" || item.Reason == "userRateLimitExceeded" {
+				cloudErr.Category = ErrThrottled
+			}
+		}
+		return valid(cloudErr)
+	}
+	if errors.Is(err, storage.ErrBucketNotExist) || errors.Is(err, storage.ErrObjectNotExist) {
+		return &infraType.CloudError{Category: ErrNotFound, StatusCode: http.StatusNotFound, Err: err}
+	}
+	var tokenErr *oauth2.RetrieveError
+	if errors.As(err, &tokenErr) {
+		return &infraType.CloudError{Category: ErrCredentials, Code: tokenErr.ErrorCode, Err: err}
+	}
+
+	var statusErr *infraType.StatusError
+	if errors.As(err, &statusErr) {
+		return valid(&infraType.CloudError{
+			Category:   statusCategory(statusErr.StatusCode),
+			StatusCode: statusErr.StatusCode,
+			Err:        err,
+		})
+	}
+
+	if code := vapiStatus(err); code != 0 {
+		return valid(&infraType.CloudError{
+			Category:   statusCategory(code),
+			StatusCode: code,
+			Err:        err,
+		})
+	}
+	if category := vimFaultCategory(err); category != nil {
+		return &infraType.CloudError{Category: category, Err: err}
+	}
+	return nil
+}
+
+// vapiStatus returns the status of the vSphere Automation API error err
+// wraps, 0 if none. The error type is unexported and rest.IsStatusError does
+// not unwrap, so the error tree is walked here, for the statuses
+// statusCategory knows.
+func vapiStatus(err error) int {
+	for _, code := range []int{http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound, http.StatusTooManyRequests} {
+		if rest.IsStatusError(err, code) {
+			return code
+		}
+	}
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		if inner := e.Unwrap(); inner != nil {
+			return vapiStatus(inner)
+		}
+	case interface{ Unwrap() []error }:
+		for _, inner := range e.Unwrap() {
+			if code := vapiStatus(inner); code != 0 {
+				return code
+			}
+		}
+	}
+	return 0
+}
+
+// vimFaultCategory maps the faults of the vSphere Web Services API.
+func vimFaultCategory(err error) error {
+	switch {
+	case fault.Is(err, &vimtypes.NotAuthenticated{}), fault.Is(err, &vimtypes.InvalidLogin{}):
+		return ErrCredentials
+	case fault.Is(err, &vimtypes.NoPermission{}):
+		return ErrPermissionDenied
+	case fault.Is(err, &vimtypes.ManagedObjectNotFound{}):
+		return ErrNotFound
+	default:
+		return nil
+	}
+}
+
+// valid returns nil for errors of no known category.
+func valid(cloudErr *infraType.CloudError) *infraType.CloudError {
+	if cloudErr.Category == nil {
+		return nil
+	}
+	return cloudErr
+}
+
+func statusCategory(statusCode int) error {
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return ErrCredentials
+	case http.StatusForbidden:
+		return ErrPermissionDenied
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusTooManyRequests:
+		return ErrThrottled
+	default:
+		return nil
+	}
+}
+
+// awsCategory maps the error codes of the EC2, ELB, IAM, Route 53, S3 and
+// STS APIs.
+func awsCategory(code string) error {
+	switch code {
+	case "AuthFailure", "InvalidClientTokenId", "UnrecognizedClientException", "SignatureDoesNotMatch",
+		"InvalidAccessKeyId", "ExpiredToken", "ExpiredTokenException":
+		return ErrCredentials
+	case "AccessDenied", "AccessDeniedException", "UnauthorizedOperation":
+		return ErrPermissionDenied
+	case "Throttling", "ThrottlingException", "RequestLimitExceeded", "TooManyRequestsException",
+		"SlowDown", "PriorRequestNotComplete":
+		return ErrThrottled
+	case "TagLimitExceeded", "TagLimitExceededException", "TooManyTags", "TooManyTagsException":
+		return ErrTagLimitExceeded
+	}
+	if strings.HasSuffix(code, "NotFound") || strings.HasSuffix(code, "NotFoundException") ||
+		strings.HasPrefix(code, "NoSuch") {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// azureCategory maps the Azure Resource Manager error codes that are more
+// specific than the HTTP status.
+func azureCategory(code string) error {
+	switch code {
+	case "InvalidAuthenticationToken", "ExpiredAuthenticationToken", "AuthenticationFailed":
+		return ErrCredentials
+	case "AuthorizationFailed", "LinkedAuthorizationFailed":
+		return ErrPermissionDenied
+	case "SubscriptionRequestsThrottled", "TooManyRequests":
+		return ErrThrottled
+	case "ResourceNotFound", "ResourceGroupNotFound", "NotFound":
+		return ErrNotFound
+	case "InvalidTagCount", "TagsLimitExceeded":
+		return ErrTagLimitExceeded
+	default:
+		return nil
+	}
+}
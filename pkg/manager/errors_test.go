@@ -0,0 +1,152 @@
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/aws/smithy-go"
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/soap"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/googleapi"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// azureError returns an Azure response error as the SDK returns it, with
+// the response its message is built from.
+func azureError(code string, statusCode int) error {
+	req, _ := http.NewRequest(http.MethodPatch, "https://management.azure.com/subscriptions/s", nil)
+	return &azcore.ResponseError{
+		ErrorCode:   code,
+		StatusCode:  statusCode,
+		RawResponse: &http.Response{StatusCode: statusCode, Request: req, Body: http.NoBody},
+	}
+}
+
+// vapiError returns the error the vSphere Automation API client returns for
+// a response with the status.
+func vapiError(t *testing.T, statusCode int) error {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(statusCode)
+	}))
+	defer server.Close()
+
+	u, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := rest.NewClient(&vim25.Client{Client: soap.NewClient(u, true)})
+	req, err := http.NewRequest(http.MethodGet, server.URL+rest.Path+"/com/vmware/cis/tagging/tag", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Do(context.Background(), req, nil)
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "AWS credentials", err: &smithy.GenericAPIError{Code: "AuthFailure"}, want: ErrCredentials},
+		{name: "AWS permissions", err: &smithy.GenericAPIError{Code: "UnauthorizedOperation"}, want: ErrPermissionDenied},
+		{name: "AWS throttling", err: &smithy.GenericAPIError{Code: "RequestLimitExceeded"}, want: ErrThrottled},
+		{name: "AWS tag limit", err: &smithy.GenericAPIError{Code: "TagLimitExceeded"}, want: ErrTagLimitExceeded},
+		{name: "AWS not found", err: &smithy.GenericAPIError{Code: "InvalidInstanceID.NotFound"}, want: ErrNotFound},
+		{name: "S3 not found", err: &smithy.GenericAPIError{Code: "NoSuchBucket"}, want: ErrNotFound},
+		{name: "AWS unknown code", err: &smithy.GenericAPIError{Code: "InvalidParameterValue"}},
+		{
+			name: "Azure code",
+			err:  azureError("AuthorizationFailed", 403),
+			want: ErrPermissionDenied,
+		},
+		{
+			name: "Azure status",
+			err:  azureError("Conflict", 429),
+			want: ErrThrottled,
+		},
+		{
+			name: "GCP rate limit reported as 403",
+			err:  &googleapi.Error{Code: 403, Errors: []googleapi.ErrorItem{{Reason: "rateLimitExceeded"}}},
+			want: ErrThrottled,
+		},
+		{name: "GCP permissions", err: &googleapi.Error{Code: 403}, want: ErrPermissionDenied},
+		{name: "GCS bucket", err: fmt.Errorf("reading bucket: %w", storage.ErrBucketNotExist), want: ErrNotFound},
+		{name: "OAuth2 token", err: &oauth2.RetrieveError{ErrorCode: "invalid_grant"}, want: ErrCredentials},
+		{
+			name: "REST status",
+			err:  fmt.Errorf("failed to update: %w", &infraType.StatusError{StatusCode: 401}),
+			want: ErrCredentials,
+		},
+		{name: "REST server error", err: &infraType.StatusError{StatusCode: 500}},
+		{
+			name: "vSphere Automation API status",
+			err:  errors.Join(errors.New("boom"), fmt.Errorf("failed to attach: %w", vapiError(t, 403))),
+			want: ErrPermissionDenied,
+		},
+		{name: "vSphere Automation API server error", err: vapiError(t, 500)},
+		{
+			name: "vSphere login fault",
+			err:  fmt.Errorf("vCenter login failed: %w", soap.WrapVimFault(&vimtypes.InvalidLogin{})),
+			want: ErrCredentials,
+		},
+		{name: "vSphere permission fault", err: soap.WrapVimFault(&vimtypes.NoPermission{}), want: ErrPermissionDenied},
+		{name: "vSphere missing object", err: soap.WrapVimFault(&vimtypes.ManagedObjectNotFound{}), want: ErrNotFound},
+		{name: "already classified", err: fmt.Errorf("%w: aws", ErrUnsupportedPlatform), want: ErrUnsupportedPlatform},
+		{name: "unknown", err: errors.New("boom")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Classify(tt.err)
+			if c := Category(got); c != tt.want {
+				t.Errorf("Category(Classify()) = %v, want %v", c, tt.want)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("Classify() = %v, does not wrap %v", got, tt.err)
+			}
+			if got.Error() != tt.err.Error() {
+				t.Errorf("Classify() message = %q, want %q", got.Error(), tt.err.Error())
+			}
+		})
+	}
+
+	if err := Classify(nil); err != nil {
+		t.Errorf("Classify(nil) = %v, want nil", err)
+	}
+}
+
+func TestCategoryOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "credentials before not found", err: errors.Join(ErrNotFound, ErrCredentials), want: ErrCredentials},
+		{name: "tag limit before throttling", err: errors.Join(ErrThrottled, ErrTagLimitExceeded), want: ErrTagLimitExceeded},
+		{
+			name: "manager error",
+			err:  &Error{Op: "tag", Platform: infraType.CloudPlatformAWS, Err: Classify(&smithy.GenericAPIError{Code: "Throttling"})},
+			want: ErrThrottled,
+		},
+		{name: "canceled", err: context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Category(tt.err); got != tt.want {
+				t.Errorf("Category() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,338 @@
+// Package manager is the Go API for discovering and tagging the cloud
+// resources of OpenShift clusters. A Manager holds everything it needs about
+// one cluster, so managers for several clusters can be used concurrently in
+// one process.
+package manager
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	"golang.org/x/oauth2/google"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/aws"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/azure"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/cluster"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/gcp"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/ibm"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/nutanix"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/openstack"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/vsphere"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// Options configures a Manager.
+type Options struct {
+	// Client reads the cluster identity from the Infrastructure object. When
+	// nil, a client is created from Offline, or from Kubeconfig and
+	// KubeContext.
+	Client      client.Client
+	Kubeconfig  string
+	KubeContext string
+	// Offline identifies a cluster without using its API server.
+	Offline *cluster.OfflineCluster
+
+	// Platform overrides the platform recorded in the Infrastructure status.
+	Platform infraType.CloudPlatform
+	// GCPNetworkProject overrides the shared-VPC host project read from the
+	// install-config.
+	GCPNetworkProject string
+
+	// Credentials replace the default credential chains of the cloud SDKs.
+	Credentials Credentials
+	// Logger receives progress messages and non-fatal discovery errors. They
+	// are discarded when it is unset.
+	Logger logr.Logger
+}
+
+// Credentials for the cloud APIs. Unset fields fall back to the SDK
+// defaults: the AWS shared config and environment, DefaultAzureCredential
+// with AZURE_SUBSCRIPTION_ID, and Application Default Credentials on GCP.
+// The other platforms read their credentials from the environment.
+type Credentials struct {
+	AWS                 *awssdk.Config
+	Azure               azcore.TokenCredential
+	AzureSubscriptionID string
+	GCP                 *google.Credentials
+}
+
+// Manager discovers and tags the resources of one cluster.
+type Manager struct {
+	client   client.Client
+	platform infraType.CloudPlatform
+	opts     Options
+}
+
+// New returns a Manager for the cluster described by opts. Unless
+// opts.Platform is set, the platform is read from the Infrastructure status.
+func New(ctx context.Context, opts Options) (*Manager, error) {
+	m := &Manager{client: opts.Client, platform: opts.Platform, opts: opts}
+
+	if m.client == nil {
+		var err error
+		if opts.Offline != nil {
+			m.client, err = cluster.NewOfflineClient(opts.Offline)
+		} else {
+			m.client, err = cluster.NewClient(opts.Kubeconfig, opts.KubeContext)
+		}
+		if err != nil {
+			return nil, &Error{Op: "connect", Platform: opts.Platform, Err: err}
+		}
+	}
+
+	if m.platform == "" {
+		infra := &configv1.Infrastructure{}
+		if err := m.client.Get(ctx, client.ObjectKey{Name: "cluster"}, infra); err != nil {
+			return nil, &Error{Op: "connect", Err: fmt.Errorf("failed to get Infrastructure resource: %w", err)}
+		}
+		m.platform = cluster.Platform(infra)
+	}
+	return m, nil
+}
+
+// Platform returns the cluster's cloud platform.
+func (m *Manager) Platform() infraType.CloudPlatform {
+	return m.platform
+}
+
+// Client returns the client the Manager reads the cluster with.
+func (m *Manager) Client() client.Client {
+	return m.client
+}
+
+// ListResources discovers the cluster's resources and their current tags.
+func (m *Manager) ListResources(ctx context.Context) ([]infraType.CloudResource, error) {
+	ctx = m.context(ctx)
+
+	var resources []infraType.CloudResource
+	var err error
+	switch m.platform {
+	case infraType.CloudPlatformAWS:
+		resources, err = aws.ListAWSResources(ctx, m.client)
+	case infraType.CloudPlatformAzure:
+		resources, err = azure.ListAzureResources(ctx, m.client)
+	case infraType.CloudPlatformGCP:
+		resources, err = gcp.ListGCPResources(ctx, m.client, m.opts.GCPNetworkProject)
+	case infraType.CloudPlatformIBM:
+		resources, err = ibm.ListIBMResources(ctx, m.client)
+	case infraType.CloudPlatformPowerVS:
+		resources, err = ibm.ListPowerVSResources(ctx, m.client)
+	case infraType.CloudPlatformOpenStack:
+		resources, err = openstack.ListOpenStackResources(ctx, m.client)
+	case infraType.CloudPlatformVSphere:
+		resources, err = vsphere.ListVSphereResources(ctx, m.client)
+	case infraType.CloudPlatformNutanix:
+		resources, err = nutanix.ListNutanixResources(ctx, m.client)
+	default:
+		err = unsupported(m.platform)
+	}
+	if err != nil {
+		return nil, m.error("list", err)
+	}
+	return resources, nil
+}
+
+// ApplyTags adds the tags to the resources, overwriting the values of
+// existing keys and keeping other tags.
+func (m *Manager) ApplyTags(ctx context.Context, resources []infraType.CloudResource, tags map[string]string) error {
+	if err := ValidateTags(m.platform, tags, false); err != nil {
+		return m.error("validate", err)
+	}
+	ctx = m.context(ctx)
+
+	var err error
+	switch m.platform {
+	case infraType.CloudPlatformAWS:
+		err = aws.UpdateResourceTags(ctx, resources, tags)
+	case infraType.CloudPlatformAzure:
+		err = azure.UpdateResourceTags(ctx, resources, tags)
+	case infraType.CloudPlatformGCP:
+		// GCP replaces the whole label set, so the labels are merged per
+		// resource.
+		err = infraType.ForEachResource(ctx, resources, "update", func(ctx context.Context, res infraType.CloudResource) error {
+			return gcp.UpdateResourceTags(ctx, res, mergeTags(res.Tags, tags))
+		})
+	case infraType.CloudPlatformIBM, infraType.CloudPlatformPowerVS:
+		err = ibm.UpdateResourceTags(ctx, resources, tags)
+	case infraType.CloudPlatformOpenStack:
+		err = openstack.UpdateResourceTags(ctx, m.client, resources, tags)
+	case infraType.CloudPlatformVSphere:
+		err = vsphere.UpdateResourceTags(ctx, m.client, resources, tags)
+	case infraType.CloudPlatformNutanix:
+		err = nutanix.UpdateResourceTags(ctx, m.client, resources, tags)
+	default:
+		err = unsupported(m.platform)
+	}
+	if err != nil {
+		return m.error("apply", err)
+	}
+	return nil
+}
+
+// RemoveTags deletes the tag keys from the resources. It is supported on
+// AWS, Azure and GCP.
+func (m *Manager) RemoveTags(ctx context.Context, resources []infraType.CloudResource, keys []string) error {
+	ctx = m.context(ctx)
+
+	var err error
+	switch m.platform {
+	case infraType.CloudPlatformAWS:
+		err = aws.RemoveResourceTags(ctx, resources, keys)
+	case infraType.CloudPlatformAzure:
+		err = azure.RemoveResourceTags(ctx, resources, keys)
+	case infraType.CloudPlatformGCP:
+		err = infraType.ForEachResource(ctx, resources, "remove labels from", func(ctx context.Context, res infraType.CloudResource) error {
+			return gcp.RemoveResourceTags(ctx, res, keys)
+		})
+	default:
+		err = unsupported(m.platform)
+	}
+	if err != nil {
+		return m.error("remove", err)
+	}
+	return nil
+}
+
+// DescribeResource returns a single resource with its current tags. It is
+// supported for instances, disks and load balancers on AWS, Azure and GCP.
+func (m *Manager) DescribeResource(ctx context.Context, resource infraType.CloudResource) (infraType.CloudResource, error) {
+	ctx = m.context(ctx)
+
+	var err error
+	switch m.platform {
+	case infraType.CloudPlatformAWS:
+		resource, err = aws.DescribeResource(ctx, resource)
+	case infraType.CloudPlatformAzure:
+		resource, err = azure.DescribeResource(ctx, resource)
+	case infraType.CloudPlatformGCP:
+		resource, err = gcp.DescribeResource(ctx, resource)
+	default:
+		err = unsupported(m.platform)
+	}
+	if err != nil {
+		return resource, m.error("describe", err)
+	}
+	return resource, nil
+}
+
+// FindLoadBalancer returns the load balancer behind a Service ingress: the
+// ALB/NLB with the hostname on AWS, the public IP on Azure and the
+// forwarding rule on GCP. It returns nil when there is none.
+func (m *Manager) FindLoadBalancer(ctx context.Context, hostname, ip string) (*infraType.CloudResource, error) {
+	ctx = m.context(ctx)
+
+	var res *infraType.CloudResource
+	var err error
+	switch m.platform {
+	case infraType.CloudPlatformAWS:
+		if hostname != "" {
+			res, err = aws.FindLoadBalancer(ctx, hostname)
+		}
+	case infraType.CloudPlatformAzure:
+		if ip != "" {
+			res, err = azure.FindLoadBalancer(ctx, m.client, ip)
+		}
+	case infraType.CloudPlatformGCP:
+		if ip != "" {
+			res, err = gcp.FindLoadBalancer(ctx, m.client, ip)
+		}
+	default:
+		err = unsupported(m.platform)
+	}
+	if err != nil {
+		return nil, m.error("find load balancer", err)
+	}
+	return res, nil
+}
+
+// SyncResult summarizes a Sync.
+type SyncResult struct {
+	Resources int
+	InSync    int
+	Updated   int
+}
+
+// Sync adds the tags to every taggable cluster resource that is missing one
+// of them or has a different value.
+func (m *Manager) Sync(ctx context.Context, tags map[string]string) (SyncResult, error) {
+	var result SyncResult
+
+	resources, err := m.ListResources(ctx)
+	if err != nil {
+		return result, err
+	}
+	result.Resources = len(resources)
+
+	var outdated []infraType.CloudResource
+	for _, res := range resources {
+		if res.NotTaggable {
+			continue
+		}
+		if hasTags(res.Tags, tags) {
+			result.InSync++
+			continue
+		}
+		outdated = append(outdated, res)
+	}
+	if len(outdated) == 0 {
+		return result, nil
+	}
+
+	if err := m.ApplyTags(ctx, outdated, tags); err != nil {
+		return result, err
+	}
+	result.Updated = len(outdated)
+	return result, nil
+}
+
+// context returns ctx carrying the logger and credentials the provider
+// packages read.
+func (m *Manager) context(ctx context.Context) context.Context {
+	if m.opts.Logger.GetSink() != nil {
+		ctx = logr.NewContext(ctx, m.opts.Logger)
+	}
+	// The region of an offline AWS cluster is not in the SDK configuration.
+	if o := m.opts.Offline; o != nil && o.Platform == infraType.CloudPlatformAWS && o.Region != "" {
+		ctx = aws.WithRegion(ctx, o.Region)
+	}
+	creds := m.opts.Credentials
+	if creds.AWS != nil {
+		ctx = aws.WithConfig(ctx, *creds.AWS)
+	}
+	if creds.Azure != nil {
+		ctx = azure.WithCredential(ctx, creds.Azure, creds.AzureSubscriptionID)
+	}
+	if creds.GCP != nil {
+		ctx = gcp.WithCredentials(ctx, creds.GCP)
+	}
+	return ctx
+}
+
+func (m *Manager) error(op string, err error) error {
+	return &Error{Op: op, Platform: m.platform, Err: Classify(err)}
+}
+
+func mergeTags(existing, updates map[string]string) map[string]string {
+	merged := make(map[string]string, len(existing)+len(updates))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range updates {
+		merged[k] = v
+	}
+	return merged
+}
+
+func hasTags(current, desired map[string]string) bool {
+	for k, v := range desired {
+		if cur, ok := current[k]; !ok || cur != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,39 @@
+package manager
+
+import (
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/aws"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/azure"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/gcp"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/ibm"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/nutanix"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/openstack"
+	"github.com/anirudhAgniRedhat/openshift-metadata-manager/pkg/vsphere"
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// ValidateTags checks the tags against the platform's key and value
+// restrictions. With tagBindings set, GCP tags are checked as Resource
+// Manager tags instead of labels.
+func ValidateTags(cloudPlatform infraType.CloudPlatform, tags map[string]string, tagBindings bool) error {
+	switch cloudPlatform {
+	case infraType.CloudPlatformAWS:
+		return aws.IsValidAWSTag(tags)
+	case infraType.CloudPlatformAzure:
+		return azure.IsValidAzureTag(tags)
+	case infraType.CloudPlatformGCP:
+		if tagBindings {
+			return gcp.IsValidGCPResourceTag(tags)
+		}
+		return gcp.IsValidGCPTag(tags)
+	case infraType.CloudPlatformIBM, infraType.CloudPlatformPowerVS:
+		return ibm.IsValidIBMTag(tags)
+	case infraType.CloudPlatformOpenStack:
+		return openstack.IsValidOpenStackTag(tags)
+	case infraType.CloudPlatformVSphere:
+		return vsphere.IsValidVSphereTag(tags)
+	case infraType.CloudPlatformNutanix:
+		return nutanix.IsValidNutanixTag(tags)
+	default:
+		return unsupported(cloudPlatform)
+	}
+}
@@ -0,0 +1,115 @@
+package nutanix
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// apiClient talks to the Prism Central v3 REST API with basic auth.
+// Connection settings use the same NUTANIX_* variables as the Nutanix
+// terraform provider; NUTANIX_ENDPOINT may be a full URL to point the
+// provider at a local stand-in.
+type apiClient struct {
+	httpClient *http.Client
+	baseURL    string
+	username   string
+	password   string
+}
+
+func newClient(defaultAddress string, defaultPort int32) (*apiClient, error) {
+	username := os.Getenv("NUTANIX_USERNAME")
+	password := os.Getenv("NUTANIX_PASSWORD")
+	if username == "" || password == "" {
+		return nil, fmt.Errorf("NUTANIX_USERNAME and NUTANIX_PASSWORD environment variables must be set")
+	}
+
+	endpoint := os.Getenv("NUTANIX_ENDPOINT")
+	if endpoint == "" {
+		endpoint = defaultAddress
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("NUTANIX_ENDPOINT environment variable not set")
+	}
+	if !strings.Contains(endpoint, "://") {
+		port := os.Getenv("NUTANIX_PORT")
+		if port == "" && defaultPort != 0 {
+			port = strconv.Itoa(int(defaultPort))
+		}
+		if port == "" {
+			port = "9440"
+		}
+		endpoint = fmt.Sprintf("https://%s:%s", endpoint, port)
+	}
+
+	insecure, _ := strconv.ParseBool(os.Getenv("NUTANIX_INSECURE"))
+	return &apiClient{
+		httpClient: &http.Client{
+			Timeout: 60 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: insecure},
+			},
+		},
+		baseURL:  strings.TrimSuffix(endpoint, "/") + "/api/nutanix/v3",
+		username: username,
+		password: password,
+	}, nil
+}
+
+// do sends a request to path (relative to /api/nutanix/v3) and decodes the
+// response into out, if given.
+func (c *apiClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.username, c.password)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &infraType.StatusError{
+			Method:     method,
+			Path:       req.URL.Path,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(data)),
+		}
+	}
+
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
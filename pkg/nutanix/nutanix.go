@@ -0,0 +1,217 @@
+package nutanix
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// ClusterCategoryKey is the category the installer attaches to cluster
+// VMs, with the value "owned".
+const ClusterCategoryKey = "kubernetes-io-cluster-%s"
+
+// entityKinds maps Prism v3 entity kinds to resource types. The kind's
+// plural form is also its URL path.
+var entityKinds = []struct {
+	kind      string
+	path      string
+	cloudType infraType.CloudResourceType
+}{
+	{"vm", "vms", infraType.CloudResourceTypeNutanixVM},
+	{"volume_group", "volume_groups", infraType.CloudResourceTypeNutanixVolumeGroup},
+}
+
+// ListNutanixResources lists the VMs and volume groups that carry the
+// cluster category or the infraID name prefix. Tags are the entity's
+// Prism categories.
+func ListNutanixResources(ctx context.Context, k8sClient client.Client) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	infraID, address, port, err := getClusterInfo(ctx, k8sClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster info: %w", err)
+	}
+
+	c, err := newClient(address, port)
+	if err != nil {
+		return nil, fmt.Errorf("Nutanix authentication error: %w", err)
+	}
+
+	for _, kind := range entityKinds {
+		if entityRes, err := listEntities(ctx, c, kind.kind, kind.path, kind.cloudType, infraID); err == nil {
+			resources = append(resources, entityRes...)
+		}
+	}
+
+	return resources, nil
+}
+
+type entity struct {
+	Metadata struct {
+		UUID       string            `json:"uuid"`
+		Categories map[string]string `json:"categories"`
+	} `json:"metadata"`
+	Spec struct {
+		Name string `json:"name"`
+	} `json:"spec"`
+}
+
+func listEntities(ctx context.Context, c *apiClient, kind, path string, cloudType infraType.CloudResourceType,
+	infraID string) ([]infraType.CloudResource, error) {
+
+	var resources []infraType.CloudResource
+	clusterKey := fmt.Sprintf(ClusterCategoryKey, infraID)
+
+	const pageSize = 250
+	for offset := 0; ; offset += pageSize {
+		var page struct {
+			Metadata struct {
+				TotalMatches int `json:"total_matches"`
+			} `json:"metadata"`
+			Entities []entity `json:"entities"`
+		}
+		body := map[string]interface{}{"kind": kind, "offset": offset, "length": pageSize}
+		if err := c.do(ctx, http.MethodPost, "/"+path+"/list", body, &page); err != nil {
+			return nil, fmt.Errorf("error listing %s: %w", path, err)
+		}
+
+		for _, e := range page.Entities {
+			_, owned := e.Metadata.Categories[clusterKey]
+			if !owned && !strings.HasPrefix(e.Spec.Name, infraID+"-") {
+				continue
+			}
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformNutanix,
+				Type:          cloudType,
+				ID:            e.Metadata.UUID,
+				Name:          e.Spec.Name,
+				Tags:          e.Metadata.Categories,
+			})
+		}
+
+		if len(page.Entities) == 0 || offset+len(page.Entities) >= page.Metadata.TotalMatches {
+			break
+		}
+	}
+	return resources, nil
+}
+
+// UpdateResourceTags assigns a Prism category per key/value to every
+// resource, creating categories and values that do not exist yet.
+func UpdateResourceTags(ctx context.Context, k8sClient client.Client, resources []infraType.CloudResource, tags map[string]string) error {
+
+	_, address, port, err := getClusterInfo(ctx, k8sClient)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster info: %w", err)
+	}
+
+	c, err := newClient(address, port)
+	if err != nil {
+		return fmt.Errorf("Nutanix authentication error: %w", err)
+	}
+
+	for k, v := range tags {
+		if err := ensureCategory(ctx, c, k, v); err != nil {
+			return err
+		}
+	}
+
+	paths := make(map[infraType.CloudResourceType]string)
+	for _, kind := range entityKinds {
+		paths[kind.cloudType] = kind.path
+	}
+
+	return infraType.ForEachResource(ctx, resources, "update", func(ctx context.Context, resource infraType.CloudResource) error {
+		path, ok := paths[resource.Type]
+		if !ok {
+			return fmt.Errorf("unsupported resource type: %s", resource.Type)
+		}
+		return updateEntityCategories(ctx, c, path, resource.ID, tags)
+	})
+}
+
+// ensureCategory creates the category key and value if they are missing.
+func ensureCategory(ctx context.Context, c *apiClient, key, value string) error {
+	keyPath := "/categories/" + url.PathEscape(key)
+	if err := c.do(ctx, http.MethodGet, keyPath, nil, nil); err != nil {
+		if err := c.do(ctx, http.MethodPut, keyPath, map[string]string{"name": key}, nil); err != nil {
+			return fmt.Errorf("failed to create category %s: %w", key, err)
+		}
+	}
+
+	valuePath := keyPath + "/" + url.PathEscape(value)
+	if err := c.do(ctx, http.MethodGet, valuePath, nil, nil); err != nil {
+		if err := c.do(ctx, http.MethodPut, valuePath, map[string]string{"value": value}, nil); err != nil {
+			return fmt.Errorf("failed to create category value %s:%s: %w", key, value, err)
+		}
+	}
+	return nil
+}
+
+// updateEntityCategories re-reads the entity and PUTs back its spec and
+// metadata with the categories merged in. The spec_version in metadata
+// makes the update fail rather than overwrite a concurrent change.
+func updateEntityCategories(ctx context.Context, c *apiClient, path, uuid string, tags map[string]string) error {
+	var current map[string]json.RawMessage
+	if err := c.do(ctx, http.MethodGet, "/"+path+"/"+uuid, nil, &current); err != nil {
+		return fmt.Errorf("failed to get entity: %w", err)
+	}
+
+	var metadata map[string]interface{}
+	if err := json.Unmarshal(current["metadata"], &metadata); err != nil {
+		return fmt.Errorf("failed to decode metadata: %w", err)
+	}
+
+	if useMapping, _ := metadata["use_categories_mapping"].(bool); useMapping {
+		mapping, _ := metadata["categories_mapping"].(map[string]interface{})
+		if mapping == nil {
+			mapping = make(map[string]interface{})
+		}
+		for k, v := range tags {
+			mapping[k] = []string{v}
+		}
+		metadata["categories_mapping"] = mapping
+	} else {
+		categories, _ := metadata["categories"].(map[string]interface{})
+		if categories == nil {
+			categories = make(map[string]interface{})
+		}
+		for k, v := range tags {
+			categories[k] = v
+		}
+		metadata["categories"] = categories
+	}
+
+	body := map[string]interface{}{
+		"api_version": current["api_version"],
+		"metadata":    metadata,
+		"spec":        current["spec"],
+	}
+	return c.do(ctx, http.MethodPut, "/"+path+"/"+uuid, body, nil)
+}
+
+// getClusterInfo returns the infraID and the Prism Central endpoint
+// configured on the cluster.
+func getClusterInfo(ctx context.Context, k8sClient client.Client) (string, string, int32, error) {
+	infra := &configv1.Infrastructure{}
+	if err := k8sClient.Get(ctx,
+		client.ObjectKey{Name: "cluster"}, infra); err != nil {
+		return "", "", 0, fmt.Errorf("failed to get Infrastructure: %w", err)
+	}
+
+	var address string
+	var port int32
+	if spec := infra.Spec.PlatformSpec.Nutanix; spec != nil {
+		address = spec.PrismCentral.Address
+		port = spec.PrismCentral.Port
+	}
+	return infra.Status.InfrastructureName, address, port, nil
+}
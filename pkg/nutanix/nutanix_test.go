@@ -0,0 +1,236 @@
+package nutanix
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+const testInfraID = "mycluster-x7k2p"
+
+// fakePrism is an HTTP stand-in for the Prism Central v3 API.
+type fakePrism struct {
+	mu sync.Mutex
+	// vms are the entities of the vms collection, as returned by a GET.
+	vms map[string]map[string]interface{}
+	// categories are the existing category keys and their values.
+	categories map[string]map[string]bool
+	// created are the categories and values created, as "key" and
+	// "key:value".
+	created []string
+	// updates are the metadata of the entities PUT back, by UUID.
+	updates map[string]map[string]interface{}
+}
+
+func (f *fakePrism) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if user, password, ok := r.BasicAuth(); !ok || user != "admin" || password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	path := strings.TrimPrefix(r.URL.Path, "/api/nutanix/v3")
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch {
+	case r.Method == http.MethodPost && path == "/vms/list":
+		var entities []map[string]interface{}
+		for _, uuid := range sortedUUIDs(f.vms) {
+			entities = append(entities, f.vms[uuid])
+		}
+		writeJSON(w, map[string]interface{}{
+			"metadata": map[string]int{"total_matches": len(entities)},
+			"entities": entities,
+		})
+	case r.Method == http.MethodPost && path == "/volume_groups/list":
+		writeJSON(w, map[string]interface{}{"metadata": map[string]int{"total_matches": 0}})
+	case parts[0] == "categories" && len(parts) == 2:
+		key := parts[1]
+		if r.Method == http.MethodPut {
+			f.categories[key] = make(map[string]bool)
+			f.created = append(f.created, key)
+		} else if _, ok := f.categories[key]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case parts[0] == "categories" && len(parts) == 3:
+		key, value := parts[1], parts[2]
+		if r.Method == http.MethodPut {
+			f.categories[key][value] = true
+			f.created = append(f.created, key+":"+value)
+		} else if !f.categories[key][value] {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	case parts[0] == "vms" && len(parts) == 2:
+		vm, ok := f.vms[parts[1]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodGet {
+			writeJSON(w, vm)
+			return
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !reflect.DeepEqual(body["spec"], vm["spec"]) {
+			http.Error(w, "spec changed", http.StatusBadRequest)
+			return
+		}
+		f.updates[parts[1]] = body["metadata"].(map[string]interface{})
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func sortedUUIDs(vms map[string]map[string]interface{}) []string {
+	uuids := make([]string, 0, len(vms))
+	for uuid := range vms {
+		uuids = append(uuids, uuid)
+	}
+	sort.Strings(uuids)
+	return uuids
+}
+
+func vm(uuid, name string, metadata map[string]interface{}) map[string]interface{} {
+	metadata["uuid"] = uuid
+	metadata["kind"] = "vm"
+	return map[string]interface{}{
+		"api_version": "3.1",
+		"metadata":    metadata,
+		"spec":        map[string]interface{}{"name": name},
+	}
+}
+
+// startFakePrism points NUTANIX_ENDPOINT at a fakePrism and returns a
+// client for the cluster's Infrastructure.
+func startFakePrism(t *testing.T, f *fakePrism) client.Client {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	t.Setenv("NUTANIX_ENDPOINT", server.URL)
+	t.Setenv("NUTANIX_USERNAME", "admin")
+	t.Setenv("NUTANIX_PASSWORD", "secret")
+
+	scheme := runtime.NewScheme()
+	if err := configv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{InfrastructureName: testInfraID},
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).WithStatusSubresource(infra).Build()
+}
+
+func TestListNutanixResources(t *testing.T) {
+	clusterKey := "kubernetes-io-cluster-" + testInfraID
+	f := &fakePrism{vms: map[string]map[string]interface{}{
+		"uuid-1": vm("uuid-1", "master-0", map[string]interface{}{"categories": map[string]string{clusterKey: "owned"}}),
+		"uuid-2": vm("uuid-2", testInfraID+"-worker-a", map[string]interface{}{}),
+		"uuid-3": vm("uuid-3", "other-cluster-worker", map[string]interface{}{"categories": map[string]string{"Owner": "x"}}),
+	}}
+	k8sClient := startFakePrism(t, f)
+
+	resources, err := ListNutanixResources(context.Background(), k8sClient)
+	if err != nil {
+		t.Fatalf("ListNutanixResources() error = %v", err)
+	}
+	var ids []string
+	for _, res := range resources {
+		ids = append(ids, res.ID)
+	}
+	if want := []string{"uuid-1", "uuid-2"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("discovered %v, want %v", ids, want)
+	}
+	if got := resources[0].Tags[clusterKey]; got != "owned" {
+		t.Errorf("categories of %s = %v, want the cluster category", ids[0], resources[0].Tags)
+	}
+}
+
+func TestUpdateResourceTags(t *testing.T) {
+	tags := map[string]string{"Owner": "DevOps", "Team": "infra"}
+
+	tests := []struct {
+		name       string
+		metadata   map[string]interface{}
+		wantField  string
+		wantValues map[string]interface{}
+	}{
+		{
+			name:      "categories",
+			metadata:  map[string]interface{}{"spec_version": 3, "categories": map[string]interface{}{"Env": "prod"}},
+			wantField: "categories",
+			wantValues: map[string]interface{}{
+				"Env": "prod", "Owner": "DevOps", "Team": "infra",
+			},
+		},
+		{
+			name: "categories mapping",
+			metadata: map[string]interface{}{
+				"spec_version":           3,
+				"use_categories_mapping": true,
+				"categories_mapping":     map[string]interface{}{"Env": []interface{}{"prod"}},
+			},
+			wantField: "categories_mapping",
+			wantValues: map[string]interface{}{
+				"Env": []interface{}{"prod"}, "Owner": []interface{}{"DevOps"}, "Team": []interface{}{"infra"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakePrism{
+				vms:        map[string]map[string]interface{}{"uuid-1": vm("uuid-1", "master-0", tt.metadata)},
+				categories: map[string]map[string]bool{"Owner": {"Admin": true}},
+				updates:    make(map[string]map[string]interface{}),
+			}
+			k8sClient := startFakePrism(t, f)
+
+			resources := []infraType.CloudResource{{
+				CloudProvider: infraType.CloudPlatformNutanix,
+				Type:          infraType.CloudResourceTypeNutanixVM,
+				ID:            "uuid-1",
+			}}
+			if err := UpdateResourceTags(context.Background(), k8sClient, resources, tags); err != nil {
+				t.Fatalf("UpdateResourceTags() error = %v", err)
+			}
+
+			sort.Strings(f.created)
+			if want := []string{"Owner:DevOps", "Team", "Team:infra"}; !reflect.DeepEqual(f.created, want) {
+				t.Errorf("created categories %v, want %v", f.created, want)
+			}
+			metadata := f.updates["uuid-1"]
+			if metadata == nil {
+				t.Fatal("the VM was not updated")
+			}
+			if got := metadata[tt.wantField]; !reflect.DeepEqual(got, tt.wantValues) {
+				t.Errorf("%s = %v, want %v", tt.wantField, got, tt.wantValues)
+			}
+			if got := metadata["spec_version"]; got != float64(3) {
+				t.Errorf("spec_version = %v, want it sent back unchanged", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package nutanix
+
+import (
+	"fmt"
+)
+
+const (
+	nutanixKeyMaxLength   = 64
+	nutanixValueMaxLength = 64
+)
+
+// IsValidNutanixTag validates tags against the Prism category name and
+// value limits. A category value cannot be empty.
+func IsValidNutanixTag(tags map[string]string) error {
+	for key, value := range tags {
+		if len(key) == 0 || len(key) > nutanixKeyMaxLength {
+			return fmt.Errorf("Nutanix category name length must be 1-%d characters", nutanixKeyMaxLength)
+		}
+		if len(value) == 0 || len(value) > nutanixValueMaxLength {
+			return fmt.Errorf("Nutanix category value length must be 1-%d characters", nutanixValueMaxLength)
+		}
+	}
+	return nil
+}
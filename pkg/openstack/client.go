@@ -0,0 +1,326 @@
+package openstack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/yaml"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// authOptions holds the Keystone credentials, read from the standard OS_*
+// environment variables or from a clouds.yaml entry.
+type authOptions struct {
+	AuthURL                     string `json:"auth_url"`
+	Username                    string `json:"username"`
+	Password                    string `json:"password"`
+	ProjectID                   string `json:"project_id"`
+	ProjectName                 string `json:"project_name"`
+	UserDomainName              string `json:"user_domain_name"`
+	ProjectDomainName           string `json:"project_domain_name"`
+	ApplicationCredentialID     string `json:"application_credential_id"`
+	ApplicationCredentialSecret string `json:"application_credential_secret"`
+}
+
+type cloudsFile struct {
+	Clouds map[string]struct {
+		Auth       authOptions `json:"auth"`
+		RegionName string      `json:"region_name"`
+	} `json:"clouds"`
+}
+
+// apiClient is a minimal OpenStack REST client. It authenticates against
+// Keystone v3 and resolves service endpoints from the token catalog, so a
+// local stand-in only has to serve a catalog pointing back at itself.
+type apiClient struct {
+	httpClient *http.Client
+	token      string
+	region     string
+	catalog    []catalogEntry
+}
+
+type catalogEntry struct {
+	Type      string `json:"type"`
+	Endpoints []struct {
+		Interface string `json:"interface"`
+		Region    string `json:"region"`
+		RegionID  string `json:"region_id"`
+		URL       string `json:"url"`
+	} `json:"endpoints"`
+}
+
+// newClient authenticates using OS_* variables when OS_AUTH_URL is set and
+// falls back to the clouds.yaml entry named by OS_CLOUD or cloudName.
+func newClient(ctx context.Context, cloudName string) (*apiClient, error) {
+	opts, region, err := loadAuthOptions(cloudName)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &apiClient{
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+		region:     region,
+	}
+	if err := c.authenticate(ctx, opts); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func loadAuthOptions(cloudName string) (authOptions, string, error) {
+	if authURL := os.Getenv("OS_AUTH_URL"); authURL != "" {
+		return authOptions{
+			AuthURL:                     authURL,
+			Username:                    os.Getenv("OS_USERNAME"),
+			Password:                    os.Getenv("OS_PASSWORD"),
+			ProjectID:                   os.Getenv("OS_PROJECT_ID"),
+			ProjectName:                 os.Getenv("OS_PROJECT_NAME"),
+			UserDomainName:              os.Getenv("OS_USER_DOMAIN_NAME"),
+			ProjectDomainName:           os.Getenv("OS_PROJECT_DOMAIN_NAME"),
+			ApplicationCredentialID:     os.Getenv("OS_APPLICATION_CREDENTIAL_ID"),
+			ApplicationCredentialSecret: os.Getenv("OS_APPLICATION_CREDENTIAL_SECRET"),
+		}, os.Getenv("OS_REGION_NAME"), nil
+	}
+
+	if name := os.Getenv("OS_CLOUD"); name != "" {
+		cloudName = name
+	}
+	if cloudName == "" {
+		cloudName = "openstack"
+	}
+
+	for _, path := range cloudsYAMLPaths() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var clouds cloudsFile
+		if err := yaml.Unmarshal(data, &clouds); err != nil {
+			return authOptions{}, "", fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+		if cloud, ok := clouds.Clouds[cloudName]; ok {
+			region := cloud.RegionName
+			if r := os.Getenv("OS_REGION_NAME"); r != "" {
+				region = r
+			}
+			return cloud.Auth, region, nil
+		}
+	}
+	return authOptions{}, "", fmt.Errorf("no OpenStack credentials: set OS_AUTH_URL or provide cloud %q in clouds.yaml", cloudName)
+}
+
+func cloudsYAMLPaths() []string {
+	var paths []string
+	if p := os.Getenv("OS_CLIENT_CONFIG_FILE"); p != "" {
+		paths = append(paths, p)
+	}
+	paths = append(paths, "clouds.yaml")
+	if home, err := os.UserHomeDir(); err == nil {
+		paths = append(paths, filepath.Join(home, ".config", "openstack", "clouds.yaml"))
+	}
+	return append(paths, "/etc/openstack/clouds.yaml")
+}
+
+// authenticate requests a project scoped token. Application credentials
+// carry their own scope.
+func (c *apiClient) authenticate(ctx context.Context, opts authOptions) error {
+	identity := map[string]interface{}{}
+	var auth map[string]interface{}
+
+	if opts.ApplicationCredentialID != "" {
+		identity["methods"] = []string{"application_credential"}
+		identity["application_credential"] = map[string]string{
+			"id":     opts.ApplicationCredentialID,
+			"secret": opts.ApplicationCredentialSecret,
+		}
+		auth = map[string]interface{}{"identity": identity}
+	} else {
+		identity["methods"] = []string{"password"}
+		identity["password"] = map[string]interface{}{
+			"user": map[string]interface{}{
+				"name":     opts.Username,
+				"password": opts.Password,
+				"domain":   map[string]string{"name": defaultString(opts.UserDomainName, "Default")},
+			},
+		}
+
+		project := map[string]interface{}{}
+		if opts.ProjectID != "" {
+			project["id"] = opts.ProjectID
+		} else {
+			project["name"] = opts.ProjectName
+			project["domain"] = map[string]string{"name": defaultString(opts.ProjectDomainName, "Default")}
+		}
+		auth = map[string]interface{}{
+			"identity": identity,
+			"scope":    map[string]interface{}{"project": project},
+		}
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"auth": auth})
+	if err != nil {
+		return err
+	}
+
+	authURL := strings.TrimSuffix(opts.AuthURL, "/")
+	if !strings.HasSuffix(authURL, "/v3") {
+		authURL += "/v3"
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, authURL+"/auth/tokens", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("keystone token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		data, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%w: keystone token request failed: %s: %s", infraType.ErrCredentials, resp.Status, strings.TrimSpace(string(data)))
+	}
+
+	var token struct {
+		Token struct {
+			Catalog []catalogEntry `json:"catalog"`
+		} `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("failed to decode keystone token: %w", err)
+	}
+
+	c.token = resp.Header.Get("X-Subject-Token")
+	c.catalog = token.Token.Catalog
+	return nil
+}
+
+// endpoint returns the public endpoint of the first matching service type in
+// the client's region.
+func (c *apiClient) endpoint(serviceTypes ...string) (string, error) {
+	for _, serviceType := range serviceTypes {
+		for _, entry := range c.catalog {
+			if entry.Type != serviceType {
+				continue
+			}
+			for _, ep := range entry.Endpoints {
+				if ep.Interface != "public" {
+					continue
+				}
+				if c.region != "" && ep.RegionID != c.region && ep.Region != c.region {
+					continue
+				}
+				return strings.TrimSuffix(ep.URL, "/"), nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no public %s endpoint in the service catalog", strings.Join(serviceTypes, "/"))
+}
+
+// do sends an authenticated request and returns the response. Non-2xx
+// responses are returned as errors.
+func (c *apiClient) do(ctx context.Context, method, rawURL string, body interface{}, header http.Header) (*http.Response, []byte, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("X-Auth-Token", c.token)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, &infraType.StatusError{
+			Method:     method,
+			Path:       req.URL.Path,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+			Body:       strings.TrimSpace(string(data)),
+		}
+	}
+	return resp, data, nil
+}
+
+// listCollection follows the <collection>_links "next" links Nova, Cinder
+// and Neutron return for paginated lists.
+func (c *apiClient) listCollection(ctx context.Context, rawURL, collection string) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+
+	for next := rawURL; next != ""; {
+		_, data, err := c.do(ctx, http.MethodGet, next, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var page map[string]json.RawMessage
+		if err := json.Unmarshal(data, &page); err != nil {
+			return nil, err
+		}
+
+		var pageItems []json.RawMessage
+		if err := json.Unmarshal(page[collection], &pageItems); err != nil {
+			return nil, fmt.Errorf("error decoding %s: %w", collection, err)
+		}
+		items = append(items, pageItems...)
+
+		next = ""
+		if raw, ok := page[collection+"_links"]; ok {
+			var links []struct {
+				Rel  string `json:"rel"`
+				Href string `json:"href"`
+			}
+			if err := json.Unmarshal(raw, &links); err != nil {
+				return nil, fmt.Errorf("error decoding %s links: %w", collection, err)
+			}
+			for _, link := range links {
+				if link.Rel == "next" {
+					next = link.Href
+				}
+			}
+		}
+	}
+	return items, nil
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
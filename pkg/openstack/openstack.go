@@ -0,0 +1,279 @@
+package openstack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+const (
+	// ClusterIDKey is the tag (Neutron) or metadata key (Nova, Cinder,
+	// Swift) the installer uses to mark resources owned by the cluster.
+	ClusterIDKey = "openshiftClusterID"
+	// csiClusterKey is set by the Cinder CSI driver on provisioned volumes.
+	csiClusterKey = "cinder.csi.openstack.org/cluster"
+)
+
+// neutronCollections maps Neutron collections to resource types. The JSON
+// key differs from the URL path for security groups.
+var neutronCollections = []struct {
+	path      string
+	key       string
+	cloudType infraType.CloudResourceType
+}{
+	{"ports", "ports", infraType.CloudResourceTypeOpenStackPort},
+	{"networks", "networks", infraType.CloudResourceTypeOpenStackNetwork},
+	{"subnets", "subnets", infraType.CloudResourceTypeOpenStackSubnet},
+	{"routers", "routers", infraType.CloudResourceTypeOpenStackRouter},
+	{"security-groups", "security_groups", infraType.CloudResourceTypeOpenStackSecurityGroup},
+}
+
+// neutronPaths maps a Neutron resource type back to its URL path.
+var neutronPaths = map[infraType.CloudResourceType]string{}
+
+func init() {
+	for _, collection := range neutronCollections {
+		neutronPaths[collection.cloudType] = collection.path
+	}
+}
+
+func ListOpenStackResources(ctx context.Context, k8sClient client.Client) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	infraID, cloudName, err := getClusterInfo(ctx, k8sClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster info: %w", err)
+	}
+
+	c, err := newClient(ctx, cloudName)
+	if err != nil {
+		return nil, fmt.Errorf("OpenStack authentication error: %w", err)
+	}
+
+	if serverRes, err := listServers(ctx, c, infraID); err == nil {
+		resources = append(resources, serverRes...)
+	}
+	if volumeRes, err := listVolumes(ctx, c, infraID); err == nil {
+		resources = append(resources, volumeRes...)
+	}
+	for _, collection := range neutronCollections {
+		if netRes, err := listNeutronResources(ctx, c, collection.path, collection.key, collection.cloudType, infraID); err == nil {
+			resources = append(resources, netRes...)
+		}
+	}
+	if containerRes, err := listContainers(ctx, c, infraID); err == nil {
+		resources = append(resources, containerRes...)
+	}
+
+	return resources, nil
+}
+
+type metadataItem struct {
+	ID       string            `json:"id"`
+	Name     string            `json:"name"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+func listServers(ctx context.Context, c *apiClient, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	endpoint, err := c.endpoint("compute")
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := c.listCollection(ctx, endpoint+"/servers/detail", "servers")
+	if err != nil {
+		return nil, fmt.Errorf("error listing servers: %w", err)
+	}
+
+	for _, raw := range items {
+		var server metadataItem
+		if err := json.Unmarshal(raw, &server); err != nil {
+			return nil, err
+		}
+		if server.Metadata[ClusterIDKey] != infraID {
+			continue
+		}
+		resources = append(resources, infraType.CloudResource{
+			CloudProvider: infraType.CloudPlatformOpenStack,
+			Type:          infraType.CloudResourceTypeOpenStackServer,
+			ID:            server.ID,
+			Name:          server.Name,
+			Tags:          server.Metadata,
+		})
+	}
+	return resources, nil
+}
+
+func listVolumes(ctx context.Context, c *apiClient, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	endpoint, err := c.endpoint("volumev3", "block-storage", "volume")
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := c.listCollection(ctx, endpoint+"/volumes/detail", "volumes")
+	if err != nil {
+		return nil, fmt.Errorf("error listing volumes: %w", err)
+	}
+
+	for _, raw := range items {
+		var volume metadataItem
+		if err := json.Unmarshal(raw, &volume); err != nil {
+			return nil, err
+		}
+		if volume.Metadata[ClusterIDKey] != infraID && volume.Metadata[csiClusterKey] != infraID {
+			continue
+		}
+		resources = append(resources, infraType.CloudResource{
+			CloudProvider: infraType.CloudPlatformOpenStack,
+			Type:          infraType.CloudResourceTypeOpenStackVolume,
+			ID:            volume.ID,
+			Name:          volume.Name,
+			Tags:          volume.Metadata,
+		})
+	}
+	return resources, nil
+}
+
+// listNeutronResources lists a Neutron collection filtered server side on
+// the openshiftClusterID=<infraID> tag.
+func listNeutronResources(ctx context.Context, c *apiClient, path, key string,
+	cloudType infraType.CloudResourceType, infraID string) ([]infraType.CloudResource, error) {
+
+	var resources []infraType.CloudResource
+
+	endpoint, err := c.endpoint("network")
+	if err != nil {
+		return nil, err
+	}
+
+	u := fmt.Sprintf("%s/v2.0/%s?tags=%s", endpoint, path, url.QueryEscape(formatTag(ClusterIDKey, infraID)))
+	items, err := c.listCollection(ctx, u, key)
+	if err != nil {
+		return nil, fmt.Errorf("error listing %s: %w", path, err)
+	}
+
+	for _, raw := range items {
+		var item struct {
+			ID   string   `json:"id"`
+			Name string   `json:"name"`
+			Tags []string `json:"tags"`
+		}
+		if err := json.Unmarshal(raw, &item); err != nil {
+			return nil, err
+		}
+		resources = append(resources, infraType.CloudResource{
+			CloudProvider: infraType.CloudPlatformOpenStack,
+			Type:          cloudType,
+			ID:            item.ID,
+			Name:          item.Name,
+			Tags:          parseTags(item.Tags),
+		})
+	}
+	return resources, nil
+}
+
+// listContainers lists the Swift containers carrying the cluster ID in their
+// metadata. Swift has no metadata filter, so every container is inspected.
+func listContainers(ctx context.Context, c *apiClient, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	endpoint, err := c.endpoint("object-store")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	marker := ""
+	for {
+		u := endpoint + "?format=json"
+		if marker != "" {
+			u += "&marker=" + url.QueryEscape(marker)
+		}
+		_, data, err := c.do(ctx, http.MethodGet, u, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("error listing containers: %w", err)
+		}
+
+		var page []struct {
+			Name string `json:"name"`
+		}
+		if len(data) > 0 {
+			if err := json.Unmarshal(data, &page); err != nil {
+				return nil, fmt.Errorf("error decoding containers: %w", err)
+			}
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, container := range page {
+			names = append(names, container.Name)
+		}
+		marker = page[len(page)-1].Name
+	}
+
+	for _, name := range names {
+		metadata, err := getContainerMetadata(ctx, c, endpoint, name)
+		if err != nil {
+			return nil, err
+		}
+		if metadata[strings.ToLower(ClusterIDKey)] != infraID {
+			continue
+		}
+		resources = append(resources, infraType.CloudResource{
+			CloudProvider: infraType.CloudPlatformOpenStack,
+			Type:          infraType.CloudResourceTypeOpenStackContainer,
+			ID:            name,
+			Name:          name,
+			Tags:          metadata,
+		})
+	}
+	return resources, nil
+}
+
+// getContainerMetadata returns the X-Container-Meta-* headers of a
+// container. Swift treats metadata keys case-insensitively, so keys are
+// returned in lower case.
+func getContainerMetadata(ctx context.Context, c *apiClient, endpoint, name string) (map[string]string, error) {
+	resp, _, err := c.do(ctx, http.MethodHead, endpoint+"/"+url.PathEscape(name), nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error reading container %s: %w", name, err)
+	}
+
+	const prefix = "x-container-meta-"
+	metadata := make(map[string]string)
+	for header, values := range resp.Header {
+		lower := strings.ToLower(header)
+		if strings.HasPrefix(lower, prefix) && len(values) > 0 {
+			metadata[strings.TrimPrefix(lower, prefix)] = values[0]
+		}
+	}
+	return metadata, nil
+}
+
+// getClusterInfo returns the infraID and the clouds.yaml entry the cluster
+// was installed with.
+func getClusterInfo(ctx context.Context, k8sClient client.Client) (string, string, error) {
+	infra := &configv1.Infrastructure{}
+	if err := k8sClient.Get(ctx,
+		client.ObjectKey{Name: "cluster"}, infra); err != nil {
+		return "", "", fmt.Errorf("failed to get Infrastructure: %w", err)
+	}
+
+	cloudName := ""
+	if infra.Status.PlatformStatus != nil && infra.Status.PlatformStatus.OpenStack != nil {
+		cloudName = infra.Status.PlatformStatus.OpenStack.CloudName
+	}
+	return infra.Status.InfrastructureName, cloudName, nil
+}
@@ -0,0 +1,386 @@
+package openstack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+const testInfraID = "mycluster-x7k2p"
+
+// fakeOpenStack is an HTTP stand-in for Keystone, Nova, Cinder, Neutron and
+// Swift. The token catalog points every service back at the server, under
+// /compute, /volume, /network and /swift.
+type fakeOpenStack struct {
+	mu sync.Mutex
+	// servers and volumes are the Nova servers and Cinder volumes.
+	servers, volumes []metadataItem
+	// neutron are the items of each Neutron collection by JSON key.
+	neutron map[string][]neutronItem
+	// containers are the metadata of the Swift containers by name.
+	containers map[string]map[string]string
+	// calls are the writes, as "<method> <path>" followed by the metadata
+	// sent, if any, as " k=v,...".
+	calls []string
+}
+
+type neutronItem struct {
+	ID   string   `json:"id"`
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+}
+
+func (f *fakeOpenStack) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/v3/auth/tokens" {
+		f.serveToken(w, r)
+		return
+	}
+	if r.Header.Get("X-Auth-Token") != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	service, path, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	parts := strings.Split(path, "/")
+
+	switch {
+	case r.Method == http.MethodGet && service == "compute" && path == "servers/detail":
+		writeJSON(w, map[string]interface{}{"servers": f.servers})
+	case r.Method == http.MethodGet && service == "volume" && path == "volumes/detail":
+		writeJSON(w, map[string]interface{}{"volumes": f.volumes})
+	case r.Method == http.MethodPost && (service == "compute" || service == "volume") && len(parts) == 3 && parts[2] == "metadata":
+		if !f.hasMetadataItem(service, parts[1]) {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		var body struct {
+			Metadata map[string]string `json:"metadata"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.record(r, body.Metadata)
+		writeJSON(w, body)
+	case r.Method == http.MethodGet && service == "network" && len(parts) == 2:
+		key := strings.ReplaceAll(parts[1], "-", "_")
+		var items []neutronItem
+		for _, item := range f.neutron[key] {
+			if hasTag(item.Tags, r.URL.Query().Get("tags")) {
+				items = append(items, item)
+			}
+		}
+		writeJSON(w, map[string]interface{}{key: items})
+	case (r.Method == http.MethodPut || r.Method == http.MethodDelete) && service == "network" && len(parts) == 5 && parts[3] == "tags":
+		f.record(r, nil)
+	case r.Method == http.MethodGet && service == "swift" && path == "":
+		var page []map[string]string
+		if r.URL.Query().Get("marker") == "" {
+			for _, name := range sortedKeys(f.containers) {
+				page = append(page, map[string]string{"name": name})
+			}
+		}
+		writeJSON(w, page)
+	case r.Method == http.MethodHead && service == "swift":
+		metadata, ok := f.containers[path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		for k, v := range metadata {
+			w.Header().Set("X-Container-Meta-"+k, v)
+		}
+	case r.Method == http.MethodPost && service == "swift":
+		metadata := make(map[string]string)
+		for header, values := range r.Header {
+			if k, ok := strings.CutPrefix(header, "X-Container-Meta-"); ok {
+				metadata[k] = values[0]
+			}
+		}
+		f.record(r, metadata)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeOpenStack) serveToken(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		Auth struct {
+			Identity struct {
+				Password struct {
+					User struct {
+						Name     string `json:"name"`
+						Password string `json:"password"`
+					} `json:"user"`
+				} `json:"password"`
+			} `json:"identity"`
+		} `json:"auth"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if user := body.Auth.Identity.Password.User; user.Name != "admin" || user.Password != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	var catalog []map[string]interface{}
+	for serviceType, path := range map[string]string{
+		"compute": "compute", "volumev3": "volume", "network": "network", "object-store": "swift",
+	} {
+		catalog = append(catalog, map[string]interface{}{
+			"type": serviceType,
+			"endpoints": []map[string]string{
+				{"interface": "internal", "region_id": "RegionOne", "url": "http://invalid.internal/" + path},
+				{"interface": "public", "region_id": "RegionOne", "url": "http://" + r.Host + "/" + path},
+			},
+		})
+	}
+	w.Header().Set("X-Subject-Token", "token")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, map[string]interface{}{"token": map[string]interface{}{"catalog": catalog}})
+}
+
+func (f *fakeOpenStack) hasMetadataItem(service, id string) bool {
+	items := f.servers
+	if service == "volume" {
+		items = f.volumes
+	}
+	for _, item := range items {
+		if item.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeOpenStack) record(r *http.Request, metadata map[string]string) {
+	call := r.Method + " " + r.URL.Path
+	if len(metadata) > 0 {
+		var pairs []string
+		for _, k := range sortedKeys(metadata) {
+			pairs = append(pairs, k+"="+metadata[k])
+		}
+		call += " " + strings.Join(pairs, ",")
+	}
+	f.calls = append(f.calls, call)
+}
+
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// startFakeOpenStack points OS_AUTH_URL at a fakeOpenStack and returns a
+// client for the cluster's Infrastructure.
+func startFakeOpenStack(t *testing.T, f *fakeOpenStack) client.Client {
+	t.Helper()
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	t.Setenv("OS_AUTH_URL", server.URL)
+	t.Setenv("OS_USERNAME", "admin")
+	t.Setenv("OS_PASSWORD", "secret")
+	t.Setenv("OS_PROJECT_NAME", "openshift")
+	t.Setenv("OS_REGION_NAME", "RegionOne")
+
+	scheme := runtime.NewScheme()
+	if err := configv1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status:     configv1.InfrastructureStatus{InfrastructureName: testInfraID},
+	}
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).WithStatusSubresource(infra).Build()
+}
+
+func TestListOpenStackResources(t *testing.T) {
+	owned := map[string]string{ClusterIDKey: testInfraID}
+	clusterTag := formatTag(ClusterIDKey, testInfraID)
+	f := &fakeOpenStack{
+		servers: []metadataItem{
+			{ID: "server-1", Name: "master-0", Metadata: owned},
+			{ID: "server-2", Name: "other", Metadata: map[string]string{ClusterIDKey: "other-cluster"}},
+		},
+		volumes: []metadataItem{
+			{ID: "volume-1", Name: "pvc-1", Metadata: map[string]string{csiClusterKey: testInfraID}},
+			{ID: "volume-2", Name: "unowned"},
+		},
+		neutron: map[string][]neutronItem{
+			"ports":           {{ID: "port-1", Name: "master-0-port", Tags: []string{clusterTag, "Owner=DevOps"}}},
+			"security_groups": {{ID: "sg-1", Name: "master", Tags: []string{clusterTag}}, {ID: "sg-2", Tags: []string{"Owner=DevOps"}}},
+		},
+		containers: map[string]map[string]string{
+			"image-registry": {"Openshiftclusterid": testInfraID},
+			"other":          {"Openshiftclusterid": "other-cluster"},
+		},
+	}
+	k8sClient := startFakeOpenStack(t, f)
+
+	resources, err := ListOpenStackResources(context.Background(), k8sClient)
+	if err != nil {
+		t.Fatalf("ListOpenStackResources() error = %v", err)
+	}
+	var got []string
+	for _, res := range resources {
+		got = append(got, fmt.Sprintf("%s %s", res.Type, res.ID))
+	}
+	want := []string{
+		"OpenStackServer server-1",
+		"OpenStackVolume volume-1",
+		"OpenStackPort port-1",
+		"OpenStackSecurityGroup sg-1",
+		"OpenStackContainer image-registry",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("discovered %q, want %q", got, want)
+	}
+	if want := map[string]string{ClusterIDKey: testInfraID, "Owner": "DevOps"}; !reflect.DeepEqual(resources[2].Tags, want) {
+		t.Errorf("tags of port-1 = %v, want %v", resources[2].Tags, want)
+	}
+}
+
+func TestUpdateResourceTags(t *testing.T) {
+	tags := map[string]string{"Owner": "DevOps"}
+
+	tests := []struct {
+		name      string
+		resource  infraType.CloudResource
+		wantCalls []string
+	}{
+		{
+			name:      "Nova server metadata",
+			resource:  infraType.CloudResource{Type: infraType.CloudResourceTypeOpenStackServer, ID: "server-1"},
+			wantCalls: []string{"POST /compute/servers/server-1/metadata Owner=DevOps"},
+		},
+		{
+			name:      "Cinder volume metadata",
+			resource:  infraType.CloudResource{Type: infraType.CloudResourceTypeOpenStackVolume, ID: "volume-1"},
+			wantCalls: []string{"POST /volume/volumes/volume-1/metadata Owner=DevOps"},
+		},
+		{
+			name:      "new Neutron tag",
+			resource:  infraType.CloudResource{Type: infraType.CloudResourceTypeOpenStackSecurityGroup, ID: "sg-1"},
+			wantCalls: []string{"PUT /network/v2.0/security-groups/sg-1/tags/Owner=DevOps"},
+		},
+		{
+			name: "Neutron tag with another value",
+			resource: infraType.CloudResource{
+				Type: infraType.CloudResourceTypeOpenStackPort,
+				ID:   "port-1",
+				Tags: map[string]string{"Owner": "Admin"},
+			},
+			wantCalls: []string{
+				"DELETE /network/v2.0/ports/port-1/tags/Owner=Admin",
+				"PUT /network/v2.0/ports/port-1/tags/Owner=DevOps",
+			},
+		},
+		{
+			name: "Neutron tag with the same value",
+			resource: infraType.CloudResource{
+				Type: infraType.CloudResourceTypeOpenStackPort,
+				ID:   "port-1",
+				Tags: map[string]string{"Owner": "DevOps"},
+			},
+		},
+		{
+			name:      "Swift container metadata",
+			resource:  infraType.CloudResource{Type: infraType.CloudResourceTypeOpenStackContainer, ID: "image-registry", Name: "image-registry"},
+			wantCalls: []string{"POST /swift/image-registry Owner=DevOps"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &fakeOpenStack{
+				servers: []metadataItem{{ID: "server-1"}},
+				volumes: []metadataItem{{ID: "volume-1"}},
+			}
+			k8sClient := startFakeOpenStack(t, f)
+
+			if err := UpdateResourceTags(context.Background(), k8sClient, []infraType.CloudResource{tt.resource}, tags); err != nil {
+				t.Fatalf("UpdateResourceTags() error = %v", err)
+			}
+			if !reflect.DeepEqual(f.calls, tt.wantCalls) {
+				t.Errorf("calls = %q, want %q", f.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestUpdateResourceTagsPartialFailure(t *testing.T) {
+	f := &fakeOpenStack{
+		servers: []metadataItem{{ID: "server-1"}},
+		volumes: []metadataItem{{ID: "volume-1"}},
+	}
+	k8sClient := startFakeOpenStack(t, f)
+
+	resources := []infraType.CloudResource{
+		{Type: infraType.CloudResourceTypeOpenStackServer, ID: "server-1"},
+		{Type: infraType.CloudResourceTypeOpenStackServer, ID: "server-gone"},
+		{Type: infraType.CloudResourceTypeOpenStackVolume, ID: "volume-1"},
+	}
+	progress := &infraType.Progress{}
+	ctx := infraType.WithProgress(context.Background(), progress)
+	err := UpdateResourceTags(ctx, k8sClient, resources, map[string]string{"Owner": "DevOps"})
+
+	var multi infraType.MultiError
+	if !errors.As(err, &multi) || len(multi) != 1 {
+		t.Fatalf("UpdateResourceTags() error = %v, want a MultiError of one error", err)
+	}
+	if !strings.Contains(multi[0].Error(), "failed to update server-gone (OpenStackServer)") {
+		t.Errorf("error = %v, want it to name server-gone", multi[0])
+	}
+	var statusErr *infraType.StatusError
+	if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+		t.Errorf("error = %v, want the 404 as a StatusError", err)
+	}
+
+	want := []string{
+		"POST /compute/servers/server-1/metadata Owner=DevOps",
+		"POST /volume/volumes/volume-1/metadata Owner=DevOps",
+	}
+	if !reflect.DeepEqual(f.calls, want) {
+		t.Errorf("calls = %q, want %q", f.calls, want)
+	}
+	for i, written := range []bool{true, false, true} {
+		if got := progress.Written(resources[i]); got != written {
+			t.Errorf("Written(%s) = %v, want %v", resources[i].ID, got, written)
+		}
+	}
+}
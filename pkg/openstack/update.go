@@ -0,0 +1,124 @@
+package openstack
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// UpdateResourceTags writes the tags as Nova server metadata, Cinder volume
+// metadata and Swift container metadata, and as key=value string tags on
+// Neutron resources. Existing entries with other keys are preserved.
+func UpdateResourceTags(ctx context.Context, k8sClient client.Client, resources []infraType.CloudResource, tags map[string]string) error {
+
+	_, cloudName, err := getClusterInfo(ctx, k8sClient)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster info: %w", err)
+	}
+
+	c, err := newClient(ctx, cloudName)
+	if err != nil {
+		return fmt.Errorf("OpenStack authentication error: %w", err)
+	}
+
+	return infraType.ForEachResource(ctx, resources, "update", func(ctx context.Context, resource infraType.CloudResource) error {
+		var err error
+		switch resource.Type {
+		case infraType.CloudResourceTypeOpenStackServer:
+			err = updateMetadata(ctx, c, []string{"compute"}, "servers", resource, tags)
+		case infraType.CloudResourceTypeOpenStackVolume:
+			err = updateMetadata(ctx, c, []string{"volumev3", "block-storage", "volume"}, "volumes", resource, tags)
+		case infraType.CloudResourceTypeOpenStackPort,
+			infraType.CloudResourceTypeOpenStackNetwork,
+			infraType.CloudResourceTypeOpenStackSubnet,
+			infraType.CloudResourceTypeOpenStackRouter,
+			infraType.CloudResourceTypeOpenStackSecurityGroup:
+			err = updateNeutronTags(ctx, c, resource, tags)
+		case infraType.CloudResourceTypeOpenStackContainer:
+			err = updateContainerMetadata(ctx, c, resource, tags)
+		default:
+			err = fmt.Errorf("unsupported resource type: %s", resource.Type)
+		}
+
+		return err
+	})
+}
+
+// updateMetadata uses the Nova and Cinder "update metadata items" call,
+// which merges the given keys into the existing metadata.
+func updateMetadata(ctx context.Context, c *apiClient, serviceTypes []string, collection string,
+	resource infraType.CloudResource, tags map[string]string) error {
+
+	endpoint, err := c.endpoint(serviceTypes...)
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("%s/%s/%s/metadata", endpoint, collection, resource.ID)
+	_, _, err = c.do(ctx, http.MethodPost, u, map[string]interface{}{"metadata": tags}, nil)
+	return err
+}
+
+// updateNeutronTags adds one key=value tag per entry. Neutron tags are plain
+// strings, so a tag for the same key with another value is removed first.
+func updateNeutronTags(ctx context.Context, c *apiClient, resource infraType.CloudResource, tags map[string]string) error {
+	endpoint, err := c.endpoint("network")
+	if err != nil {
+		return err
+	}
+
+	base := fmt.Sprintf("%s/v2.0/%s/%s/tags", endpoint, neutronPaths[resource.Type], resource.ID)
+	for k, v := range tags {
+		if current, ok := resource.Tags[k]; ok {
+			if current == v {
+				continue
+			}
+			if _, _, err := c.do(ctx, http.MethodDelete, base+"/"+url.PathEscape(formatTag(k, current)), nil, nil); err != nil {
+				return err
+			}
+		}
+		if _, _, err := c.do(ctx, http.MethodPut, base+"/"+url.PathEscape(formatTag(k, v)), nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateContainerMetadata sets X-Container-Meta-* headers. Headers that are
+// not sent are left untouched by Swift.
+func updateContainerMetadata(ctx context.Context, c *apiClient, resource infraType.CloudResource, tags map[string]string) error {
+	endpoint, err := c.endpoint("object-store")
+	if err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	for k, v := range tags {
+		header.Set("X-Container-Meta-"+k, v)
+	}
+
+	_, _, err = c.do(ctx, http.MethodPost, endpoint+"/"+url.PathEscape(resource.Name), nil, header)
+	return err
+}
+
+// Neutron tags follow the installer's key=value convention, a tag without
+// "=" is read back as a key with an empty value.
+
+func formatTag(key, value string) string {
+	return key + "=" + value
+}
+
+func parseTags(tags []string) map[string]string {
+	parsed := make(map[string]string)
+	for _, tag := range tags {
+		k, v, _ := strings.Cut(tag, "=")
+		parsed[k] = v
+	}
+	return parsed
+}
@@ -0,0 +1,36 @@
+package openstack
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	openstackKeyMaxLength   = 255
+	openstackValueMaxLength = 255
+	// neutronTagMaxLength applies to the combined key=value string.
+	neutronTagMaxLength = 255
+)
+
+// IsValidOpenStackTag validates tags against the Nova/Cinder metadata and
+// Neutron tag limits.
+func IsValidOpenStackTag(tags map[string]string) error {
+	for key, value := range tags {
+		if len(key) == 0 || len(key) > openstackKeyMaxLength {
+			return fmt.Errorf("OpenStack metadata key length must be 1-%d characters", openstackKeyMaxLength)
+		}
+		if len(value) > openstackValueMaxLength {
+			return fmt.Errorf("OpenStack metadata value length must be 0-%d characters", openstackValueMaxLength)
+		}
+		if strings.ContainsAny(key, "=,") {
+			return fmt.Errorf("OpenStack tag key %q must not contain '=' or ','", key)
+		}
+		if strings.Contains(value, ",") {
+			return fmt.Errorf("OpenStack tag value %q must not contain ','", value)
+		}
+		if len(formatTag(key, value)) > neutronTagMaxLength {
+			return fmt.Errorf("Neutron tag %s=%s exceeds %d characters", key, value, neutronTagMaxLength)
+		}
+	}
+	return nil
+}
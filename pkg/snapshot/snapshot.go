@@ -0,0 +1,194 @@
+// Package snapshot saves the tags of the resources a sync is about to
+// change, so that the change can be rolled back.
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// Version is the format version of the snapshots Save writes.
+const Version = "v1"
+
+// Snapshot is the tags of a cluster's resources before a sync.
+type Snapshot struct {
+	Version   string                  `json:"version"`
+	Time      time.Time               `json:"time"`
+	Platform  infraType.CloudPlatform `json:"platform"`
+	Resources []Resource              `json:"resources"`
+}
+
+// Resource is the tags of a resource before a sync and the tags the sync
+// changed.
+type Resource struct {
+	Type infraType.CloudResourceType `json:"type"`
+	ID   string                      `json:"id"`
+	Name string                      `json:"name,omitempty"`
+	Tags map[string]string           `json:"tags"`
+	// Changed are the tags the sync added or set to another value.
+	Changed map[string]string `json:"changed"`
+	// Removed are the keys the sync pruned.
+	Removed []string `json:"removed,omitempty"`
+}
+
+// Key identifies the resource, see CloudResource.Key.
+func (r Resource) Key() string {
+	return infraType.CloudResource{Type: r.Type, ID: r.ID, Name: r.Name}.Key()
+}
+
+// New returns an empty snapshot of the platform's resources.
+func New(cloudPlatform infraType.CloudPlatform) *Snapshot {
+	return &Snapshot{Version: Version, Time: time.Now().UTC(), Platform: cloudPlatform}
+}
+
+// Add records the current tags of the resources the tags change. A resource
+// added before keeps its original tags.
+func (s *Snapshot) Add(resources []infraType.CloudResource, tags map[string]string) {
+	index := make(map[string]int, len(s.Resources))
+	for i, r := range s.Resources {
+		index[r.Key()] = i
+	}
+
+	for _, res := range resources {
+		if res.NotTaggable {
+			continue
+		}
+		// A resource added before is compared to its original tags.
+		original := res.Tags
+		i, seen := index[res.Key()]
+		if seen {
+			original = s.Resources[i].Tags
+		}
+
+		changed := make(map[string]string)
+		for k, v := range tags {
+			if current, ok := original[k]; !ok || current != v {
+				changed[k] = v
+			}
+		}
+		if len(changed) == 0 {
+			continue
+		}
+
+		if seen {
+			for k, v := range changed {
+				s.Resources[i].Changed[k] = v
+			}
+			continue
+		}
+		index[res.Key()] = len(s.Resources)
+		s.Resources = append(s.Resources, Resource{
+			Type:    res.Type,
+			ID:      res.ID,
+			Name:    res.Name,
+			Tags:    res.Tags,
+			Changed: changed,
+		})
+	}
+}
+
+// AddRemovals records the current tags of the resources before the keys
+// are removed from them. A resource added before keeps its original tags.
+func (s *Snapshot) AddRemovals(resources []infraType.CloudResource, keys []string) {
+	index := make(map[string]int, len(s.Resources))
+	for i, r := range s.Resources {
+		index[r.Key()] = i
+	}
+
+	for _, res := range resources {
+		var removed []string
+		for _, k := range keys {
+			if _, ok := res.Tags[k]; ok {
+				removed = append(removed, k)
+			}
+		}
+		if len(removed) == 0 {
+			continue
+		}
+
+		if i, seen := index[res.Key()]; seen {
+			s.Resources[i].Removed = append(s.Resources[i].Removed, removed...)
+			continue
+		}
+		index[res.Key()] = len(s.Resources)
+		s.Resources = append(s.Resources, Resource{
+			Type:    res.Type,
+			ID:      res.ID,
+			Name:    res.Name,
+			Tags:    res.Tags,
+			Changed: map[string]string{},
+			Removed: removed,
+		})
+	}
+}
+
+// Rollback returns what undoes the changes on the resource given its
+// current tags: the original values of the changed and removed keys it had
+// before, and the changed keys it did not have.
+func (r Resource) Rollback(current map[string]string) (restore map[string]string, remove []string) {
+	keys := make([]string, 0, len(r.Changed)+len(r.Removed))
+	for k := range r.Changed {
+		keys = append(keys, k)
+	}
+	keys = append(keys, r.Removed...)
+
+	restore = make(map[string]string)
+	for _, k := range keys {
+		original, had := r.Tags[k]
+		value, has := current[k]
+		switch {
+		case had && (!has || value != original):
+			restore[k] = original
+		case !had && has:
+			remove = append(remove, k)
+		}
+	}
+	return restore, remove
+}
+
+// Save writes the snapshot to path. The file is replaced atomically, so a
+// crash leaves the previous version.
+func (s *Snapshot) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to save snapshot: %w", err)
+	}
+	return nil
+}
+
+// Load reads the snapshot at path.
+func Load(path string) (*Snapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var s Snapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse snapshot %s: %w", path, err)
+	}
+	if s.Version != Version {
+		return nil, fmt.Errorf("snapshot %s has version %q, expected %q", path, s.Version, Version)
+	}
+	return &s, nil
+}
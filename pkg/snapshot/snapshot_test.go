@@ -0,0 +1,153 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+func TestRollback(t *testing.T) {
+	tests := []struct {
+		name        string
+		resource    Resource
+		current     map[string]string
+		wantRestore map[string]string
+		wantRemove  []string
+	}{
+		{
+			name: "changed value is restored",
+			resource: Resource{
+				Tags:    map[string]string{"Owner": "Old"},
+				Changed: map[string]string{"Owner": "DevOps"},
+			},
+			current:     map[string]string{"Owner": "DevOps"},
+			wantRestore: map[string]string{"Owner": "Old"},
+		},
+		{
+			name: "added key is removed",
+			resource: Resource{
+				Tags:    map[string]string{"Env": "prod"},
+				Changed: map[string]string{"Owner": "DevOps", "Team": "infra"},
+			},
+			current:     map[string]string{"Env": "prod", "Owner": "DevOps", "Team": "infra"},
+			wantRestore: map[string]string{},
+			wantRemove:  []string{"Owner", "Team"},
+		},
+		{
+			name: "removed key is restored",
+			resource: Resource{
+				Tags:    map[string]string{"Owner": "Old"},
+				Changed: map[string]string{},
+				Removed: []string{"Owner"},
+			},
+			current:     map[string]string{},
+			wantRestore: map[string]string{"Owner": "Old"},
+		},
+		{
+			name: "rolled back resource needs nothing",
+			resource: Resource{
+				Tags:    map[string]string{"Owner": "Old"},
+				Changed: map[string]string{"Owner": "DevOps", "Team": "infra"},
+			},
+			current:     map[string]string{"Owner": "Old"},
+			wantRestore: map[string]string{},
+		},
+		{
+			name: "unrelated keys are left alone",
+			resource: Resource{
+				Tags:    map[string]string{},
+				Changed: map[string]string{"Owner": "DevOps"},
+			},
+			current:     map[string]string{"Owner": "DevOps", "Env": "prod"},
+			wantRestore: map[string]string{},
+			wantRemove:  []string{"Owner"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			restore, remove := tt.resource.Rollback(tt.current)
+			sort.Strings(remove)
+			if !reflect.DeepEqual(restore, tt.wantRestore) {
+				t.Errorf("restore = %v, want %v", restore, tt.wantRestore)
+			}
+			if !reflect.DeepEqual(remove, tt.wantRemove) {
+				t.Errorf("remove = %v, want %v", remove, tt.wantRemove)
+			}
+		})
+	}
+}
+
+func TestAdd(t *testing.T) {
+	ec2 := func(id string, tags map[string]string) infraType.CloudResource {
+		return infraType.CloudResource{Type: infraType.CloudResourceTypeAWSEC2Instance, ID: id, Tags: tags}
+	}
+
+	s := New(infraType.CloudPlatformAWS)
+	s.Add([]infraType.CloudResource{
+		ec2("i-1", map[string]string{"Owner": "Old"}),
+		ec2("i-2", map[string]string{"Owner": "DevOps"}),
+		{Type: infraType.CloudResourceTypeAWSIAMRole, ID: "role", NotTaggable: true},
+	}, map[string]string{"Owner": "DevOps"})
+	// The second sync sees the tags the first one wrote.
+	s.Add([]infraType.CloudResource{
+		ec2("i-1", map[string]string{"Owner": "DevOps"}),
+	}, map[string]string{"Owner": "Admin", "Team": "infra"})
+	s.AddRemovals([]infraType.CloudResource{
+		ec2("i-1", map[string]string{"Owner": "Admin", "Env": "prod"}),
+		ec2("i-3", map[string]string{"Env": "prod"}),
+		ec2("i-4", map[string]string{}),
+	}, []string{"Env"})
+
+	want := []Resource{
+		{
+			Type:    infraType.CloudResourceTypeAWSEC2Instance,
+			ID:      "i-1",
+			Tags:    map[string]string{"Owner": "Old"},
+			Changed: map[string]string{"Owner": "Admin", "Team": "infra"},
+			Removed: []string{"Env"},
+		},
+		{
+			Type:    infraType.CloudResourceTypeAWSEC2Instance,
+			ID:      "i-3",
+			Tags:    map[string]string{"Env": "prod"},
+			Changed: map[string]string{},
+			Removed: []string{"Env"},
+		},
+	}
+	if !reflect.DeepEqual(s.Resources, want) {
+		t.Errorf("Resources = %+v, want %+v", s.Resources, want)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+
+	s := New(infraType.CloudPlatformAWS)
+	s.Add([]infraType.CloudResource{{
+		Type: infraType.CloudResourceTypeAWSS3Bucket,
+		ID:   "bucket",
+		Tags: map[string]string{"Owner": "Old"},
+	}}, map[string]string{"Owner": "DevOps"})
+	if err := s.Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !got.Time.Equal(s.Time) || got.Platform != s.Platform || !reflect.DeepEqual(got.Resources, s.Resources) {
+		t.Errorf("Load() = %+v, want %+v", got, s)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"version":"v0"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Load() of another version succeeded")
+	}
+}
@@ -0,0 +1,224 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+
+	vapitags "github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// Key/value tags map to vCenter tag categories and tags: the key is the
+// category name and the value is the name of a tag in that category.
+// Categories are created with SINGLE cardinality, so an object carries at
+// most one value per key.
+
+// clusterCategory is the category of the tag, named after the infraID, the
+// installer attaches to the cluster's VMs.
+const clusterCategory = "openshift-%s"
+
+// tagCache resolves category and tag IDs once per run.
+type tagCache struct {
+	c          *apiClient
+	categories map[string]vapitags.Category // by ID
+	tags       map[string]vapitags.Tag      // by ID
+	loaded     bool
+}
+
+func newTagCache(c *apiClient) *tagCache {
+	return &tagCache{
+		c:          c,
+		categories: make(map[string]vapitags.Category),
+		tags:       make(map[string]vapitags.Tag),
+	}
+}
+
+func (t *tagCache) load(ctx context.Context) error {
+	if t.loaded {
+		return nil
+	}
+
+	categories, err := t.c.tags.GetCategories(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing tag categories: %w", err)
+	}
+	for _, cat := range categories {
+		t.categories[cat.ID] = cat
+	}
+
+	tags, err := t.c.tags.GetTags(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing tags: %w", err)
+	}
+	for _, tg := range tags {
+		t.tags[tg.ID] = tg
+	}
+
+	t.loaded = true
+	return nil
+}
+
+// attachedTags returns the tags attached to the object as category name to
+// tag name.
+func (t *tagCache) attachedTags(ctx context.Context, resource infraType.CloudResource) (map[string]string, error) {
+	if err := t.load(ctx); err != nil {
+		return nil, err
+	}
+
+	tagIDs, err := t.c.tags.ListAttachedTags(ctx, toObjectID(resource))
+	if err != nil {
+		return nil, fmt.Errorf("error listing tags of %s: %w", resource.ID, err)
+	}
+
+	attached := make(map[string]string)
+	for _, id := range tagIDs {
+		tg, ok := t.tags[id]
+		if !ok {
+			continue
+		}
+		attached[t.categories[tg.CategoryID].Name] = tg.Name
+	}
+	return attached, nil
+}
+
+// attachedObjects returns the objects the tag with the category and name
+// is attached to, none if the tag does not exist.
+func (t *tagCache) attachedObjects(ctx context.Context, categoryName, tagName string) ([]mo.Reference, error) {
+	if err := t.load(ctx); err != nil {
+		return nil, err
+	}
+	cat, ok := t.findCategory(categoryName)
+	if !ok {
+		return nil, nil
+	}
+	tg, ok := t.findTag(cat.ID, tagName)
+	if !ok {
+		return nil, nil
+	}
+
+	objects, err := t.c.tags.ListAttachedObjects(ctx, tg.ID)
+	if err != nil {
+		return nil, fmt.Errorf("error listing objects tagged %s/%s: %w", categoryName, tagName, err)
+	}
+	return objects, nil
+}
+
+func (t *tagCache) findCategory(name string) (vapitags.Category, bool) {
+	for _, cat := range t.categories {
+		if cat.Name == name {
+			return cat, true
+		}
+	}
+	return vapitags.Category{}, false
+}
+
+func (t *tagCache) findTag(categoryID, name string) (vapitags.Tag, bool) {
+	for _, tg := range t.tags {
+		if tg.CategoryID == categoryID && tg.Name == name {
+			return tg, true
+		}
+	}
+	return vapitags.Tag{}, false
+}
+
+// ensureTag returns the tag for key/value, creating the category and the
+// tag when they do not exist yet.
+func (t *tagCache) ensureTag(ctx context.Context, key, value string) (vapitags.Tag, error) {
+	if err := t.load(ctx); err != nil {
+		return vapitags.Tag{}, err
+	}
+
+	cat, ok := t.findCategory(key)
+	if !ok {
+		cat = vapitags.Category{
+			Name:            key,
+			Description:     "Created by openshift-metadata-manager",
+			Cardinality:     "SINGLE",
+			AssociableTypes: []string{},
+		}
+		id, err := t.c.tags.CreateCategory(ctx, &cat)
+		if err != nil {
+			return vapitags.Tag{}, fmt.Errorf("failed to create tag category %s: %w", key, err)
+		}
+		cat.ID = id
+		t.categories[id] = cat
+	}
+
+	tg, ok := t.findTag(cat.ID, value)
+	if !ok {
+		tg = vapitags.Tag{
+			Name:        value,
+			Description: "Created by openshift-metadata-manager",
+			CategoryID:  cat.ID,
+		}
+		id, err := t.c.tags.CreateTag(ctx, &tg)
+		if err != nil {
+			return vapitags.Tag{}, fmt.Errorf("failed to create tag %s/%s: %w", key, value, err)
+		}
+		tg.ID = id
+		t.tags[id] = tg
+	}
+	return tg, nil
+}
+
+// UpdateResourceTags attaches a tag per key/value to every resource,
+// detaching the tag of the same category with another value first.
+func UpdateResourceTags(ctx context.Context, k8sClient client.Client, resources []infraType.CloudResource, tags map[string]string) error {
+
+	_, server, err := getClusterInfo(ctx, k8sClient)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster info: %w", err)
+	}
+
+	c, err := newClient(ctx, server)
+	if err != nil {
+		return fmt.Errorf("vSphere authentication error: %w", err)
+	}
+	cache := newTagCache(c)
+
+	return infraType.ForEachResource(ctx, resources, "update", func(ctx context.Context, resource infraType.CloudResource) error {
+		return updateObjectTags(ctx, cache, resource, tags)
+	})
+}
+
+func updateObjectTags(ctx context.Context, cache *tagCache, resource infraType.CloudResource, tags map[string]string) error {
+	if _, ok := objectTypes[resource.Type]; !ok || resource.NotTaggable {
+		return fmt.Errorf("%s does not support vCenter tags", resource.Type)
+	}
+	// The tag of the current value is looked up before any tag is ensured.
+	if err := cache.load(ctx); err != nil {
+		return err
+	}
+
+	ref := toObjectID(resource)
+	for k, v := range tags {
+		if current, ok := resource.Tags[k]; ok {
+			if current == v {
+				continue
+			}
+			cat, _ := cache.findCategory(k)
+			if old, ok := cache.findTag(cat.ID, current); ok {
+				if err := cache.c.tags.DetachTag(ctx, old.ID, ref); err != nil {
+					return fmt.Errorf("failed to detach %s/%s: %w", k, current, err)
+				}
+			}
+		}
+
+		tg, err := cache.ensureTag(ctx, k, v)
+		if err != nil {
+			return err
+		}
+		if err := cache.c.tags.AttachTag(ctx, tg.ID, ref); err != nil {
+			return fmt.Errorf("failed to attach %s/%s: %w", k, v, err)
+		}
+	}
+	return nil
+}
+
+func toObjectID(resource infraType.CloudResource) types.ManagedObjectReference {
+	return types.ManagedObjectReference{Type: objectTypes[resource.Type], Value: resource.ID}
+}
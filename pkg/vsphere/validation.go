@@ -0,0 +1,24 @@
+package vsphere
+
+import (
+	"fmt"
+)
+
+const (
+	vsphereCategoryMaxLength = 256
+	vsphereTagMaxLength      = 256
+)
+
+// IsValidVSphereTag validates tags against the vCenter category and tag
+// name limits. A tag needs a name, so values cannot be empty.
+func IsValidVSphereTag(tags map[string]string) error {
+	for key, value := range tags {
+		if len(key) == 0 || len(key) > vsphereCategoryMaxLength {
+			return fmt.Errorf("vSphere tag category length must be 1-%d characters", vsphereCategoryMaxLength)
+		}
+		if len(value) == 0 || len(value) > vsphereTagMaxLength {
+			return fmt.Errorf("vSphere tag name length must be 1-%d characters", vsphereTagMaxLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package vsphere
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/property"
+	"github.com/vmware/govmomi/view"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+// objectTypes maps taggable resource types to vSphere managed object types.
+var objectTypes = map[infraType.CloudResourceType]string{
+	infraType.CloudResourceTypeVSphereVM:           "VirtualMachine",
+	infraType.CloudResourceTypeVSphereFolder:       "Folder",
+	infraType.CloudResourceTypeVSphereResourcePool: "ResourcePool",
+}
+
+// ListVSphereResources lists the cluster's VM folder and resource pool
+// (both named after the infraID), the VMs in that folder or carrying the
+// cluster's tag, and their disks.
+func ListVSphereResources(ctx context.Context, k8sClient client.Client) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	infraID, server, err := getClusterInfo(ctx, k8sClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster info: %w", err)
+	}
+
+	c, err := newClient(ctx, server)
+	if err != nil {
+		return nil, fmt.Errorf("vSphere authentication error: %w", err)
+	}
+
+	tags := newTagCache(c)
+	folders, err := listFolders(ctx, c, infraID)
+	if err == nil {
+		resources = append(resources, folders...)
+	}
+	var vms []infraType.CloudResource
+	if vmRes, err := listVMs(ctx, c, tags, infraID, folders); err == nil {
+		vms = vmRes
+		resources = append(resources, vmRes...)
+	}
+	if poolRes, err := listResourcePools(ctx, c, infraID); err == nil {
+		resources = append(resources, poolRes...)
+	}
+
+	for i := range resources {
+		attached, err := tags.attachedTags(ctx, resources[i])
+		if err != nil {
+			return nil, err
+		}
+		resources[i].Tags = attached
+	}
+
+	for _, vm := range vms {
+		if diskRes, err := listDisks(ctx, c, vm); err == nil {
+			resources = append(resources, diskRes...)
+		}
+	}
+
+	return resources, nil
+}
+
+func listFolders(ctx context.Context, c *apiClient, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	var folders []mo.Folder
+	root := c.vim.ServiceContent.RootFolder
+	if err := c.retrieve(ctx, root, "Folder", true, []string{"name", "childType"}, &folders); err != nil {
+		return nil, fmt.Errorf("error listing folders: %w", err)
+	}
+
+	for _, folder := range folders {
+		if folder.Name != infraID || !slices.Contains(folder.ChildType, "VirtualMachine") {
+			continue
+		}
+		resources = append(resources, infraType.CloudResource{
+			CloudProvider: infraType.CloudPlatformVSphere,
+			Type:          infraType.CloudResourceTypeVSphereFolder,
+			ID:            folder.Self.Value,
+			Name:          folder.Name,
+		})
+	}
+	return resources, nil
+}
+
+// listVMs lists the VMs in the cluster folders and the VMs carrying the
+// cluster's tag, which the installer attaches to every VM it creates and the
+// UPI instructions to VMs placed outside the folder.
+func listVMs(ctx context.Context, c *apiClient, tags *tagCache, infraID string,
+	folders []infraType.CloudResource) ([]infraType.CloudResource, error) {
+
+	var resources []infraType.CloudResource
+
+	seen := make(map[string]bool)
+	add := func(vms []mo.VirtualMachine) {
+		for _, vm := range vms {
+			if seen[vm.Self.Value] {
+				continue
+			}
+			seen[vm.Self.Value] = true
+			resources = append(resources, infraType.CloudResource{
+				CloudProvider: infraType.CloudPlatformVSphere,
+				Type:          infraType.CloudResourceTypeVSphereVM,
+				ID:            vm.Self.Value,
+				Name:          vm.Name,
+			})
+		}
+	}
+
+	for _, folder := range folders {
+		var vms []mo.VirtualMachine
+		if err := c.retrieve(ctx, toObjectID(folder), "VirtualMachine", false, []string{"name"}, &vms); err != nil {
+			return nil, fmt.Errorf("error listing VMs in %s: %w", folder.ID, err)
+		}
+		add(vms)
+	}
+
+	objects, err := tags.attachedObjects(ctx, fmt.Sprintf(clusterCategory, infraID), infraID)
+	if err != nil {
+		return nil, err
+	}
+	var refs []types.ManagedObjectReference
+	for _, object := range objects {
+		ref := object.Reference()
+		if ref.Type == objectTypes[infraType.CloudResourceTypeVSphereVM] && !seen[ref.Value] {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) > 0 {
+		var vms []mo.VirtualMachine
+		if err := property.DefaultCollector(c.vim).Retrieve(ctx, refs, []string{"name"}, &vms); err != nil {
+			return nil, fmt.Errorf("error reading tagged VMs: %w", err)
+		}
+		add(vms)
+	}
+
+	return resources, nil
+}
+
+func listResourcePools(ctx context.Context, c *apiClient, infraID string) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	var pools []mo.ResourcePool
+	root := c.vim.ServiceContent.RootFolder
+	if err := c.retrieve(ctx, root, "ResourcePool", true, []string{"name"}, &pools); err != nil {
+		return nil, fmt.Errorf("error listing resource pools: %w", err)
+	}
+
+	for _, pool := range pools {
+		if pool.Name != infraID {
+			continue
+		}
+		resources = append(resources, infraType.CloudResource{
+			CloudProvider: infraType.CloudPlatformVSphere,
+			Type:          infraType.CloudResourceTypeVSphereResourcePool,
+			ID:            pool.Self.Value,
+			Name:          pool.Name,
+		})
+	}
+	return resources, nil
+}
+
+// listDisks lists the datastore-backed disks of a VM, by device key. vCenter
+// tags cannot be attached to virtual disks, so they are reported as not
+// taggable.
+func listDisks(ctx context.Context, c *apiClient, vm infraType.CloudResource) ([]infraType.CloudResource, error) {
+	var resources []infraType.CloudResource
+
+	var info mo.VirtualMachine
+	if err := property.DefaultCollector(c.vim).RetrieveOne(ctx, toObjectID(vm), []string{"config.hardware.device"}, &info); err != nil {
+		return nil, fmt.Errorf("error listing disks of %s: %w", vm.ID, err)
+	}
+	if info.Config == nil {
+		return nil, nil
+	}
+
+	devices := object.VirtualDeviceList(info.Config.Hardware.Device)
+	for _, device := range devices.SelectByType((*types.VirtualDisk)(nil)) {
+		disk := device.(*types.VirtualDisk)
+		backing, ok := disk.Backing.(types.BaseVirtualDeviceFileBackingInfo)
+		if !ok {
+			continue
+		}
+
+		resources = append(resources, infraType.CloudResource{
+			CloudProvider: infraType.CloudPlatformVSphere,
+			Type:          infraType.CloudResourceTypeVSphereVolume,
+			ID:            vm.ID + "/" + strconv.Itoa(int(disk.Key)),
+			Name:          backing.GetVirtualDeviceFileBackingInfo().FileName,
+			NotTaggable:   true,
+		})
+	}
+	return resources, nil
+}
+
+// retrieve reads the properties of the objects of the managed object type
+// in container, or only of its children unless recursive, into dst, a
+// pointer to a slice of the matching mo type.
+func (c *apiClient) retrieve(ctx context.Context, container types.ManagedObjectReference, kind string,
+	recursive bool, props []string, dst interface{}) error {
+
+	v, err := view.NewManager(c.vim).CreateContainerView(ctx, container, []string{kind}, recursive)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = v.Destroy(context.WithoutCancel(ctx)) }()
+
+	return v.Retrieve(ctx, []string{kind}, props, dst)
+}
+
+// getClusterInfo returns the infraID and the first vCenter configured on
+// the cluster.
+func getClusterInfo(ctx context.Context, k8sClient client.Client) (string, string, error) {
+	infra := &configv1.Infrastructure{}
+	if err := k8sClient.Get(ctx,
+		client.ObjectKey{Name: "cluster"}, infra); err != nil {
+		return "", "", fmt.Errorf("failed to get Infrastructure: %w", err)
+	}
+
+	server := ""
+	if spec := infra.Spec.PlatformSpec.VSphere; spec != nil && len(spec.VCenters) > 0 {
+		server = spec.VCenters[0].Server
+	}
+	return infra.Status.InfrastructureName, server, nil
+}
@@ -0,0 +1,300 @@
+package vsphere
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"github.com/vmware/govmomi/find"
+	"github.com/vmware/govmomi/object"
+	"github.com/vmware/govmomi/simulator"
+	"github.com/vmware/govmomi/vapi/rest"
+	_ "github.com/vmware/govmomi/vapi/simulator"
+	vapitags "github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/types"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infraType "github.com/anirudhAgniRedhat/openshift-metadata-manager/types"
+)
+
+const testInfraID = "mycluster-x7k2p"
+
+// vcenter is a govmomi vcsim vCenter, with the vSphere Automation API
+// simulator serving tagging, and the client the tests set it up with.
+type vcenter struct {
+	t      *testing.T
+	ctx    context.Context
+	vim    *vim25.Client
+	finder *find.Finder
+	tags   *vapitags.Manager
+}
+
+// runVCenter runs f against a vcsim vCenter with the default VPX inventory
+// and points VSPHERE_SERVER at it. f gets a client for the cluster's
+// Infrastructure.
+func runVCenter(t *testing.T, f func(vc *vcenter, k8sClient client.Client)) {
+	t.Helper()
+	simulator.Test(func(ctx context.Context, c *vim25.Client) {
+		t.Setenv("VSPHERE_SERVER", c.URL().Host)
+		t.Setenv("VSPHERE_USERNAME", simulator.DefaultLogin.Username())
+		password, _ := simulator.DefaultLogin.Password()
+		t.Setenv("VSPHERE_PASSWORD", password)
+		t.Setenv("VSPHERE_INSECURE", "true")
+
+		restClient := rest.NewClient(c)
+		if err := restClient.Login(ctx, simulator.DefaultLogin); err != nil {
+			t.Fatal(err)
+		}
+		finder := find.NewFinder(c)
+		dc, err := finder.DefaultDatacenter(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		finder.SetDatacenter(dc)
+
+		scheme := runtime.NewScheme()
+		if err := configv1.AddToScheme(scheme); err != nil {
+			t.Fatal(err)
+		}
+		infra := &configv1.Infrastructure{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status:     configv1.InfrastructureStatus{InfrastructureName: testInfraID},
+		}
+		k8sClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(infra).WithStatusSubresource(infra).Build()
+
+		f(&vcenter{t: t, ctx: ctx, vim: c, finder: finder, tags: vapitags.NewManager(restClient)}, k8sClient)
+	})
+}
+
+func (vc *vcenter) vm(name string) *object.VirtualMachine {
+	vm, err := vc.finder.VirtualMachine(vc.ctx, name)
+	if err != nil {
+		vc.t.Fatal(err)
+	}
+	return vm
+}
+
+func (vc *vcenter) folders() *object.DatacenterFolders {
+	dc, err := vc.finder.DefaultDatacenter(vc.ctx)
+	if err != nil {
+		vc.t.Fatal(err)
+	}
+	folders, err := dc.Folders(vc.ctx)
+	if err != nil {
+		vc.t.Fatal(err)
+	}
+	return folders
+}
+
+// tag attaches the tag category/name to ref, creating both as needed.
+func (vc *vcenter) tag(ref mo.Reference, categoryName, name string) {
+	cat, err := vc.tags.GetCategory(vc.ctx, categoryName)
+	if err != nil {
+		id, err := vc.tags.CreateCategory(vc.ctx, &vapitags.Category{Name: categoryName, Cardinality: "SINGLE"})
+		if err != nil {
+			vc.t.Fatal(err)
+		}
+		cat = &vapitags.Category{ID: id, Name: categoryName}
+	}
+	tg, err := vc.tags.GetTagForCategory(vc.ctx, name, cat.ID)
+	if err != nil {
+		id, err := vc.tags.CreateTag(vc.ctx, &vapitags.Tag{Name: name, CategoryID: cat.ID})
+		if err != nil {
+			vc.t.Fatal(err)
+		}
+		tg = &vapitags.Tag{ID: id}
+	}
+	if err := vc.tags.AttachTag(vc.ctx, tg.ID, ref); err != nil {
+		vc.t.Fatal(err)
+	}
+}
+
+// attached returns the tags attached to ref as category name to tag name.
+func (vc *vcenter) attached(ref mo.Reference) map[string]string {
+	tags, err := vc.tags.GetAttachedTags(vc.ctx, ref)
+	if err != nil {
+		vc.t.Fatal(err)
+	}
+	attached := make(map[string]string)
+	for _, tg := range tags {
+		cat, err := vc.tags.GetCategory(vc.ctx, tg.CategoryID)
+		if err != nil {
+			vc.t.Fatal(err)
+		}
+		attached[cat.Name] = tg.Name
+	}
+	return attached
+}
+
+func TestListVSphereResources(t *testing.T) {
+	runVCenter(t, func(vc *vcenter, k8sClient client.Client) {
+		folders := vc.folders()
+		folder, err := folders.VmFolder.CreateFolder(vc.ctx, testInfraID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// A host folder of the same name is not a VM folder.
+		if _, err := folders.HostFolder.CreateFolder(vc.ctx, testInfraID); err != nil {
+			t.Fatal(err)
+		}
+		master := vc.vm("DC0_H0_VM0")
+		task, err := folder.MoveInto(vc.ctx, []types.ManagedObjectReference{master.Reference()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := task.Wait(vc.ctx); err != nil {
+			t.Fatal(err)
+		}
+		// A UPI worker outside the folder carries the cluster tag.
+		worker := vc.vm("DC0_H0_VM1")
+		vc.tag(worker, "openshift-"+testInfraID, testInfraID)
+		vc.tag(folder, "Owner", "DevOps")
+
+		parent, err := vc.finder.ResourcePool(vc.ctx, "DC0_C0/Resources")
+		if err != nil {
+			t.Fatal(err)
+		}
+		pool, err := parent.Create(vc.ctx, testInfraID, types.DefaultResourceConfigSpec())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		resources, err := ListVSphereResources(vc.ctx, k8sClient)
+		if err != nil {
+			t.Fatalf("ListVSphereResources() error = %v", err)
+		}
+
+		var got []string
+		for _, res := range resources {
+			if res.Type != infraType.CloudResourceTypeVSphereVolume {
+				got = append(got, string(res.Type)+" "+res.ID)
+			}
+		}
+		want := []string{
+			string(infraType.CloudResourceTypeVSphereFolder) + " " + folder.Reference().Value,
+			string(infraType.CloudResourceTypeVSphereVM) + " " + master.Reference().Value,
+			string(infraType.CloudResourceTypeVSphereVM) + " " + worker.Reference().Value,
+			string(infraType.CloudResourceTypeVSphereResourcePool) + " " + pool.Reference().Value,
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("discovered %v, want %v", got, want)
+		}
+		if tags := resources[0].Tags; tags["Owner"] != "DevOps" {
+			t.Errorf("tags of the folder = %v, want Owner=DevOps", tags)
+		}
+		if tags := resources[2].Tags; tags["openshift-"+testInfraID] != testInfraID {
+			t.Errorf("tags of the worker = %v, want the cluster tag", tags)
+		}
+
+		disks := make(map[string]int)
+		for _, res := range resources {
+			if res.Type != infraType.CloudResourceTypeVSphereVolume {
+				continue
+			}
+			vmID, _, _ := strings.Cut(res.ID, "/")
+			disks[vmID]++
+			if !res.NotTaggable || !strings.HasSuffix(res.Name, ".vmdk") {
+				t.Errorf("disk %s = %+v, want a not taggable disk named after its VMDK", res.ID, res)
+			}
+		}
+		if disks[master.Reference().Value] == 0 || disks[worker.Reference().Value] == 0 || len(disks) != 2 {
+			t.Errorf("disks by VM = %v, want the disks of both VMs", disks)
+		}
+	})
+}
+
+func TestUpdateResourceTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(vc *vcenter, ref mo.Reference)
+		current map[string]string
+		want    map[string]string
+	}{
+		{
+			name: "category and tag are created",
+			want: map[string]string{"Owner": "DevOps", "Team": "infra"},
+		},
+		{
+			name: "tag is created in the existing category",
+			setup: func(vc *vcenter, ref mo.Reference) {
+				vc.tag(vc.folders().VmFolder, "Owner", "Admin")
+			},
+			want: map[string]string{"Owner": "DevOps", "Team": "infra"},
+		},
+		{
+			name: "old value is detached",
+			setup: func(vc *vcenter, ref mo.Reference) {
+				vc.tag(ref, "Owner", "Admin")
+				vc.tag(ref, "Env", "prod")
+			},
+			current: map[string]string{"Owner": "Admin", "Env": "prod"},
+			want:    map[string]string{"Owner": "DevOps", "Team": "infra", "Env": "prod"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runVCenter(t, func(vc *vcenter, k8sClient client.Client) {
+				vm := vc.vm("DC0_H0_VM0")
+				if tt.setup != nil {
+					tt.setup(vc, vm)
+				}
+
+				resources := []infraType.CloudResource{{
+					CloudProvider: infraType.CloudPlatformVSphere,
+					Type:          infraType.CloudResourceTypeVSphereVM,
+					ID:            vm.Reference().Value,
+					Tags:          tt.current,
+				}}
+				err := UpdateResourceTags(vc.ctx, k8sClient, resources, map[string]string{"Owner": "DevOps", "Team": "infra"})
+				if err != nil {
+					t.Fatalf("UpdateResourceTags() error = %v", err)
+				}
+				if got := vc.attached(vm); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("attached tags = %v, want %v", got, tt.want)
+				}
+
+				categories, err := vc.tags.GetCategories(vc.ctx)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, cat := range categories {
+					if cat.Cardinality != "SINGLE" {
+						t.Errorf("category %s has cardinality %s, want SINGLE", cat.Name, cat.Cardinality)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestUpdateResourceTagsPartialFailure(t *testing.T) {
+	runVCenter(t, func(vc *vcenter, k8sClient client.Client) {
+		pool, err := vc.finder.ResourcePool(vc.ctx, "DC0_C0/Resources")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resources := []infraType.CloudResource{
+			{Type: infraType.CloudResourceTypeVSphereResourcePool, ID: pool.Reference().Value},
+			{Type: infraType.CloudResourceTypeVSphereVolume, ID: "vm-1/2000", NotTaggable: true},
+			{Type: infraType.CloudResourceTypeVSphereFolder, ID: vc.folders().VmFolder.Reference().Value},
+		}
+		err = UpdateResourceTags(vc.ctx, k8sClient, resources, map[string]string{"Owner": "DevOps"})
+
+		multi, ok := err.(infraType.MultiError)
+		if !ok || len(multi) != 1 || !strings.Contains(multi[0].Error(), "vm-1/2000") {
+			t.Fatalf("UpdateResourceTags() error = %v, want a MultiError for the disk", err)
+		}
+		for _, ref := range []mo.Reference{pool, vc.folders().VmFolder} {
+			if got := vc.attached(ref); got["Owner"] != "DevOps" {
+				t.Errorf("tags of %s = %v, want Owner=DevOps", ref.Reference(), got)
+			}
+		}
+	})
+}
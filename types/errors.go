@@ -0,0 +1,72 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Error categories. Errors returned by the provider packages match one of
+// them with errors.Is once classified, see manager.Classify.
+var (
+	// ErrUnsupportedPlatform is returned for operations a platform does not
+	// support.
+	ErrUnsupportedPlatform = errors.New("unsupported platform")
+	// ErrCredentials means the cloud credentials are missing, invalid or
+	// expired.
+	ErrCredentials = errors.New("invalid or missing cloud credentials")
+	// ErrPermissionDenied means the credentials lack a permission.
+	ErrPermissionDenied = errors.New("permission denied")
+	// ErrThrottled means the cloud API rate limit was hit.
+	ErrThrottled = errors.New("request throttled")
+	// ErrNotFound means a resource or tag no longer exists.
+	ErrNotFound = errors.New("not found")
+	// ErrTagLimitExceeded means a resource would carry more tags than the
+	// platform allows.
+	ErrTagLimitExceeded = errors.New("tag limit exceeded")
+)
+
+// CloudError is a cloud API error with its category. Its message is the
+// original one; errors.Is matches both the category and the original error.
+type CloudError struct {
+	// Category is one of the Err* sentinels.
+	Category error
+	// Code is the API's error code, e.g. "UnauthorizedOperation", if any.
+	Code string
+	// StatusCode is the HTTP status of the response, if any.
+	StatusCode int
+	Err        error
+}
+
+func (e *CloudError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CloudError) Unwrap() []error {
+	return []error{e.Category, e.Err}
+}
+
+// StatusError is an unsuccessful response of the IBM Cloud, OpenStack and
+// Nutanix REST APIs.
+type StatusError struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Status     string
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: %s: %s", e.Method, e.Path, e.Status, e.Body)
+}
+
+// MultiError collects the errors of a batch of updates. errors.Is and
+// errors.As match any of them.
+type MultiError []error
+
+func (e MultiError) Error() string {
+	return fmt.Sprintf("encountered %d errors: %v", len(e), []error(e))
+}
+
+func (e MultiError) Unwrap() []error {
+	return e
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultOperationTimeout bounds a single write to a cloud resource,
+// including the long-running operations Azure polls for, unless
+// WithOperations sets another timeout.
+const DefaultOperationTimeout = 10 * time.Minute
+
+type operationsKey struct{}
+
+type operations struct {
+	timeout time.Duration
+	abort   context.Context
+}
+
+// WithOperations returns a context whose writes to cloud resources each get
+// the timeout and outlive ctx: once ctx is done, ForEachResource starts no
+// new writes, but the writes in flight finish unless abort is done first.
+// A timeout of 0 means DefaultOperationTimeout.
+func WithOperations(ctx context.Context, timeout time.Duration, abort context.Context) context.Context {
+	return context.WithValue(ctx, operationsKey{}, operations{timeout: timeout, abort: abort})
+}
+
+// OperationContext returns the context for one write to a cloud resource,
+// see WithOperations. Without WithOperations it is ctx with the default
+// timeout.
+func OperationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ops, ok := ctx.Value(operationsKey{}).(operations)
+	if !ok {
+		return context.WithTimeout(ctx, DefaultOperationTimeout)
+	}
+	timeout := ops.timeout
+	if timeout == 0 {
+		timeout = DefaultOperationTimeout
+	}
+
+	opCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	if ops.abort == nil {
+		return opCtx, cancel
+	}
+	stop := context.AfterFunc(ops.abort, cancel)
+	return opCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Progress records the resources a command has written, to report what was
+// applied when it is interrupted.
+type Progress struct {
+	// OnWrite, if set, is called after each write to a resource with its
+	// error, nil if the resource was written.
+	OnWrite func(resource CloudResource, err error)
+
+	mu      sync.Mutex
+	written map[string]bool
+}
+
+type progressKey struct{}
+
+// WithProgress returns a context that records the writes ForEachResource
+// and RecordWrite see in p.
+func WithProgress(ctx context.Context, p *Progress) context.Context {
+	return context.WithValue(ctx, progressKey{}, p)
+}
+
+// RecordWrite records the outcome of a write to the resource in the
+// Progress of ctx, if any.
+func RecordWrite(ctx context.Context, resource CloudResource, err error) {
+	p, ok := ctx.Value(progressKey{}).(*Progress)
+	if !ok {
+		return
+	}
+	if err == nil {
+		p.mu.Lock()
+		if p.written == nil {
+			p.written = make(map[string]bool)
+		}
+		p.written[resource.Key()] = true
+		p.mu.Unlock()
+	}
+	if p.OnWrite != nil {
+		p.OnWrite(resource, err)
+	}
+}
+
+// Written reports whether the resource was written.
+func (p *Progress) Written(resource CloudResource) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.written[resource.Key()]
+}
+
+// ForEachResource calls write for each resource with its own operation
+// context, see OperationContext, and records each write with RecordWrite. Once
+// ctx is done it stops and reports the resources left as not updated. The
+// errors are returned as a MultiError, each as "failed to <action> <ID>
+// (<type>): <error>".
+func ForEachResource(ctx context.Context, resources []CloudResource, action string,
+	write func(ctx context.Context, resource CloudResource) error) error {
+
+	var errs []error
+	for i, resource := range resources {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("stopped before %d of %d resources: %w",
+				len(resources)-i, len(resources), context.Cause(ctx)))
+			break
+		}
+
+		opCtx, cancel := OperationContext(ctx)
+		err := write(opCtx, resource)
+		cancel()
+		RecordWrite(ctx, resource, err)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to %s %s (%s): %w", action, resource.ID, resource.Type, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return MultiError(errs)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package types
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestForEachResource(t *testing.T) {
+	tests := []struct {
+		name    string
+		failing map[string]bool
+		// setup returns the context for ForEachResource and the function
+		// the write to "b" calls with its operation context before it
+		// checks it.
+		setup       func(t *testing.T) (context.Context, func(opCtx context.Context))
+		wantWritten []string
+		wantErrs    []string
+	}{
+		{
+			name:        "all written",
+			wantWritten: []string{"a", "b", "c"},
+		},
+		{
+			name:        "failures are collected",
+			failing:     map[string]bool{"a": true, "c": true},
+			wantWritten: []string{"b"},
+			wantErrs: []string{
+				"failed to tag a (Test): boom",
+				"failed to tag c (Test): boom",
+			},
+		},
+		{
+			name: "stops once cancelled",
+			setup: func(t *testing.T) (context.Context, func(opCtx context.Context)) {
+				ctx, cancel := context.WithCancelCause(context.Background())
+				t.Cleanup(func() { cancel(nil) })
+				return ctx, func(context.Context) { cancel(errors.New("shutting down")) }
+			},
+			wantWritten: []string{"a"},
+			wantErrs: []string{
+				"failed to tag b (Test): context canceled",
+				"stopped before 1 of 3 resources: shutting down",
+			},
+		},
+		{
+			name: "stops on the deadline",
+			setup: func(t *testing.T) (context.Context, func(opCtx context.Context)) {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+				t.Cleanup(cancel)
+				return ctx, func(context.Context) { <-ctx.Done() }
+			},
+			wantWritten: []string{"a"},
+			wantErrs: []string{
+				"failed to tag b (Test): context deadline exceeded",
+				"stopped before 1 of 3 resources: context deadline exceeded",
+			},
+		},
+		{
+			name: "write in flight outlives the cancel",
+			setup: func(t *testing.T) (context.Context, func(opCtx context.Context)) {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				return WithOperations(ctx, 0, context.Background()), func(context.Context) { cancel() }
+			},
+			wantWritten: []string{"a", "b"},
+			wantErrs:    []string{"stopped before 1 of 3 resources: context canceled"},
+		},
+		{
+			name: "stops on abort",
+			setup: func(t *testing.T) (context.Context, func(opCtx context.Context)) {
+				ctx, cancel := context.WithCancel(context.Background())
+				t.Cleanup(cancel)
+				abort, abortNow := context.WithCancel(context.Background())
+				t.Cleanup(abortNow)
+				return WithOperations(ctx, 0, abort), func(opCtx context.Context) {
+					cancel()
+					abortNow()
+					// The abort cancels the operation from its own goroutine.
+					<-opCtx.Done()
+				}
+			},
+			wantWritten: []string{"a"},
+			wantErrs: []string{
+				"failed to tag b (Test): context canceled",
+				"stopped before 1 of 3 resources: context canceled",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, stop := context.Background(), func(context.Context) {}
+			if tt.setup != nil {
+				ctx, stop = tt.setup(t)
+			}
+			progress := &Progress{}
+			ctx = WithProgress(ctx, progress)
+
+			var resources []CloudResource
+			for _, id := range []string{"a", "b", "c"} {
+				resources = append(resources, CloudResource{Type: "Test", ID: id})
+			}
+			err := ForEachResource(ctx, resources, "tag", func(opCtx context.Context, resource CloudResource) error {
+				if resource.ID == "b" {
+					stop(opCtx)
+				}
+				if tt.failing[resource.ID] {
+					return errors.New("boom")
+				}
+				return opCtx.Err()
+			})
+
+			var gotErrs []string
+			if err != nil {
+				var multi MultiError
+				if !errors.As(err, &multi) {
+					t.Fatalf("ForEachResource() error = %v, want a MultiError", err)
+				}
+				for _, err := range multi {
+					gotErrs = append(gotErrs, err.Error())
+				}
+			}
+			if !reflect.DeepEqual(gotErrs, tt.wantErrs) {
+				t.Errorf("errors = %q, want %q", gotErrs, tt.wantErrs)
+			}
+
+			var gotWritten []string
+			for _, resource := range resources {
+				if progress.Written(resource) {
+					gotWritten = append(gotWritten, resource.ID)
+				}
+			}
+			if !reflect.DeepEqual(gotWritten, tt.wantWritten) {
+				t.Errorf("written = %v, want %v", gotWritten, tt.wantWritten)
+			}
+		})
+	}
+}
+
+func TestOperationContext(t *testing.T) {
+	tests := []struct {
+		name string
+		// ctx wraps the parent context; abort is the abort context for
+		// WithOperations.
+		ctx           func(parent, abort context.Context) context.Context
+		wantTimeout   time.Duration
+		parentCancels bool
+		abortCancels  bool
+	}{
+		{
+			name:          "without operations",
+			ctx:           func(parent, abort context.Context) context.Context { return parent },
+			wantTimeout:   DefaultOperationTimeout,
+			parentCancels: true,
+		},
+		{
+			name: "default timeout",
+			ctx: func(parent, abort context.Context) context.Context {
+				return WithOperations(parent, 0, abort)
+			},
+			wantTimeout:  DefaultOperationTimeout,
+			abortCancels: true,
+		},
+		{
+			name: "custom timeout",
+			ctx: func(parent, abort context.Context) context.Context {
+				return WithOperations(parent, time.Minute, abort)
+			},
+			wantTimeout:  time.Minute,
+			abortCancels: true,
+		},
+		{
+			name: "without abort",
+			ctx: func(parent, abort context.Context) context.Context {
+				return WithOperations(parent, time.Minute, nil)
+			},
+			wantTimeout: time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, cancelAbort := range []bool{false, true} {
+				parent, cancelParent := context.WithCancel(context.Background())
+				defer cancelParent()
+				abort, cancelAbortCtx := context.WithCancel(context.Background())
+				defer cancelAbortCtx()
+
+				start := time.Now()
+				opCtx, cancel := OperationContext(tt.ctx(parent, abort))
+				defer cancel()
+
+				deadline, ok := opCtx.Deadline()
+				if timeout := deadline.Sub(start); !ok || timeout < tt.wantTimeout || timeout > tt.wantTimeout+time.Minute {
+					t.Errorf("deadline in %v, want %v", timeout, tt.wantTimeout)
+				}
+
+				want := tt.parentCancels
+				if cancelAbort {
+					cancelAbortCtx()
+					want = tt.abortCancels
+				} else {
+					cancelParent()
+				}
+				// The abort cancels the operation from its own goroutine.
+				select {
+				case <-opCtx.Done():
+				case <-time.After(100 * time.Millisecond):
+				}
+				if cancelled := opCtx.Err() != nil; cancelled != want {
+					t.Errorf("abort cancelled %v: operation cancelled %v, want %v", cancelAbort, cancelled, want)
+				}
+			}
+		})
+	}
+}
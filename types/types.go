@@ -1,13 +1,18 @@
 package types
 
+import "strings"
+
 type CloudPlatform string
 
 const CloudPlatformAWS CloudPlatform = "AWS"
 const CloudPlatformAzure CloudPlatform = "Azure"
 const CloudPlatformOpenStack CloudPlatform = "OpenStack"
 const CloudPlatformGCP CloudPlatform = "GCP"
+const CloudPlatformVSphere CloudPlatform = "VSphere"
+const CloudPlatformNutanix CloudPlatform = "Nutanix"
+const CloudPlatformPowerVS CloudPlatform = "PowerVS"
 const CloudPlatformUnknown CloudPlatform = "Unknown"
-const CloudPlatformIBM = "IBM"
+const CloudPlatformIBM CloudPlatform = "IBM"
 
 //type AwsCloudResources struct {
 //	EC2Instances []string
@@ -61,15 +66,24 @@ const (
 	CloudResourceTypeAWSSubnet       CloudResourceType = "AWSSubnet"
 
 	// Azure Resource Types
-	CloudResourceTypeAzureVM                   CloudResourceType = "AzureVM"
-	CloudResourceTypeAzureVPC                  CloudResourceType = "AzureVPC"
-	CloudResourceTypeAzureManagedDisk          CloudResourceType = "AzureManagedDisk"
-	CloudResourceTypeAzureVirtualNetwork       CloudResourceType = "AzureVirtualNetwork"
-	CloudResourceTypeAzureLoadBalancer         CloudResourceType = "AzureLoadBalancer"
-	CloudResourceTypeAzurePublicIP             CloudResourceType = "AzurePublicIP"
-	CloudResourceTypeAzureStorageAccount       CloudResourceType = "AzureStorageAccount"
-	CloudResourceTypeAzureSubnet               CloudResourceType = "AzureSubnet"
-	CloudResourceTypeAzureNetworkSecurityGroup CloudResourceType = "AzureNetworkSecurityGroup"
+	CloudResourceTypeAzureVM                     CloudResourceType = "AzureVM"
+	CloudResourceTypeAzureManagedDisk            CloudResourceType = "AzureManagedDisk"
+	CloudResourceTypeAzureVirtualNetwork         CloudResourceType = "AzureVirtualNetwork"
+	CloudResourceTypeAzureLoadBalancer           CloudResourceType = "AzureLoadBalancer"
+	CloudResourceTypeAzurePublicIP               CloudResourceType = "AzurePublicIP"
+	CloudResourceTypeAzureStorageAccount         CloudResourceType = "AzureStorageAccount"
+	CloudResourceTypeAzureSubnet                 CloudResourceType = "AzureSubnet"
+	CloudResourceTypeAzureNetworkSecurityGroup   CloudResourceType = "AzureNetworkSecurityGroup"
+	CloudResourceTypeAzureNetworkInterface       CloudResourceType = "AzureNetworkInterface"
+	CloudResourceTypeAzurePrivateDNSZone         CloudResourceType = "AzurePrivateDNSZone"
+	CloudResourceTypeAzurePrivateDNSZoneVNetLink CloudResourceType = "AzurePrivateDNSZoneVNetLink"
+	CloudResourceTypeAzureManagedIdentity        CloudResourceType = "AzureManagedIdentity"
+	CloudResourceTypeAzureImageGallery           CloudResourceType = "AzureImageGallery"
+	CloudResourceTypeAzureGalleryImage           CloudResourceType = "AzureGalleryImage"
+	CloudResourceTypeAzureAvailabilitySet        CloudResourceType = "AzureAvailabilitySet"
+	CloudResourceTypeAzureRouteTable             CloudResourceType = "AzureRouteTable"
+	CloudResourceTypeAzurePrivateEndpoint        CloudResourceType = "AzurePrivateEndpoint"
+	CloudResourceTypeAzureResourceGroup          CloudResourceType = "AzureResourceGroup"
 
 	// GCP Resource Types
 	CloudResourceTypeGCPComputeInstance CloudResourceType = "GCPComputeInstance"
@@ -79,6 +93,48 @@ const (
 	CloudResourceTypeGCPStorageBucket   CloudResourceType = "GCPStorageBucket"
 	CloudResourceTypeGCPLoadBalancer    CloudResourceType = "GCPLoadBalancer"
 	CloudResourceTypeGCPDNSZone         CloudResourceType = "GCPDNSZone"
+	CloudResourceTypeGCPFirewallRule    CloudResourceType = "GCPFirewallRule"
+	CloudResourceTypeGCPRoute           CloudResourceType = "GCPRoute"
+	CloudResourceTypeGCPTargetPool      CloudResourceType = "GCPTargetPool"
+	CloudResourceTypeGCPBackendService  CloudResourceType = "GCPBackendService"
+	CloudResourceTypeGCPHealthCheck     CloudResourceType = "GCPHealthCheck"
+	CloudResourceTypeGCPAddress         CloudResourceType = "GCPAddress"
+	CloudResourceTypeGCPImage           CloudResourceType = "GCPImage"
+	CloudResourceTypeGCPSnapshot        CloudResourceType = "GCPSnapshot"
+	CloudResourceTypeGCPInstanceGroup   CloudResourceType = "GCPInstanceGroup"
+	CloudResourceTypeGCPServiceAccount  CloudResourceType = "GCPServiceAccount"
+	CloudResourceTypeGCPFilestore       CloudResourceType = "GCPFilestore"
+
+	// IBM Cloud Resource Types
+	CloudResourceTypeIBMVPCInstance   CloudResourceType = "IBMVPCInstance"
+	CloudResourceTypeIBMVolume        CloudResourceType = "IBMVolume"
+	CloudResourceTypeIBMLoadBalancer  CloudResourceType = "IBMLoadBalancer"
+	CloudResourceTypeIBMSecurityGroup CloudResourceType = "IBMSecurityGroup"
+	CloudResourceTypeIBMCOSBucket     CloudResourceType = "IBMCOSBucket"
+
+	// OpenStack Resource Types
+	CloudResourceTypeOpenStackServer        CloudResourceType = "OpenStackServer"
+	CloudResourceTypeOpenStackVolume        CloudResourceType = "OpenStackVolume"
+	CloudResourceTypeOpenStackPort          CloudResourceType = "OpenStackPort"
+	CloudResourceTypeOpenStackNetwork       CloudResourceType = "OpenStackNetwork"
+	CloudResourceTypeOpenStackSubnet        CloudResourceType = "OpenStackSubnet"
+	CloudResourceTypeOpenStackRouter        CloudResourceType = "OpenStackRouter"
+	CloudResourceTypeOpenStackSecurityGroup CloudResourceType = "OpenStackSecurityGroup"
+	CloudResourceTypeOpenStackContainer     CloudResourceType = "OpenStackContainer"
+
+	// vSphere Resource Types
+	CloudResourceTypeVSphereVM           CloudResourceType = "VSphereVM"
+	CloudResourceTypeVSphereFolder       CloudResourceType = "VSphereFolder"
+	CloudResourceTypeVSphereResourcePool CloudResourceType = "VSphereResourcePool"
+	CloudResourceTypeVSphereVolume       CloudResourceType = "VSphereVolume"
+
+	// Nutanix Resource Types
+	CloudResourceTypeNutanixVM          CloudResourceType = "NutanixVM"
+	CloudResourceTypeNutanixVolumeGroup CloudResourceType = "NutanixVolumeGroup"
+
+	// PowerVS Resource Types
+	CloudResourceTypePowerVSWorkspace CloudResourceType = "PowerVSWorkspace"
+	CloudResourceTypePowerVSInstance  CloudResourceType = "PowerVSInstance"
 )
 
 type CloudResource struct {
@@ -87,4 +143,28 @@ type CloudResource struct {
 	ID            string
 	Name          string
 	Tags          map[string]string
+	// Location is the zone or region the resource lives in ("global" for
+	// global resources). Only set where the provider needs it to address
+	// the resource.
+	Location string
+	// SelfLink is the provider's canonical URL for the resource.
+	SelfLink string
+	// Project is the GCP project that owns the resource. In a shared VPC
+	// this is the host project for network resources.
+	Project string
+	// NotTaggable marks resources that belong to the cluster but do not
+	// support tags or labels. Sync reports them instead of updating them.
+	NotTaggable bool
+}
+
+// Key identifies the resource among the cluster's resources, e.g. in the
+// files commands write. Azure resource IDs and names are case-insensitive,
+// and the IDs Kubernetes records in providerIDs and volume handles do not
+// always match the case ARM lists them in, so they are keyed in lower case.
+func (r CloudResource) Key() string {
+	id, name := r.ID, r.Name
+	if strings.HasPrefix(string(r.Type), "Azure") {
+		id, name = strings.ToLower(id), strings.ToLower(name)
+	}
+	return string(r.Type) + "/" + id + "/" + name
 }
@@ -0,0 +1,43 @@
+package types
+
+import "testing"
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b CloudResource
+		same bool
+	}{
+		{
+			name: "Azure ID from a providerID and from ARM",
+			a: CloudResource{
+				Type: CloudResourceTypeAzureVM,
+				ID:   "/subscriptions/sub/resourceGroups/mycluster-rg/providers/Microsoft.Compute/virtualMachines/master-0",
+				Name: "master-0",
+			},
+			b: CloudResource{
+				Type: CloudResourceTypeAzureVM,
+				ID:   "/subscriptions/sub/resourcegroups/MYCLUSTER-RG/providers/Microsoft.Compute/virtualMachines/Master-0",
+				Name: "Master-0",
+			},
+			same: true,
+		},
+		{
+			name: "AWS IDs are case-sensitive",
+			a:    CloudResource{Type: CloudResourceTypeAWSS3Bucket, ID: "bucket"},
+			b:    CloudResource{Type: CloudResourceTypeAWSS3Bucket, ID: "Bucket"},
+		},
+		{
+			name: "types differ",
+			a:    CloudResource{Type: CloudResourceTypeAzureVM, ID: "id"},
+			b:    CloudResource{Type: CloudResourceTypeAzureManagedDisk, ID: "id"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if same := tt.a.Key() == tt.b.Key(); same != tt.same {
+				t.Errorf("Key() = %q and %q, want equal %v", tt.a.Key(), tt.b.Key(), tt.same)
+			}
+		})
+	}
+}